@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Rorical/RoriCode/internal/config"
+)
+
+var configCmd = &cobra.Command{
+	Use:   "config",
+	Short: "Inspect configuration",
+	Long:  `Inspect the global configuration and the project configuration merged over it.`,
+}
+
+var showEffective bool
+
+var showConfigCmd = &cobra.Command{
+	Use:   "show",
+	Short: "Show the configuration",
+	Long: `Show the global configuration file. With --effective, show the configuration
+in use in the current directory, with the project config (.roricode/config.json
+in this directory or a parent) merged over the global one, and the file each
+value came from.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		load := config.LoadGlobalConfig
+		if showEffective {
+			load = config.LoadConfig
+		}
+		cfg, err := load()
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+
+		fmt.Printf("Global config:  %s\n", cfg.GlobalPath())
+		if showEffective {
+			projectPath := cfg.ProjectPath()
+			if projectPath == "" {
+				projectPath = "none"
+			}
+			fmt.Printf("Project config: %s\n", projectPath)
+			fmt.Printf("Active profile: %s (model %s)\n", cfg.ActiveProfile, cfg.GetModel())
+		}
+		fmt.Println()
+
+		settings, err := cfg.EffectiveSettings()
+		if err != nil {
+			log.Fatalf("Failed to read settings: %v", err)
+		}
+		for _, setting := range settings {
+			line := fmt.Sprintf("%s = %s", setting.Path, formatSettingValue(setting))
+			if showEffective {
+				line += "  (" + setting.Source + ")"
+			}
+			fmt.Println(line)
+		}
+
+		for _, warning := range cfg.Warnings() {
+			fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+		}
+	},
+}
+
+// formatSettingValue prints a value as JSON, hiding API keys and profile
+// header values, which often carry tokens
+func formatSettingValue(setting config.Setting) string {
+	if strings.HasSuffix(setting.Path, ".api_key") || isHeaderSetting(setting.Path) {
+		if value, ok := setting.Value.(string); ok && value != "" {
+			return `"(hidden)"`
+		}
+	}
+	data, err := json.Marshal(setting.Value)
+	if err != nil {
+		return fmt.Sprintf("%v", setting.Value)
+	}
+	return string(data)
+}
+
+// isHeaderSetting reports whether path is a header of a profile, such as
+// profiles.work.headers.Authorization
+func isHeaderSetting(path string) bool {
+	rest, ok := strings.CutPrefix(path, "profiles.")
+	return ok && strings.Contains(rest, ".headers.")
+}
+
+func init() {
+	showConfigCmd.Flags().BoolVar(&showEffective, "effective", false, "Show the merged configuration and where each value comes from")
+	configCmd.AddCommand(showConfigCmd)
+	rootCmd.AddCommand(configCmd)
+}
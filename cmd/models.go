@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/Rorical/RoriCode/internal/config"
+	"github.com/Rorical/RoriCode/internal/provider"
+)
+
+// apiCheckTimeout bounds the requests of the models and profile test commands
+const apiCheckTimeout = 90 * time.Second
+
+var modelsCmd = &cobra.Command{
+	Use:   "models",
+	Short: "List the models available to a profile",
+	Long: `List the models offered by the active profile's endpoint, or by the profile
+given with --profile. The profile's configured model is marked.`,
+	Args: cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		profile := cfg.CurrentProfile()
+		apiKey, err := profile.ResolveAPIKey()
+		if err != nil {
+			log.Fatalf("Profile '%s': %v", cfg.ActiveProfile, err)
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), apiCheckTimeout)
+		defer cancel()
+		models, err := provider.ListModels(ctx, provider.NewClient(profile, apiKey))
+		if err != nil {
+			log.Fatalf("Failed to list models: %v", err)
+		}
+
+		if len(models) == 0 {
+			fmt.Println("The endpoint lists no models.")
+			return
+		}
+		found := false
+		for _, model := range models {
+			marker := ""
+			if model == profile.Model {
+				marker = " (configured)"
+				found = true
+			}
+			fmt.Printf("%s%s\n", model, marker)
+		}
+		if !found {
+			fmt.Printf("\nWarning: profile '%s' uses %s, which is not listed\n", cfg.ActiveProfile, profile.Model)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(modelsCmd)
+}
@@ -1,13 +1,21 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"log"
+	"os"
+	"strconv"
+	"strings"
 
 	"github.com/manifoldco/promptui"
 	"github.com/spf13/cobra"
 
 	"github.com/Rorical/RoriCode/internal/config"
+	"github.com/Rorical/RoriCode/internal/provider"
 )
 
 var profileCmd = &cobra.Command{
@@ -20,28 +28,32 @@ var listProfilesCmd = &cobra.Command{
 	Use:   "list",
 	Short: "List all profiles",
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.LoadConfig()
+		cfg, err := config.LoadGlobalConfig()
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
 
 		fmt.Printf("Active Profile: %s\n\n", cfg.ActiveProfile)
 		fmt.Println("Available Profiles:")
-		for name, profile := range cfg.Profiles {
+		for name := range cfg.Profiles {
 			marker := ""
 			if name == cfg.ActiveProfile {
 				marker = " (active)"
 			}
 			fmt.Printf("  %s%s\n", name, marker)
+			profile, err := cfg.ResolveProfile(name)
+			if err != nil {
+				fmt.Printf("    Error: %v\n\n", err)
+				continue
+			}
+			if profile.Extends != "" {
+				fmt.Printf("    Extends: %s\n", profile.Extends)
+			}
 			fmt.Printf("    Model: %s\n", profile.Model)
 			if profile.BaseURL != "" {
 				fmt.Printf("    Base URL: %s\n", profile.BaseURL)
 			}
-			hasKey := "No"
-			if profile.APIKey != "" {
-				hasKey = "Yes"
-			}
-			fmt.Printf("    API Key: %s\n", hasKey)
+			fmt.Printf("    API Key: %s\n", profile.APIKeySource())
 			fmt.Println()
 		}
 	},
@@ -52,25 +64,35 @@ var showProfileCmd = &cobra.Command{
 	Short: "Show profile details",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.LoadConfig()
+		cfg, err := config.LoadGlobalConfig()
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
 
 		profileName := args[0]
-		profile, exists := cfg.Profiles[profileName]
-		if !exists {
-			log.Fatalf("Profile '%s' does not exist", profileName)
+		profile, err := cfg.ResolveProfile(profileName)
+		if err != nil {
+			log.Fatalf("%v", err)
 		}
 
 		fmt.Printf("Profile: %s\n", profileName)
+		if profile.Extends != "" {
+			fmt.Printf("Extends: %s (unset fields are inherited)\n", profile.Extends)
+		}
 		fmt.Printf("Model: %s\n", profile.Model)
 		fmt.Printf("Base URL: %s\n", profile.BaseURL)
-		hasKey := "Not set"
-		if profile.APIKey != "" {
-			hasKey = "Set (hidden for security)"
+		fmt.Printf("API Key: %s\n", profile.APIKeySource())
+		if profile.IsTextOnly() {
+			fmt.Println("Image Input: No (text-only)")
+		} else {
+			fmt.Println("Image Input: Yes")
+		}
+		for _, line := range profile.ParameterLines() {
+			fmt.Println(line)
+		}
+		if err := profile.Validate(); err != nil {
+			fmt.Printf("Warning: %v\n", err)
 		}
-		fmt.Printf("API Key: %s\n", hasKey)
 	},
 }
 
@@ -79,7 +101,7 @@ var addProfileCmd = &cobra.Command{
 	Short: "Add a new profile",
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.LoadConfig()
+		cfg, err := config.LoadGlobalConfig()
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
@@ -103,13 +125,8 @@ var addProfileCmd = &cobra.Command{
 
 		profile := config.Profile{}
 
-		// Prompt for API Key
-		apiKeyPrompt := promptui.Prompt{
-			Label: "API Key",
-			Mask:  '*',
-		}
-		profile.APIKey, err = apiKeyPrompt.Run()
-		if err != nil {
+		// Prompt for the API key or where to read it from
+		if err := promptAPIKey(&profile); err != nil {
 			log.Fatalf("Prompt failed: %v", err)
 		}
 
@@ -132,6 +149,17 @@ var addProfileCmd = &cobra.Command{
 			log.Fatalf("Prompt failed: %v", err)
 		}
 
+		// Ask whether the model accepts image attachments
+		profile.TextOnly, err = promptTextOnly(nil, false)
+		if err != nil {
+			log.Fatalf("Selection failed: %v", err)
+		}
+
+		// Optional model parameters
+		if err := promptModelParams(&profile, false); err != nil {
+			log.Fatalf("Invalid model parameters: %v", err)
+		}
+
 		// Add profile to config
 		cfg.Profiles[profileName] = profile
 
@@ -149,7 +177,7 @@ var editProfileCmd = &cobra.Command{
 	Short: "Edit an existing profile",
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.LoadConfig()
+		cfg, err := config.LoadGlobalConfig()
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
@@ -184,16 +212,9 @@ var editProfileCmd = &cobra.Command{
 		}
 
 		// Edit API Key
-		apiKeyPrompt := promptui.Prompt{
-			Label:   "API Key",
-			Default: profile.APIKey,
-			Mask:    '*',
-		}
-		newAPIKey, err := apiKeyPrompt.Run()
-		if err != nil {
+		if err := promptAPIKey(&profile); err != nil {
 			log.Fatalf("Prompt failed: %v", err)
 		}
-		profile.APIKey = newAPIKey
 
 		// Edit Model
 		modelPrompt := promptui.Prompt{
@@ -217,6 +238,17 @@ var editProfileCmd = &cobra.Command{
 		}
 		profile.BaseURL = newBaseURL
 
+		// Edit image support
+		profile.TextOnly, err = promptTextOnly(profile.TextOnly, profile.Extends != "")
+		if err != nil {
+			log.Fatalf("Selection failed: %v", err)
+		}
+
+		// Edit model parameters
+		if err := promptModelParams(&profile, true); err != nil {
+			log.Fatalf("Invalid model parameters: %v", err)
+		}
+
 		// Update profile in config
 		cfg.Profiles[profileName] = profile
 
@@ -234,7 +266,7 @@ var deleteProfileCmd = &cobra.Command{
 	Short: "Delete a profile",
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.LoadConfig()
+		cfg, err := config.LoadGlobalConfig()
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
@@ -266,6 +298,22 @@ var deleteProfileCmd = &cobra.Command{
 		if _, exists := cfg.Profiles[profileName]; !exists {
 			log.Fatalf("Profile '%s' does not exist", profileName)
 		}
+		// Only profiles that work now block the deletion; one whose chain is
+		// already broken, such as by a cycle through this profile, does not
+		var blocking, broken []string
+		for _, dependent := range cfg.Dependents(profileName) {
+			if _, err := cfg.ResolveProfile(dependent); err == nil {
+				blocking = append(blocking, dependent)
+			} else {
+				broken = append(broken, dependent)
+			}
+		}
+		if len(blocking) > 0 {
+			log.Fatalf("Profile '%s' is extended by %s; change or delete those first", profileName, strings.Join(blocking, ", "))
+		}
+		if len(broken) > 0 {
+			fmt.Printf("Note: profiles extending '%s' that were already broken: %s\n", profileName, strings.Join(broken, ", "))
+		}
 
 		// Confirm deletion
 		confirmPrompt := promptui.Prompt{
@@ -315,7 +363,7 @@ var switchProfileCmd = &cobra.Command{
 	Short: "Switch to a different profile",
 	Args:  cobra.MaximumNArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		cfg, err := config.LoadConfig()
+		cfg, err := config.LoadGlobalConfig()
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
@@ -347,8 +395,9 @@ var switchProfileCmd = &cobra.Command{
 			}
 		}
 
-		if _, exists := cfg.Profiles[profileName]; !exists {
-			log.Fatalf("Profile '%s' does not exist", profileName)
+		// A profile whose extends chain is broken could not be loaded
+		if _, err := cfg.ResolveProfile(profileName); err != nil {
+			log.Fatalf("%v", err)
 		}
 
 		cfg.ActiveProfile = profileName
@@ -362,6 +411,452 @@ var switchProfileCmd = &cobra.Command{
 	},
 }
 
+var testProfileCmd = &cobra.Command{
+	Use:   "test [profile-name]",
+	Short: "Check that a profile works",
+	Long: `Check that a profile works: its API key can be read, the base URL is reachable
+and accepts the key, the model is listed by /models and it can call tools. The
+last check sends one small request to the model. Without a name, the active
+profile is tested.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) > 0 {
+			if err := config.SetOverride("active_profile", args[0], "argument"); err != nil {
+				log.Fatalf("%v", err)
+			}
+		}
+		cfg, err := config.LoadConfig()
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		profile := cfg.CurrentProfile()
+		if profile.Model == "" {
+			log.Fatalf("Profile '%s' has no model configured", cfg.ActiveProfile)
+		}
+
+		baseURL := profile.BaseURL
+		if baseURL == "" {
+			baseURL = "default"
+		}
+		fmt.Printf("Testing profile '%s' (model %s, base URL %s)\n\n", cfg.ActiveProfile, profile.Model, baseURL)
+
+		ctx, cancel := context.WithTimeout(context.Background(), apiCheckTimeout)
+		defer cancel()
+		failed := false
+		for _, result := range provider.CheckProfile(ctx, profile) {
+			label := "PASS"
+			switch result.Status {
+			case provider.Warn:
+				label = "WARN"
+			case provider.Fail:
+				label = "FAIL"
+				failed = true
+			}
+			fmt.Printf("  [%s] %s: %s\n", label, result.Name, result.Detail)
+		}
+		if failed {
+			os.Exit(1)
+		}
+	},
+}
+
+var exportProfileCmd = &cobra.Command{
+	Use:   "export <profile-name>...",
+	Short: "Export profiles for sharing",
+	Long: `Print the named profiles, and the profiles they extend, as a JSON bundle that
+profile import reads. API keys, key files, key commands and headers are left
+out; api_key_env is kept since it only names a variable.`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		cfg, err := config.LoadGlobalConfig()
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+
+		bundle, notes, err := cfg.ExportProfiles(args)
+		if err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		data, err := json.MarshalIndent(bundle, "", "  ")
+		if err != nil {
+			log.Fatalf("Export failed: %v", err)
+		}
+		fmt.Println(string(data))
+
+		// Notes go to stderr so the bundle can be redirected to a file
+		for _, note := range notes {
+			fmt.Fprintf(os.Stderr, "Note: %s\n", note)
+		}
+	},
+}
+
+var (
+	replaceProfiles bool
+	trustEndpoints  bool
+)
+
+var importProfileCmd = &cobra.Command{
+	Use:   "import <file|->",
+	Short: "Import profiles from a bundle",
+	Long: `Add the profiles in a bundle written by profile export, read from a file or,
+with -, from standard input. Unknown fields, missing bases, extends cycles and
+invalid model parameters are rejected before anything is saved. Existing
+profiles are only replaced with --replace, and keep their API key when the
+imported profile has none.
+
+Bundles cannot set api_key, api_key_file or api_key_command. When a profile
+would send an API key you already have to a base URL, embeddings URL or
+headers it is not sent to today (through api_key_env, extends or a replaced
+profile keeping its key), the profiles are listed and the import needs
+confirmation, or --trust-endpoints when the bundle is read from standard input.`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		var data []byte
+		var err error
+		if args[0] == "-" {
+			data, err = io.ReadAll(os.Stdin)
+		} else {
+			data, err = os.ReadFile(args[0])
+		}
+		if err != nil {
+			log.Fatalf("Failed to read bundle: %v", err)
+		}
+		bundle, err := config.ParseProfileBundle(data)
+		if err != nil {
+			log.Fatalf("%v", err)
+		}
+
+		cfg, err := config.LoadGlobalConfig()
+		if err != nil {
+			log.Fatalf("Failed to load config: %v", err)
+		}
+		names, redirects, err := cfg.ImportProfiles(bundle, replaceProfiles, trustEndpoints)
+		if len(redirects) > 0 {
+			fmt.Println("These profiles would send a local API key to an endpoint it is not sent to today:")
+			for _, redirect := range redirects {
+				fmt.Printf("  %s: key from %s sent to %s\n", redirect.Profile, redirect.KeySource, redirect.Endpoint)
+			}
+		}
+		if errors.Is(err, config.ErrKeyRedirect) {
+			if args[0] == "-" {
+				log.Fatalf("Import refused; check the endpoints and rerun with --trust-endpoints to import anyway")
+			}
+			confirmPrompt := promptui.Prompt{
+				Label:     "Import anyway? (y/N)",
+				IsConfirm: true,
+			}
+			if _, err := confirmPrompt.Run(); err != nil {
+				fmt.Println("Import cancelled")
+				return
+			}
+			names, _, err = cfg.ImportProfiles(bundle, replaceProfiles, true)
+		}
+		if err != nil {
+			log.Fatalf("Import failed: %v", err)
+		}
+		if err := cfg.Save(); err != nil {
+			log.Fatalf("Failed to save config: %v", err)
+		}
+
+		for _, name := range names {
+			fmt.Printf("Imported profile '%s'\n", name)
+		}
+		for _, name := range names {
+			if profile, err := cfg.ResolveProfile(name); err == nil && !profile.HasAPIKey() {
+				fmt.Printf("Profile '%s' has no API key; set one with: roricode profile edit %s\n", name, name)
+			}
+		}
+	},
+}
+
+// promptAPIKey asks where the profile's API key comes from and sets that
+// source, clearing the others
+func promptAPIKey(profile *config.Profile) error {
+	sources := []string{
+		"Enter the key (stored in plaintext in the config file)",
+		"Environment variable",
+		"File",
+		"Command (e.g. pass show openai)",
+	}
+	cursor := 0
+	switch {
+	case profile.APIKeyEnv != "":
+		cursor = 1
+	case profile.APIKeyFile != "":
+		cursor = 2
+	case profile.APIKeyCommand != "":
+		cursor = 3
+	}
+	sourcePrompt := promptui.Select{
+		Label:     "API key source",
+		Items:     sources,
+		CursorPos: cursor,
+	}
+	index, _, err := sourcePrompt.Run()
+	if err != nil {
+		return err
+	}
+
+	var prompt promptui.Prompt
+	switch index {
+	case 0:
+		prompt = promptui.Prompt{Label: "API Key", Default: profile.APIKey, Mask: '*'}
+	case 1:
+		prompt = promptui.Prompt{Label: "Environment variable", Default: profile.APIKeyEnv}
+	case 2:
+		prompt = promptui.Prompt{Label: "Key file path", Default: profile.APIKeyFile}
+	case 3:
+		prompt = promptui.Prompt{Label: "Command printing the key", Default: profile.APIKeyCommand}
+	}
+	value, err := prompt.Run()
+	if err != nil {
+		return err
+	}
+
+	profile.APIKey, profile.APIKeyEnv, profile.APIKeyFile, profile.APIKeyCommand = "", "", "", ""
+	switch index {
+	case 0:
+		profile.APIKey = value
+	case 1:
+		profile.APIKeyEnv = value
+	case 2:
+		profile.APIKeyFile = value
+	case 3:
+		profile.APIKeyCommand = value
+	}
+	return nil
+}
+
+// promptTextOnly asks whether the profile's model accepts image input. A
+// profile that extends another can also leave it to its base.
+func promptTextOnly(current *bool, inherits bool) (*bool, error) {
+	items := []string{"Yes", "No (text-only)"}
+	if inherits {
+		items = append(items, "Same as the base profile")
+	}
+	cursor := 0
+	switch {
+	case current != nil && *current:
+		cursor = 1
+	case current == nil && inherits:
+		cursor = 2
+	}
+	prompt := promptui.Select{
+		Label:     "Does the model accept image input?",
+		Items:     items,
+		CursorPos: cursor,
+	}
+	index, _, err := prompt.Run()
+	if err != nil {
+		return current, err
+	}
+	switch {
+	case index == 1:
+		textOnly := true
+		return &textOnly, nil
+	case index == 0 && inherits:
+		// Stated explicitly so a text-only base is overridden
+		textOnly := false
+		return &textOnly, nil
+	}
+	return nil, nil
+}
+
+// promptModelParams asks for the optional model parameters. Empty answers
+// leave a parameter to the provider's default.
+func promptModelParams(profile *config.Profile, editing bool) error {
+	items := []string{"No, use the provider's defaults", "Yes"}
+	if editing {
+		items[0] = "No, keep the current parameters"
+	}
+	askPrompt := promptui.Select{
+		Label: "Configure model parameters (temperature, max tokens, ...)?",
+		Items: items,
+	}
+	index, _, err := askPrompt.Run()
+	if err != nil || index == 0 {
+		return err
+	}
+
+	if profile.Temperature, err = promptFloat("Temperature (0-2)", profile.Temperature, 0, 2); err != nil {
+		return err
+	}
+	if profile.TopP, err = promptFloat("Top P (0-1)", profile.TopP, 0, 1); err != nil {
+		return err
+	}
+	if profile.MaxTokens, err = promptInt("Max tokens (0 for no limit)", profile.MaxTokens, 0); err != nil {
+		return err
+	}
+	if profile.Seed, err = promptInt("Seed", profile.Seed, 0); err != nil {
+		return err
+	}
+
+	stopPrompt := promptui.Prompt{
+		Label:   "Stop sequences (comma-separated, up to 4)",
+		Default: strings.Join(profile.Stop, ","),
+	}
+	stop, err := stopPrompt.Run()
+	if err != nil {
+		return err
+	}
+	profile.Stop = nil
+	for _, seq := range strings.Split(stop, ",") {
+		if seq = strings.TrimSpace(seq); seq != "" {
+			profile.Stop = append(profile.Stop, seq)
+		}
+	}
+
+	efforts := append([]string{"Provider default"}, config.ReasoningEfforts...)
+	effortPrompt := promptui.Select{
+		Label:     "Reasoning effort",
+		Items:     efforts,
+		CursorPos: indexOf(efforts, profile.ReasoningEffort),
+	}
+	if index, _, err = effortPrompt.Run(); err != nil {
+		return err
+	}
+	profile.ReasoningEffort = ""
+	if index > 0 {
+		profile.ReasoningEffort = efforts[index]
+	}
+
+	cursor := 0
+	if profile.ParallelToolCalls != nil {
+		cursor = 1
+		if !*profile.ParallelToolCalls {
+			cursor = 2
+		}
+	}
+	parallelPrompt := promptui.Select{
+		Label:     "Parallel tool calls",
+		Items:     []string{"Provider default", "Enabled", "Disabled"},
+		CursorPos: cursor,
+	}
+	if index, _, err = parallelPrompt.Run(); err != nil {
+		return err
+	}
+	profile.ParallelToolCalls = nil
+	if index > 0 {
+		enabled := index == 1
+		profile.ParallelToolCalls = &enabled
+	}
+
+	toolChoicePrompt := promptui.Prompt{
+		Label:    "Tool choice (auto, none, required or a tool name)",
+		Default:  profile.ToolChoice,
+		Validate: config.ValidateToolChoice,
+	}
+	if profile.ToolChoice, err = toolChoicePrompt.Run(); err != nil {
+		return err
+	}
+
+	var headerPairs []string
+	for name, value := range profile.Headers {
+		headerPairs = append(headerPairs, name+": "+value)
+	}
+	headersPrompt := promptui.Prompt{
+		Label:   "Extra headers (Name: value; Name: value)",
+		Default: strings.Join(headerPairs, "; "),
+		Mask:    '*',
+	}
+	headers, err := headersPrompt.Run()
+	if err != nil {
+		return err
+	}
+	profile.Headers = nil
+	for _, pair := range strings.Split(headers, ";") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(pair, ":")
+		if !ok {
+			return fmt.Errorf("header %q must be written as Name: value", strings.TrimSpace(pair))
+		}
+		if profile.Headers == nil {
+			profile.Headers = make(map[string]string)
+		}
+		profile.Headers[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+
+	orgPrompt := promptui.Prompt{Label: "Organization ID (optional)", Default: profile.Organization}
+	if profile.Organization, err = orgPrompt.Run(); err != nil {
+		return err
+	}
+	projectPrompt := promptui.Prompt{Label: "Project ID (optional)", Default: profile.Project}
+	if profile.Project, err = projectPrompt.Run(); err != nil {
+		return err
+	}
+
+	return profile.Validate()
+}
+
+// promptFloat asks for an optional number between min and max
+func promptFloat(label string, current *float32, min, max float64) (*float32, error) {
+	def := ""
+	if current != nil {
+		def = strconv.FormatFloat(float64(*current), 'g', -1, 32)
+	}
+	prompt := promptui.Prompt{
+		Label:   label + " (empty for default)",
+		Default: def,
+		Validate: func(input string) error {
+			if strings.TrimSpace(input) == "" {
+				return nil
+			}
+			value, err := strconv.ParseFloat(strings.TrimSpace(input), 32)
+			if err != nil || value < min || value > max {
+				return fmt.Errorf("enter a number between %g and %g", min, max)
+			}
+			return nil
+		},
+	}
+	input, err := prompt.Run()
+	if err != nil || strings.TrimSpace(input) == "" {
+		return nil, err
+	}
+	value, _ := strconv.ParseFloat(strings.TrimSpace(input), 32)
+	result := float32(value)
+	return &result, nil
+}
+
+// promptInt asks for an optional whole number of at least min
+func promptInt(label string, current *int, min int) (*int, error) {
+	def := ""
+	if current != nil {
+		def = strconv.Itoa(*current)
+	}
+	prompt := promptui.Prompt{
+		Label:   label + " (empty for default)",
+		Default: def,
+		Validate: func(input string) error {
+			if strings.TrimSpace(input) == "" {
+				return nil
+			}
+			value, err := strconv.Atoi(strings.TrimSpace(input))
+			if err != nil || value < min {
+				return fmt.Errorf("enter a whole number of at least %d", min)
+			}
+			return nil
+		},
+	}
+	input, err := prompt.Run()
+	if err != nil || strings.TrimSpace(input) == "" {
+		return nil, err
+	}
+	value, _ := strconv.Atoi(strings.TrimSpace(input))
+	return &value, nil
+}
+
+// indexOf returns the position of value in list, or 0
+func indexOf(list []string, value string) int {
+	for i, item := range list {
+		if item == value {
+			return i
+		}
+	}
+	return 0
+}
+
 func init() {
 	// Add subcommands to profile
 	profileCmd.AddCommand(listProfilesCmd)
@@ -370,4 +865,10 @@ func init() {
 	profileCmd.AddCommand(editProfileCmd)
 	profileCmd.AddCommand(deleteProfileCmd)
 	profileCmd.AddCommand(switchProfileCmd)
+	profileCmd.AddCommand(testProfileCmd)
+	profileCmd.AddCommand(exportProfileCmd)
+	profileCmd.AddCommand(importProfileCmd)
+
+	importProfileCmd.Flags().BoolVar(&replaceProfiles, "replace", false, "Replace existing profiles with the same name")
+	importProfileCmd.Flags().BoolVar(&trustEndpoints, "trust-endpoints", false, "Import even if local API keys would be sent to new endpoints")
 }
\ No newline at end of file
@@ -1,18 +1,33 @@
 package cmd
 
 import (
+	"fmt"
 	"log"
 	"os"
 
 	"github.com/spf13/cobra"
 
 	"github.com/Rorical/RoriCode/internal/app"
+	"github.com/Rorical/RoriCode/internal/config"
+)
+
+// Global flags override the configuration for one run without saving it.
+// Each has a RORICODE_* environment variable; the flag wins when both are set.
+var (
+	flagProfile string
+	flagModel   string
+	flagBaseURL string
+	flagCwd     string
+	flagConfig  string
 )
 
 var rootCmd = &cobra.Command{
 	Use:   "roricode",
 	Short: "Another Terminal Coding Agent",
 	Long:  `RoriCode is Another Terminal Coding Agent designed for fast and simplicity.`,
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		return applyGlobalFlags(cmd)
+	},
 	Run: func(cmd *cobra.Command, args []string) {
 		// Default behavior: run the chat application
 		application, err := app.NewApplication()
@@ -34,7 +49,54 @@ func Execute() {
 	}
 }
 
+// applyGlobalFlags applies the global flags and their environment variables
+func applyGlobalFlags(cmd *cobra.Command) error {
+	if dir, _ := flagOrEnv(cmd, "cwd", flagCwd, "RORICODE_CWD"); dir != "" {
+		if err := os.Chdir(dir); err != nil {
+			return fmt.Errorf("failed to change directory: %w", err)
+		}
+	}
+	if path, _ := flagOrEnv(cmd, "config", flagConfig, "RORICODE_CONFIG"); path != "" {
+		config.SetConfigPath(path)
+	}
+
+	overrides := []struct {
+		flag, value, env, key string
+	}{
+		{"profile", flagProfile, "RORICODE_PROFILE", "active_profile"},
+		{"model", flagModel, "RORICODE_MODEL", "model"},
+		{"base-url", flagBaseURL, "RORICODE_BASE_URL", "base_url"},
+	}
+	for _, o := range overrides {
+		if value, source := flagOrEnv(cmd, o.flag, o.value, o.env); value != "" {
+			if err := config.SetOverride(o.key, value, source); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// flagOrEnv returns the flag's value when it was given, otherwise the
+// environment variable's, with a description of where it came from
+func flagOrEnv(cmd *cobra.Command, flag, value, env string) (string, string) {
+	if cmd.Flags().Changed(flag) {
+		return value, "--" + flag + " flag"
+	}
+	if value := os.Getenv(env); value != "" {
+		return value, env
+	}
+	return "", ""
+}
+
 func init() {
+	flags := rootCmd.PersistentFlags()
+	flags.StringVar(&flagProfile, "profile", "", "Profile to use for this run (env RORICODE_PROFILE)")
+	flags.StringVar(&flagModel, "model", "", "Model to use for this run (env RORICODE_MODEL)")
+	flags.StringVar(&flagBaseURL, "base-url", "", "API base URL to use for this run (env RORICODE_BASE_URL)")
+	flags.StringVar(&flagCwd, "cwd", "", "Directory to work in (env RORICODE_CWD)")
+	flags.StringVar(&flagConfig, "config", "", "Global config file to use instead of ~/.roricode/config.json (env RORICODE_CONFIG)")
+
 	// Add subcommands
 	rootCmd.AddCommand(profileCmd)
 }
@@ -17,15 +17,15 @@ var useCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		profileName := args[0]
 
-		// Load config
-		cfg, err := config.LoadConfig()
+		// Load the global config, which is where the active profile is saved
+		cfg, err := config.LoadGlobalConfig()
 		if err != nil {
 			log.Fatalf("Failed to load config: %v", err)
 		}
 
-		// Check if profile exists
-		if _, exists := cfg.Profiles[profileName]; !exists {
-			log.Fatalf("Profile '%s' does not exist", profileName)
+		// Check that the profile exists and its extends chain resolves
+		if _, err := cfg.ResolveProfile(profileName); err != nil {
+			log.Fatalf("%v", err)
 		}
 
 		// Switch to the profile
@@ -3,6 +3,7 @@ package app
 import (
 	"log"
 
+	"github.com/Rorical/RoriCode/internal/attach"
 	"github.com/Rorical/RoriCode/internal/config"
 	"github.com/Rorical/RoriCode/internal/core"
 	"github.com/Rorical/RoriCode/internal/dispatcher"
@@ -23,6 +24,16 @@ type AppModel struct {
 	appModel      models.AppModel
 	dispatcher    *dispatcher.EventDispatcher
 	statusShown   bool // Track if we have a status bar that needs clearing
+
+	// Live output of the running tool call, drawn as a rolling window
+	liveCallID string
+	liveLines  []string
+	liveShown  int // Lines of the window currently on screen
+
+	input *lineReader // Input line editor
+
+	// Images queued with /attach for the next message
+	pendingImages []*attach.Image
 }
 
 func NewApplication() (*Application, error) {
@@ -0,0 +1,148 @@
+package app
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/chzyer/readline"
+)
+
+// lineReader reads input lines. On a terminal it is a line editor with
+// history and tab completion of paths; otherwise lines are read from stdin
+// as they come.
+type lineReader struct {
+	rl      *readline.Instance
+	scanner *bufio.Scanner
+}
+
+func newLineReader() *lineReader {
+	if term.IsTerminal(os.Stdin.Fd()) {
+		rl, err := readline.NewEx(&readline.Config{
+			Prompt:          "> ",
+			AutoComplete:    pathCompleter{},
+			InterruptPrompt: "\n",
+			EOFPrompt:       "\n",
+		})
+		if err == nil {
+			return &lineReader{rl: rl}
+		}
+	}
+	return &lineReader{scanner: bufio.NewScanner(os.Stdin)}
+}
+
+// ReadLine returns the next line; Ctrl+C and Ctrl+D end input with an error
+func (r *lineReader) ReadLine() (string, error) {
+	if r.rl != nil {
+		return r.rl.Readline()
+	}
+	if !r.scanner.Scan() {
+		if err := r.scanner.Err(); err != nil {
+			return "", err
+		}
+		return "", io.EOF
+	}
+	return r.scanner.Text(), nil
+}
+
+// SetPrompt shows prompt in front of the input line; an empty prompt hides
+// it while the assistant is working
+func (r *lineReader) SetPrompt(prompt string) {
+	if r.rl == nil {
+		fmt.Print(prompt)
+		return
+	}
+	r.rl.SetPrompt(prompt)
+	r.rl.Refresh()
+}
+
+// Write prints p above the input line. While a line is being read the line
+// editor clears it first and redraws it after, so output from the event
+// loop does not garble what the user is typing.
+func (r *lineReader) Write(p []byte) (int, error) {
+	if r.rl != nil {
+		return r.rl.Stdout().Write(p)
+	}
+	return os.Stdout.Write(p)
+}
+
+func (r *lineReader) Close() {
+	if r.rl != nil {
+		r.rl.Close()
+	}
+}
+
+// pathCompleter completes file paths after "@" and in "/attach" arguments.
+// Mentions stay inside the working directory, like the mentions themselves.
+type pathCompleter struct{}
+
+func (pathCompleter) Do(line []rune, pos int) ([][]rune, int) {
+	text := string(line[:pos])
+	start := strings.LastIndexAny(text, " \t") + 1
+	token := text[start:]
+
+	var prefix string
+	mention := strings.HasPrefix(token, "@")
+	switch {
+	case mention:
+		prefix = token[1:]
+		if filepath.IsAbs(prefix) || strings.HasPrefix(prefix, "..") || strings.Contains(prefix, "/..") {
+			return nil, 0
+		}
+	case strings.HasPrefix(text, "/attach ") && start >= len("/attach "):
+		prefix = token
+	default:
+		return nil, 0
+	}
+	if strings.Contains(prefix, ":") {
+		return nil, 0 // Line ranges are not completed
+	}
+
+	dir, base := "", prefix
+	if i := strings.LastIndex(prefix, "/"); i >= 0 {
+		dir, base = prefix[:i+1], prefix[i+1:]
+	}
+	lookup := dir
+	if strings.HasPrefix(lookup, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			lookup = filepath.Join(home, lookup[2:])
+		}
+	}
+	if lookup == "" {
+		lookup = "."
+	}
+
+	entries, err := os.ReadDir(lookup)
+	if err != nil {
+		return nil, 0
+	}
+	var candidates [][]rune
+	for _, entry := range entries {
+		name := entry.Name()
+		if !strings.HasPrefix(name, base) {
+			continue
+		}
+		// Hidden entries only when asked for
+		if strings.HasPrefix(name, ".") && !strings.HasPrefix(base, ".") {
+			continue
+		}
+		isDir := entry.IsDir()
+		if entry.Type()&os.ModeSymlink != 0 {
+			if info, err := os.Stat(filepath.Join(lookup, name)); err == nil {
+				isDir = info.IsDir()
+			}
+		}
+		suffix := name[len(base):]
+		if isDir {
+			suffix += "/"
+		} else {
+			suffix += " "
+		}
+		candidates = append(candidates, []rune(suffix))
+	}
+	return candidates, len([]rune(base))
+}
@@ -1,30 +1,38 @@
 package app
 
 import (
-	"bufio"
 	"encoding/json"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
+	"github.com/Rorical/RoriCode/internal/attach"
 	"github.com/Rorical/RoriCode/internal/eventbus"
 	"github.com/Rorical/RoriCode/internal/models"
 	"github.com/Rorical/RoriCode/internal/utils"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/charmbracelet/x/term"
 )
 
+// liveOutputLines is the height of the live tool output window
+const liveOutputLines = 8
+
 // Terminal control sequences
-func clearLine() {
-	fmt.Print("\033[2K") // Clear entire line
+func clearLine(w io.Writer) {
+	fmt.Fprint(w, "\033[2K") // Clear entire line
 }
 
-func moveCursorUp(lines int) {
-	fmt.Printf("\033[%dA", lines) // Move cursor up N lines
+func moveCursorUp(w io.Writer, lines int) {
+	fmt.Fprintf(w, "\033[%dA", lines) // Move cursor up N lines
 }
 
 // Start the simple fmt-based UI loop
 func (m *AppModel) Start() {
 	// Initialize basic state
 	m.appModel.Status = "Ready"
+	m.input = newLineReader()
+	defer m.input.Close()
 
 	// Start listening for core events in background
 	go m.listenForCoreEvents()
@@ -46,11 +54,27 @@ func (m *AppModel) listenForCoreEvents() {
 func (m *AppModel) handleCoreEvent(coreEvent eventbus.CoreEvent) {
 	// Handle confirmation requests
 	if confirmationEvent, ok := coreEvent.(eventbus.ConfirmationRequestEvent); ok {
+		m.collapseLiveOutput()
 		m.handleConfirmationRequest(confirmationEvent)
 		return
 	}
 
+	// Handle live tool output
+	if outputEvent, ok := coreEvent.(eventbus.ToolOutputEvent); ok {
+		m.handleToolOutput(outputEvent)
+		return
+	}
+
+	// Warn that secrets were removed from a tool result
+	if redactionEvent, ok := coreEvent.(eventbus.RedactionEvent); ok {
+		m.handleRedaction(redactionEvent)
+		return
+	}
+
 	if stateEvent, ok := coreEvent.(eventbus.StateUpdateEvent); ok {
+		// A finished tool's output window collapses into its result summary
+		m.collapseLiveOutput()
+
 		// Core now only sends new messages, so we can print them all
 		newMessages := stateEvent.Messages
 
@@ -86,7 +110,7 @@ func (m *AppModel) handleCoreEvent(coreEvent eventbus.CoreEvent) {
 
 		// Print new prompt after message (only if not processing)
 		if !stateEvent.IsProcessing {
-			fmt.Print("> ")
+			m.input.SetPrompt("> ")
 		}
 	}
 }
@@ -128,7 +152,7 @@ func (m *AppModel) printMessageWithStatusHandling(msg models.Message) {
 func (m *AppModel) printMessageToScrollArea(msg models.Message) {
 	switch msg.Type {
 	case models.User:
-		fmt.Println(utils.UserStyle().Render("> " + msg.Content))
+		fmt.Fprintln(m.input, utils.UserStyle().Render("> "+msg.Content))
 	case models.Assistant:
 		// Render markdown for assistant messages
 		renderedContent := utils.RenderMarkdown(msg.Content)
@@ -138,18 +162,18 @@ func (m *AppModel) printMessageToScrollArea(msg models.Message) {
 			lines[i] = "   " + lines[i]
 		}
 		indentedContent := strings.Join(lines, "\n")
-		fmt.Print(utils.AssistantStyle().Render(">> "+indentedContent) + "\n")
+		fmt.Fprint(m.input, utils.AssistantStyle().Render(">> "+indentedContent)+"\n")
 	case models.Program:
-		fmt.Println(utils.ProgramStyle().Render(msg.Content))
+		fmt.Fprintln(m.input, utils.ProgramStyle().Render(msg.Content))
 	case models.ToolCall:
 		// Format tool call with name and arguments
 		toolCallContent := fmt.Sprintf("「%s(%s)」", msg.ToolName, msg.ToolArgs)
-		fmt.Println(utils.ToolCallStyle().Render(toolCallContent))
+		fmt.Fprintln(m.input, utils.ToolCallStyle().Render(toolCallContent))
 	case models.ToolResult:
 		// Format tool result with user-friendly summary instead of raw JSON
 		formattedResult := m.formatToolResult(msg.ToolName, msg.Content)
 		toolResultContent := fmt.Sprintf("·%s → %s", msg.ToolName, formattedResult)
-		fmt.Println(utils.ToolResultStyle().Render(toolResultContent))
+		fmt.Fprintln(m.input, utils.ToolResultStyle().Render(toolResultContent))
 	}
 }
 
@@ -173,6 +197,10 @@ func (m *AppModel) formatToolResult(toolName, content string) string {
 		return strings.Trim(content, "\"") // Remove JSON quotes if present
 	case "shell":
 		return m.formatShellResult(result)
+	case "find_files":
+		return m.formatFindFilesResult(result)
+	case "run_tests":
+		return m.formatRunTestsResult(result)
 	default:
 		// For unknown tools, provide a generic summary
 		return m.formatGenericResult(result)
@@ -237,6 +265,58 @@ func (m *AppModel) formatShellResult(result map[string]any) string {
 	return fmt.Sprintf("Command successful: %s", strings.TrimSpace(output))
 }
 
+// formatFindFilesResult creates a summary for file search results
+func (m *AppModel) formatFindFilesResult(result map[string]any) string {
+	pattern, _ := result["pattern"].(string)
+	count, _ := result["count"].(float64)
+	total, _ := result["total_matches"].(float64)
+
+	if total == 0 {
+		return fmt.Sprintf("No files matching %s", pattern)
+	}
+	if total > count {
+		return fmt.Sprintf("Found %.0f files matching %s (showing newest %.0f)", total, pattern, count)
+	}
+	return fmt.Sprintf("Found %.0f files matching %s", total, pattern)
+}
+
+// formatRunTestsResult creates a summary for test runs, naming the first failures
+func (m *AppModel) formatRunTestsResult(result map[string]any) string {
+	if aborted, _ := result["aborted"].(bool); aborted {
+		return "Test run aborted"
+	}
+
+	summary, hasSummary := result["summary"].(string)
+	if !hasSummary {
+		exitCode, _ := result["exit_code"].(float64)
+		if exitCode != 0 {
+			return fmt.Sprintf("Tests failed (exit %d)", int(exitCode))
+		}
+		return "Tests passed"
+	}
+	if timedOut, _ := result["timed_out"].(bool); timedOut {
+		summary += " (timed out)"
+	}
+
+	failures, _ := result["failures"].([]any)
+	var names []string
+	for _, f := range failures {
+		if failure, ok := f.(map[string]any); ok && len(names) < 3 {
+			name, _ := failure["name"].(string)
+			names = append(names, name)
+		}
+	}
+	if len(names) > 0 {
+		summary += ": " + strings.Join(names, ", ")
+		if len(failures) > len(names) {
+			summary += fmt.Sprintf(" and %d more", len(failures)-len(names))
+		}
+	} else if _, ok := result["build_errors"]; ok {
+		summary += " (build failed)"
+	}
+	return summary
+}
+
 // formatGenericResult provides a fallback summary for unknown tool results
 func (m *AppModel) formatGenericResult(result map[string]any) string {
 	// Look for common fields that might indicate success or provide summary info
@@ -276,15 +356,15 @@ func (m *AppModel) handleConfirmationRequest(request eventbus.ConfirmationReques
 	}
 
 	// Show the confirmation prompt using the local copy
-	fmt.Printf("\n%s\n", utils.ProgramStyle().Render("CONFIRMATION REQUIRED"))
-	fmt.Printf("Operation: %s\n", utils.BoldStyle().Render(m.appModel.PendingConfirmation.Operation))
+	fmt.Fprintf(m.input, "\n%s\n", utils.ProgramStyle().Render("CONFIRMATION REQUIRED"))
+	fmt.Fprintf(m.input, "Operation: %s\n", utils.BoldStyle().Render(m.appModel.PendingConfirmation.Operation))
 	if m.appModel.PendingConfirmation.Command != "" {
-		fmt.Printf("Content: %s\n", utils.CodeBlockStyle().Render(m.appModel.PendingConfirmation.Command))
+		fmt.Fprintf(m.input, "Content: %s\n", utils.CodeBlockStyle().Render(m.appModel.PendingConfirmation.Command))
 	}
 	if m.appModel.PendingConfirmation.Dangerous {
-		fmt.Printf("%s\n", utils.DangerStyle().Render("This operation may be potentially dangerous"))
+		fmt.Fprintf(m.input, "%s\n", utils.DangerStyle().Render("This operation may be potentially dangerous"))
 	}
-	fmt.Print("Do you still want to proceed? (y/N): ")
+	m.input.SetPrompt("Do you still want to proceed? (y/N): ")
 }
 
 // handleConfirmationInput processes user input when a confirmation is pending
@@ -294,8 +374,8 @@ func (m *AppModel) handleConfirmationInput(input string) {
 	}
 
 	// Clear the input line
-	moveCursorUp(1)
-	clearLine()
+	moveCursorUp(m.input, 1)
+	clearLine(m.input)
 
 	input = strings.ToLower(strings.TrimSpace(input))
 	approved := input == "y" || input == "yes"
@@ -308,48 +388,181 @@ func (m *AppModel) handleConfirmationInput(input string) {
 	}
 
 	if err := eventBus.SendToCore(response); err != nil {
-		fmt.Printf("Error sending confirmation response: %s\n", err.Error())
+		fmt.Fprintf(m.input, "Error sending confirmation response: %s\n", err.Error())
 	}
 
 	// Show user's decision
 	if approved {
-		fmt.Printf("%s\n", utils.ListStyle().Render("✓ Approved - proceeding with operation"))
+		fmt.Fprintf(m.input, "%s\n", utils.ListStyle().Render("✓ Approved - proceeding with operation"))
 	} else {
-		fmt.Printf("%s\n", utils.ListStyle().Render("✗ Denied - operation aborted"))
+		fmt.Fprintf(m.input, "%s\n", utils.ListStyle().Render("✗ Denied - operation aborted"))
 	}
 
 	// Clear the pending confirmation
 	m.appModel.PendingConfirmation = nil
 
-	// Print new prompt
-	fmt.Print("> ")
+	// The operation continues; the prompt returns when the response is done
+	m.input.SetPrompt("")
+}
+
+// handleToolOutput shows the latest lines of a running tool's output in a
+// dimmed rolling window above the status bar
+func (m *AppModel) handleToolOutput(event eventbus.ToolOutputEvent) {
+	if m.appModel.PendingConfirmation != nil {
+		return // Don't draw over the confirmation prompt
+	}
+	if event.CallID != m.liveCallID {
+		m.collapseLiveOutput()
+		m.liveCallID = event.CallID
+	}
+
+	m.liveLines = append(m.liveLines, event.Lines...)
+	if over := len(m.liveLines) - liveOutputLines; over > 0 {
+		m.liveLines = m.liveLines[over:]
+	}
+
+	wasStatusShown := m.statusShown
+	if m.statusShown {
+		m.clearPreviousStatus()
+		m.statusShown = false
+	}
+	m.clearLines(m.liveShown)
+
+	width := terminalWidth() - 6 // Style margin and spacing
+	for _, line := range m.liveLines {
+		line = strings.ReplaceAll(line, "\t", "    ")
+		fmt.Fprintln(m.input, utils.LiveOutputStyle().Render(ansi.Truncate(ansi.Strip(line), width, "…")))
+	}
+	m.liveShown = len(m.liveLines)
+
+	if wasStatusShown {
+		m.printStatusBar()
+		m.statusShown = true
+	}
+}
+
+// handleRedaction tells the user that secrets were replaced by placeholders
+// in a tool result before it was sent to the model
+func (m *AppModel) handleRedaction(event eventbus.RedactionEvent) {
+	wasStatusShown := m.statusShown
+	if m.statusShown {
+		m.clearPreviousStatus()
+		m.statusShown = false
+	}
+	fmt.Fprintln(m.input, utils.DangerStyle().Render(fmt.Sprintf("⚠ Redacted from %s result before sending: %s", event.ToolName, event.Summary)))
+	if wasStatusShown {
+		m.printStatusBar()
+		m.statusShown = true
+	}
+}
+
+// collapseLiveOutput removes the live output window from the screen; the
+// tool result printed next summarises it
+func (m *AppModel) collapseLiveOutput() {
+	if m.liveShown > 0 {
+		wasStatusShown := m.statusShown
+		if m.statusShown {
+			m.clearPreviousStatus()
+			m.statusShown = false
+		}
+		m.clearLines(m.liveShown)
+		if wasStatusShown {
+			m.printStatusBar()
+			m.statusShown = true
+		}
+	}
+	m.liveCallID = ""
+	m.liveLines = nil
+	m.liveShown = 0
+}
+
+// clearLines clears the n lines above the cursor, leaving it at the first
+func (m *AppModel) clearLines(n int) {
+	for i := 0; i < n; i++ {
+		moveCursorUp(m.input, 1)
+		clearLine(m.input)
+	}
+}
+
+// terminalWidth returns the width of the terminal, or 100 when unknown
+func terminalWidth() int {
+	if width, _, err := term.GetSize(os.Stdout.Fd()); err == nil && width > 20 {
+		return width
+	}
+	return 100
 }
 
 // printStatusBar prints the current status
 func (m *AppModel) printStatusBar() {
-	fmt.Println(utils.StatusStyle(80).Render(m.appModel.Status))
+	fmt.Fprintln(m.input, utils.StatusStyle(80).Render(m.appModel.Status))
 }
 
 // clearPreviousStatus clears the previous status line
 func (m *AppModel) clearPreviousStatus() {
 	// Move up one line and clear it (where the status was)
-	moveCursorUp(1)
-	clearLine()
+	moveCursorUp(m.input, 1)
+	clearLine(m.input)
+}
+
+// handleAttachCommand handles "/attach [path...|clear]": paths are queued to
+// be sent with the next message; without arguments the queue is listed
+func (m *AppModel) handleAttachCommand(args string) {
+	switch args {
+	case "":
+		if len(m.pendingImages) == 0 {
+			fmt.Fprintln(m.input, utils.ProgramStyle().Render("Usage: /attach <image path>... (png, jpg, gif, webp), /attach clear; or mention @path/to/image.png in a message"))
+			return
+		}
+		for _, img := range m.pendingImages {
+			fmt.Fprintln(m.input, utils.ProgramStyle().Render(fmt.Sprintf("Queued %s (%d KB)", img.Path, (len(img.Data)+1023)/1024)))
+		}
+		return
+	case "clear":
+		m.pendingImages = nil
+		fmt.Fprintln(m.input, utils.ProgramStyle().Render("Cleared queued attachments"))
+		return
+	}
+
+	for _, path := range strings.Fields(args) {
+		img, err := attach.LoadImage(path)
+		if err != nil {
+			fmt.Fprintln(m.input, utils.ProgramStyle().Render("Error: "+err.Error()))
+			continue
+		}
+		m.pendingImages = mergeImages(m.pendingImages, []*attach.Image{img})
+		fmt.Fprintln(m.input, utils.ProgramStyle().Render(fmt.Sprintf("Attached %s (%d KB), sent with your next message", img.Path, (len(img.Data)+1023)/1024)))
+	}
+}
+
+// mergeImages appends images not already in the list, by path
+func mergeImages(list, images []*attach.Image) []*attach.Image {
+	for _, img := range images {
+		duplicate := false
+		for _, existing := range list {
+			if existing.Path == img.Path {
+				duplicate = true
+				break
+			}
+		}
+		if !duplicate {
+			list = append(list, img)
+		}
+	}
+	return list
 }
 
 // inputLoop handles user input with simple console interface
 func (m *AppModel) inputLoop() {
-	scanner := bufio.NewScanner(os.Stdin)
-
 	// Print initial prompt
-	fmt.Print("> ")
+	m.input.SetPrompt("> ")
 
 	for {
-		if !scanner.Scan() {
+		line, err := m.input.ReadLine()
+		if err != nil {
 			break
 		}
 
-		input := strings.TrimSpace(scanner.Text())
+		input := strings.TrimSpace(line)
 
 		// Check if we're waiting for a confirmation response
 		if m.appModel.PendingConfirmation != nil {
@@ -358,7 +571,7 @@ func (m *AppModel) inputLoop() {
 		}
 
 		if input == "" {
-			fmt.Print("> ")
+			m.input.SetPrompt("> ")
 			continue
 		}
 
@@ -367,25 +580,44 @@ func (m *AppModel) inputLoop() {
 			break
 		}
 
+		// Queue image attachments for the next message
+		if input == "/attach" || strings.HasPrefix(input, "/attach ") {
+			m.handleAttachCommand(strings.TrimSpace(strings.TrimPrefix(input, "/attach")))
+			m.input.SetPrompt("> ")
+			continue
+		}
+
+		// Images referenced as @path are attached along with queued ones
+		images, err := attach.ExtractImages(input)
+		if err != nil {
+			fmt.Fprintln(m.input, utils.ProgramStyle().Render("Error: "+err.Error()))
+			m.input.SetPrompt("> ")
+			continue
+		}
+		images = mergeImages(m.pendingImages, images)
+
 		// Clear the user input line after enter
-		moveCursorUp(1)
-		clearLine()
+		moveCursorUp(m.input, 1)
+		clearLine(m.input)
 
 		// Send message to core if chat service is ready
 		if m.appModel.ChatServiceReady {
 			eventBus := m.dispatcher.GetEventBus()
-			if err := eventBus.SendToCore(eventbus.SendMessageEvent{Message: input}); err != nil {
+			if err := eventBus.SendToCore(eventbus.SendMessageEvent{Message: input, Images: images}); err != nil {
 				if m.statusShown {
 					m.clearPreviousStatus()
 					m.statusShown = false
 				}
-				fmt.Printf("Error sending message: %s\n", err.Error())
-				fmt.Print("> ")
+				fmt.Fprintf(m.input, "Error sending message: %s\n", err.Error())
+				m.input.SetPrompt("> ")
+				continue
 			}
-			// Don't print prompt here - it will be printed when response comes back
+			m.pendingImages = nil
+			// Hide the prompt - it will be shown again when response comes back
+			m.input.SetPrompt("")
 		} else {
-			fmt.Println("Chat service not available")
-			fmt.Print("> ")
+			fmt.Fprintln(m.input, "Chat service not available")
+			m.input.SetPrompt("> ")
 		}
 	}
 }
@@ -0,0 +1,113 @@
+package attach
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// MaxImageBytes bounds the size of a single attached image
+const MaxImageBytes = 10 << 20
+
+// imageExtensions are the file types accepted as image attachments
+var imageExtensions = map[string]bool{
+	".png":  true,
+	".jpg":  true,
+	".jpeg": true,
+	".gif":  true,
+	".webp": true,
+}
+
+// Image is an image file attached to a user message
+type Image struct {
+	Path      string // Path as given by the user
+	MediaType string // e.g. "image/png"
+	Data      []byte
+}
+
+// IsImagePath reports whether path names a supported image file type
+func IsImagePath(path string) bool {
+	return imageExtensions[strings.ToLower(filepath.Ext(path))]
+}
+
+// LoadImage reads an image file. Relative paths are resolved against the
+// working directory and a leading "~/" against the home directory.
+func LoadImage(path string) (*Image, error) {
+	if !IsImagePath(path) {
+		return nil, fmt.Errorf("%s is not a supported image (png, jpg, gif, webp)", path)
+	}
+
+	resolved := path
+	if strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve home directory: %v", err)
+		}
+		resolved = filepath.Join(home, path[2:])
+	}
+
+	info, err := os.Stat(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("cannot attach %s: %v", path, err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("cannot attach %s: is a directory", path)
+	}
+	if info.Size() > MaxImageBytes {
+		return nil, fmt.Errorf("cannot attach %s: %d bytes exceeds the %d MiB limit", path, info.Size(), MaxImageBytes>>20)
+	}
+
+	data, err := os.ReadFile(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("cannot attach %s: %v", path, err)
+	}
+
+	// Trust the content over the extension
+	mediaType := http.DetectContentType(data)
+	switch mediaType {
+	case "image/png", "image/jpeg", "image/gif", "image/webp":
+	default:
+		return nil, fmt.Errorf("cannot attach %s: content is %s, not a supported image", path, mediaType)
+	}
+
+	return &Image{Path: path, MediaType: mediaType, Data: data}, nil
+}
+
+// DataURL returns the image encoded as a base64 data URL
+func (img *Image) DataURL() string {
+	return "data:" + img.MediaType + ";base64," + base64.StdEncoding.EncodeToString(img.Data)
+}
+
+// Placeholder is the text shown in place of the image in the conversation
+func (img *Image) Placeholder() string {
+	return fmt.Sprintf("[image: %s]", img.Path)
+}
+
+// ExtractImages loads the images referenced by @path tokens in a message.
+// Tokens naming other file types are left alone; the message text is
+// returned unchanged so the model sees how the user referred to each image.
+func ExtractImages(message string) ([]*Image, error) {
+	var images []*Image
+	seen := make(map[string]bool)
+	for _, field := range strings.Fields(message) {
+		if !strings.HasPrefix(field, "@") {
+			continue
+		}
+		// Allow trailing punctuation such as "look at @shot.png,"
+		path := strings.TrimRight(field[1:], ",.;:!?)\"'")
+		if path == "" || !IsImagePath(path) || seen[path] {
+			continue
+		}
+		seen[path] = true
+
+		img, err := LoadImage(path)
+		if err != nil {
+			return nil, err
+		}
+		images = append(images, img)
+	}
+	return images, nil
+}
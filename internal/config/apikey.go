@@ -0,0 +1,108 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+)
+
+// apiKeyCommandTimeout bounds helper commands, which may ask for a passphrase
+const apiKeyCommandTimeout = 2 * time.Minute
+
+// HasAPIKey reports whether the profile has a key or a place to get one from
+func (p Profile) HasAPIKey() bool {
+	return p.APIKey != "" || p.APIKeyEnv != "" || p.APIKeyFile != "" || p.APIKeyCommand != ""
+}
+
+// APIKeySource describes where the profile's key comes from, without
+// revealing it
+func (p Profile) APIKeySource() string {
+	switch {
+	case p.APIKey != "":
+		return "config file (plaintext)"
+	case p.APIKeyEnv != "":
+		return fmt.Sprintf("environment variable %s", p.APIKeyEnv)
+	case p.APIKeyFile != "":
+		return fmt.Sprintf("file %s", p.APIKeyFile)
+	case p.APIKeyCommand != "":
+		return fmt.Sprintf("command `%s`", p.APIKeyCommand)
+	}
+	return "not set"
+}
+
+// ResolveAPIKey reads the profile's key from the first configured source:
+// api_key, api_key_env, api_key_file, then api_key_command
+func (p Profile) ResolveAPIKey() (string, error) {
+	switch {
+	case p.APIKey != "":
+		return p.APIKey, nil
+	case p.APIKeyEnv != "":
+		key := strings.TrimSpace(os.Getenv(p.APIKeyEnv))
+		if key == "" {
+			return "", fmt.Errorf("API key environment variable %s is not set", p.APIKeyEnv)
+		}
+		return key, nil
+	case p.APIKeyFile != "":
+		data, err := os.ReadFile(expandHome(p.APIKeyFile))
+		if err != nil {
+			return "", fmt.Errorf("failed to read API key file: %w", err)
+		}
+		key := strings.TrimSpace(string(data))
+		if key == "" {
+			return "", fmt.Errorf("API key file %s is empty", p.APIKeyFile)
+		}
+		return key, nil
+	case p.APIKeyCommand != "":
+		return runKeyCommand(p.APIKeyCommand)
+	}
+	return "", fmt.Errorf("no API key configured")
+}
+
+// runKeyCommand runs a helper command through the shell and returns the
+// first line of its output, following the convention of password managers
+// such as pass
+func runKeyCommand(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyCommandTimeout)
+	defer cancel()
+
+	var cmd *exec.Cmd
+	if runtime.GOOS == "windows" {
+		cmd = exec.CommandContext(ctx, "cmd", "/C", command)
+	} else {
+		cmd = exec.CommandContext(ctx, "sh", "-c", command)
+	}
+	// The helper may prompt for a passphrase on the terminal
+	cmd.Stdin = os.Stdin
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("API key command failed: %v: %s", err, msg)
+		}
+		return "", fmt.Errorf("API key command failed: %v", err)
+	}
+	key, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	key = strings.TrimSpace(key)
+	if key == "" {
+		return "", fmt.Errorf("API key command printed nothing")
+	}
+	return key, nil
+}
+
+// expandHome replaces a leading ~/ with the user's home directory
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
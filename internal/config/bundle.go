@@ -0,0 +1,300 @@
+package config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// ProfileBundle is a set of profiles shared between users, as written by
+// profile export and read by profile import
+type ProfileBundle struct {
+	Profiles map[string]Profile `json:"profiles"`
+}
+
+// ExportProfiles bundles the named profiles with the profiles they extend,
+// so the bundle can be imported on its own. Secrets are removed: plaintext
+// keys, key files and key commands, which are personal, and header values,
+// which often carry tokens. api_key_env is kept since it only names a
+// variable. The returned notes describe what was removed.
+func (c *Config) ExportProfiles(names []string) (*ProfileBundle, []string, error) {
+	bundle := &ProfileBundle{Profiles: make(map[string]Profile)}
+	for _, name := range names {
+		chain, err := extendsChain(c.Profiles, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, member := range chain {
+			bundle.Profiles[member] = c.Profiles[member]
+		}
+	}
+
+	exported := make([]string, 0, len(bundle.Profiles))
+	for name := range bundle.Profiles {
+		exported = append(exported, name)
+	}
+	sort.Strings(exported)
+
+	var notes []string
+	for _, name := range exported {
+		profile := bundle.Profiles[name]
+		var removed []string
+		if profile.APIKey != "" {
+			removed = append(removed, "api_key")
+		}
+		if profile.APIKeyFile != "" {
+			removed = append(removed, "api_key_file")
+		}
+		if profile.APIKeyCommand != "" {
+			removed = append(removed, "api_key_command")
+		}
+		if len(profile.Headers) > 0 {
+			headers := make([]string, 0, len(profile.Headers))
+			for header := range profile.Headers {
+				headers = append(headers, http.CanonicalHeaderKey(header))
+			}
+			sort.Strings(headers)
+			removed = append(removed, "headers "+strings.Join(headers, ", "))
+		}
+		profile.APIKey, profile.APIKeyFile, profile.APIKeyCommand = "", "", ""
+		profile.Headers = nil
+		bundle.Profiles[name] = profile
+		if len(removed) > 0 {
+			notes = append(notes, fmt.Sprintf("%s: removed %s", name, strings.Join(removed, "; ")))
+		}
+	}
+	return bundle, notes, nil
+}
+
+// ParseProfileBundle decodes a profile bundle. Unknown fields are errors, so
+// a misspelt setting is not silently dropped.
+func ParseProfileBundle(data []byte) (*ProfileBundle, error) {
+	var raw struct {
+		Profiles map[string]json.RawMessage `json:"profiles"`
+	}
+	if err := decodeStrict(data, &raw); err != nil {
+		return nil, fmt.Errorf("invalid profile bundle: %w", err)
+	}
+	if len(raw.Profiles) == 0 {
+		return nil, fmt.Errorf("invalid profile bundle: no profiles")
+	}
+
+	names := make([]string, 0, len(raw.Profiles))
+	for name := range raw.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	bundle := &ProfileBundle{Profiles: make(map[string]Profile)}
+	for _, name := range names {
+		if strings.TrimSpace(name) == "" {
+			return nil, fmt.Errorf("invalid profile bundle: empty profile name")
+		}
+		var profile Profile
+		if err := decodeStrict(raw.Profiles[name], &profile); err != nil {
+			return nil, fmt.Errorf("profile '%s': %w", name, err)
+		}
+		bundle.Profiles[name] = profile
+	}
+	return bundle, nil
+}
+
+func decodeStrict(data []byte, v interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	return decoder.Decode(v)
+}
+
+// ErrKeyRedirect is returned by ImportProfiles when imported profiles would
+// send an API key to an endpoint it is not sent to today
+var ErrKeyRedirect = errors.New("imported profiles would send local API keys to new endpoints")
+
+// KeyRedirect is a profile that, after an import, would send an API key to
+// an endpoint no profile sends it to before the import
+type KeyRedirect struct {
+	Profile   string
+	KeySource string // As described by Profile.APIKeySource
+	Endpoint  string // Base URL, embeddings URL and header names
+}
+
+// ImportProfiles adds the bundle's profiles to the config and returns their
+// names, sorted. Existing profiles are only replaced when replace is set; a
+// replaced profile keeps its API key source when the imported one has none.
+// Nothing changes unless every imported profile resolves, without missing
+// bases or cycles, to valid model parameters, and the existing profiles
+// still resolve.
+//
+// Bundles may not carry api_key, api_key_file or api_key_command. A bundle
+// can still make a local key go elsewhere: through api_key_env, a base it
+// extends, or a replaced profile that keeps its key. Every profile that
+// would send a key to a new endpoint is returned, and unless trustEndpoints
+// is set the import is refused with ErrKeyRedirect.
+func (c *Config) ImportProfiles(bundle *ProfileBundle, replace, trustEndpoints bool) ([]string, []KeyRedirect, error) {
+	names := make([]string, 0, len(bundle.Profiles))
+	for name := range bundle.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		profile := bundle.Profiles[name]
+		var fields []string
+		if profile.APIKey != "" {
+			fields = append(fields, "api_key")
+		}
+		if profile.APIKeyFile != "" {
+			fields = append(fields, "api_key_file")
+		}
+		if profile.APIKeyCommand != "" {
+			fields = append(fields, "api_key_command")
+		}
+		if len(fields) > 0 {
+			return nil, nil, fmt.Errorf("profile '%s': bundles must not set %s; only api_key_env can name a key", name, strings.Join(fields, ", "))
+		}
+	}
+
+	merged := make(map[string]Profile, len(c.Profiles)+len(names))
+	for name, profile := range c.Profiles {
+		merged[name] = profile
+	}
+	for _, name := range names {
+		profile := bundle.Profiles[name]
+		if existing, exists := c.Profiles[name]; exists {
+			if !replace {
+				return nil, nil, fmt.Errorf("profile '%s' already exists (use --replace to overwrite it)", name)
+			}
+			if !profile.HasAPIKey() {
+				profile.APIKey, profile.APIKeyEnv = existing.APIKey, existing.APIKeyEnv
+				profile.APIKeyFile, profile.APIKeyCommand = existing.APIKeyFile, existing.APIKeyCommand
+			}
+		}
+		merged[name] = profile
+	}
+
+	// Replacing a base must not break the profiles that already extend it,
+	// nor send their keys somewhere new
+	known := c.keyUses()
+	var redirects []KeyRedirect
+	all := make([]string, 0, len(merged))
+	for name := range merged {
+		all = append(all, name)
+	}
+	sort.Strings(all)
+	for _, name := range all {
+		_, imported := bundle.Profiles[name]
+		if !imported {
+			if _, err := extendsChain(c.Profiles, name); err != nil {
+				continue // Already broken before the import
+			}
+		}
+		resolved, err := resolveProfile(merged, name)
+		if err != nil {
+			return nil, nil, err
+		}
+		if use, ok := keyUseOf(resolved); ok && !known[use] {
+			redirects = append(redirects, KeyRedirect{
+				Profile:   name,
+				KeySource: resolved.APIKeySource(),
+				Endpoint:  describeEndpoint(resolved),
+			})
+		}
+		if !imported {
+			continue
+		}
+		if err := resolved.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("profile '%s': %w", name, err)
+		}
+	}
+	if len(redirects) > 0 && !trustEndpoints {
+		return nil, redirects, ErrKeyRedirect
+	}
+
+	c.Profiles = merged
+	return names, redirects, nil
+}
+
+// keyUse is an API key source together with everything it is sent to
+type keyUse struct {
+	source     string
+	baseURL    string
+	embeddings string
+	headers    string
+}
+
+// keyUseOf returns where the resolved profile sends its key, if it has one
+func keyUseOf(profile Profile) (keyUse, bool) {
+	var source string
+	switch {
+	case profile.APIKey != "":
+		source = "api_key:" + profile.APIKey
+	case profile.APIKeyEnv != "":
+		source = "api_key_env:" + profile.APIKeyEnv
+	case profile.APIKeyFile != "":
+		source = "api_key_file:" + profile.APIKeyFile
+	case profile.APIKeyCommand != "":
+		source = "api_key_command:" + profile.APIKeyCommand
+	default:
+		return keyUse{}, false
+	}
+
+	use := keyUse{source: source, baseURL: normalizeURL(profile.BaseURL)}
+	if profile.EmbeddingBaseURL != "" && profile.EmbeddingModel != "local" {
+		use.embeddings = normalizeURL(profile.EmbeddingBaseURL)
+	}
+	headers := make([]string, 0, len(profile.Headers))
+	for name, value := range profile.Headers {
+		headers = append(headers, http.CanonicalHeaderKey(name)+": "+value)
+	}
+	sort.Strings(headers)
+	use.headers = strings.Join(headers, "\n")
+	return use, true
+}
+
+// keyUses returns where the profiles that resolve send their keys
+func (c *Config) keyUses() map[keyUse]bool {
+	uses := make(map[keyUse]bool)
+	for name := range c.Profiles {
+		if resolved, err := resolveProfile(c.Profiles, name); err == nil {
+			if use, ok := keyUseOf(resolved); ok {
+				uses[use] = true
+			}
+		}
+	}
+	return uses
+}
+
+// normalizeURL makes equivalent base URLs compare equal
+func normalizeURL(raw string) string {
+	raw = strings.TrimSuffix(strings.TrimSpace(raw), "/")
+	if u, err := url.Parse(raw); err == nil && u.Host != "" {
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		return u.String()
+	}
+	return raw
+}
+
+// describeEndpoint names where a profile sends its key, for display
+func describeEndpoint(profile Profile) string {
+	endpoint := "the default OpenAI API"
+	if profile.BaseURL != "" {
+		endpoint = profile.BaseURL
+	}
+	if profile.EmbeddingBaseURL != "" && profile.EmbeddingModel != "local" {
+		endpoint += ", embeddings " + profile.EmbeddingBaseURL
+	}
+	if len(profile.Headers) > 0 {
+		names := make([]string, 0, len(profile.Headers))
+		for name := range profile.Headers {
+			names = append(names, http.CanonicalHeaderKey(name))
+		}
+		sort.Strings(names)
+		endpoint += " with headers " + strings.Join(names, ", ")
+	}
+	return endpoint
+}
@@ -5,21 +5,147 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 )
 
 type Profile struct {
 	APIKey  string `json:"api_key"`
 	BaseURL string `json:"base_url,omitempty"`
 	Model   string `json:"model"`
+	// Extends names a profile that supplies the fields this one leaves unset
+	Extends string `json:"extends,omitempty"`
+	// Alternatives to storing the key in plaintext, resolved when first needed
+	APIKeyEnv     string `json:"api_key_env,omitempty"`     // Environment variable holding the key
+	APIKeyFile    string `json:"api_key_file,omitempty"`    // File holding the key
+	APIKeyCommand string `json:"api_key_command,omitempty"` // Command printing the key, e.g. "pass show openai"
+	// Embeddings endpoint used by semantic search. "local" selects the offline hash embedder.
+	EmbeddingModel   string `json:"embedding_model,omitempty"`
+	EmbeddingBaseURL string `json:"embedding_base_url,omitempty"`
+	// TextOnly marks a model that does not accept image input; false
+	// overrides a text-only base profile
+	TextOnly *bool `json:"text_only,omitempty"`
+	// Model parameters sent with each request; unset values use the provider's defaults
+	Temperature       *float32          `json:"temperature,omitempty"`
+	TopP              *float32          `json:"top_p,omitempty"`
+	MaxTokens         *int              `json:"max_tokens,omitempty"` // 0 removes a base profile's limit
+	Seed              *int              `json:"seed,omitempty"`
+	Stop              []string          `json:"stop,omitempty"`
+	ReasoningEffort   string            `json:"reasoning_effort,omitempty"`    // minimal, low, medium or high
+	ParallelToolCalls *bool             `json:"parallel_tool_calls,omitempty"` // Let the model call several tools at once
+	ToolChoice        string            `json:"tool_choice,omitempty"`         // auto, none, required or a tool name
+	Headers           map[string]string `json:"headers,omitempty"`             // Extra HTTP headers for the API
+	Organization      string            `json:"organization,omitempty"`        // OpenAI organization ID
+	Project           string            `json:"project,omitempty"`             // OpenAI project ID
+}
+
+// RepoMapConfig controls the repository map attached to the system prompt
+type RepoMapConfig struct {
+	InSystemPrompt bool `json:"in_system_prompt"`
+	TokenBudget    int  `json:"token_budget,omitempty"`
+}
+
+// LSPServerConfig overrides or adds a language server, keyed by language name
+type LSPServerConfig struct {
+	Command    []string `json:"command"`
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// ToolOutputConfig limits how much tool output is returned to the model.
+// Larger output is saved to a spillover file that the model can page through.
+type ToolOutputConfig struct {
+	MaxBytes  int `json:"max_bytes,omitempty"`  // Output up to this size is returned whole
+	HeadBytes int `json:"head_bytes,omitempty"` // Kept from the start of spilled output
+	TailBytes int `json:"tail_bytes,omitempty"` // Kept from the end of spilled output
+}
+
+// EgressConfig restricts the hosts network tools may contact. Hosts are
+// matched with globs such as "*.example.com".
+type EgressConfig struct {
+	AllowHosts        []string `json:"allow_hosts,omitempty"`         // Only these hosts, when set
+	DenyHosts         []string `json:"deny_hosts,omitempty"`          // Never these hosts
+	AllowPrivateHosts []string `json:"allow_private_hosts,omitempty"` // May resolve to private, loopback or link-local addresses
+	MaxResponseBytes  int64    `json:"max_response_bytes,omitempty"`
+	MaxRedirects      *int     `json:"max_redirects,omitempty"` // 0 disables redirects
+}
+
+// CredentialConfig is a secret that http_request adds to requests for the
+// matching hosts. The value comes from an environment variable or a file.
+type CredentialConfig struct {
+	Type      string   `json:"type"`                 // bearer, basic, header or query
+	Hosts     []string `json:"hosts"`                // Host globs, e.g. "api.github.com"
+	Header    string   `json:"header,omitempty"`     // Header name for the header type
+	Param     string   `json:"param,omitempty"`      // Query parameter for the query type
+	Username  string   `json:"username,omitempty"`   // User name for the basic type
+	ValueEnv  string   `json:"value_env,omitempty"`  // Environment variable holding the secret
+	ValueFile string   `json:"value_file,omitempty"` // File holding the secret
+	AllowHTTP bool     `json:"allow_http,omitempty"` // Also send over plain http
+}
+
+// RedactionConfig controls how secrets are removed from tool results before
+// they are sent to the model
+type RedactionConfig struct {
+	Disabled       bool               `json:"disabled,omitempty"`
+	DisableEntropy bool               `json:"disable_entropy,omitempty"` // Keep random-looking strings
+	Patterns       []RedactionPattern `json:"patterns,omitempty"`        // Extra secret formats
+	Allow          []string           `json:"allow,omitempty"`           // Expressions for values never redacted
+}
+
+// RedactionPattern is a named regular expression; with a capture group,
+// only the group is redacted
+type RedactionPattern struct {
+	Name  string `json:"name"`
+	Regex string `json:"regex"`
 }
 
 type Config struct {
-	Profiles       map[string]Profile `json:"profiles"`
-	ActiveProfile  string             `json:"active_profile"`
+	Profiles       map[string]Profile          `json:"profiles"`
+	ActiveProfile  string                      `json:"active_profile"`
+	Model          string                      `json:"model,omitempty"`          // Overrides the active profile's model
+	BaseURL        string                      `json:"base_url,omitempty"`       // Overrides the active profile's base URL
+	EnabledTools   []string                    `json:"enabled_tools,omitempty"`  // Only these tools, when set
+	DisabledTools  []string                    `json:"disabled_tools,omitempty"` // Never these tools
+	SystemPrompt   string                      `json:"system_prompt,omitempty"`  // Instructions added to the system prompt
+	RepoMap        *RepoMapConfig              `json:"repo_map,omitempty"`
+	LSPServers     map[string]LSPServerConfig  `json:"lsp_servers,omitempty"`
+	ToolOutput     *ToolOutputConfig           `json:"tool_output,omitempty"`
+	Egress         *EgressConfig               `json:"egress,omitempty"`
+	Credentials    map[string]CredentialConfig `json:"credentials,omitempty"`
+	Redaction      *RedactionConfig            `json:"redaction,omitempty"`
 	currentProfile *Profile
+
+	globalPath  string
+	projectPath string          // Project config merged over the global one
+	projectKeys map[string]bool // Keys set by the project config
+	// Where overridden keys came from, e.g. "--model flag"; set when
+	// overrides were applied
+	overrideSources map[string]string
+	warnings        []string
+
+	keyMu  sync.Mutex
+	keyRes *resolvedKey // Cached key of the current profile
 }
 
+// resolvedKey is the outcome of resolving an API key
+type resolvedKey struct {
+	key string
+	err error
+}
+
+// LoadConfig loads the global config with the nearest project config
+// (.roricode/config.json in the working directory or a parent) merged over
+// it. The result cannot be saved; use LoadGlobalConfig to change settings.
 func LoadConfig() (*Config, error) {
+	return loadConfig(true)
+}
+
+// LoadGlobalConfig loads only the global config, for commands that save it.
+// A broken extends chain is only reported in Warnings, so the profiles can
+// still be listed and repaired; the active profile is then used unresolved.
+func LoadGlobalConfig() (*Config, error) {
+	return loadConfig(false)
+}
+
+func loadConfig(withProject bool) (*Config, error) {
 	configPath, err := getConfigPath()
 	if err != nil {
 		return nil, fmt.Errorf("failed to get config path: %w", err)
@@ -35,9 +161,26 @@ func LoadConfig() (*Config, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to load config: %w", err)
 	}
+	config.globalPath = configPath
+	config.warnings = append(config.warnings, checkExtends(config.Profiles)...)
+
+	// Project settings take precedence over global ones, and overrides for
+	// this run over both
+	if withProject {
+		if cwd, err := os.Getwd(); err == nil {
+			if projectPath := findProjectConfig(cwd, configPath); projectPath != "" {
+				if err := config.applyProject(projectPath); err != nil {
+					return nil, fmt.Errorf("failed to load project config: %w", err)
+				}
+			}
+		}
+		if err := config.applyOverrides(); err != nil {
+			return nil, err
+		}
+	}
 
 	// Validate and set current profile
-	if err := config.setCurrentProfile(); err != nil {
+	if err := config.setCurrentProfile(withProject); err != nil {
 		return nil, fmt.Errorf("failed to set current profile: %w", err)
 	}
 
@@ -45,14 +188,29 @@ func LoadConfig() (*Config, error) {
 }
 
 func (c *Config) IsValid() bool {
-	return c.currentProfile != nil && c.currentProfile.APIKey != ""
+	return c.currentProfile != nil && c.currentProfile.HasAPIKey()
 }
 
+// GetAPIKey returns the current profile's API key, or "" when it cannot be
+// resolved
 func (c *Config) GetAPIKey() string {
+	key, _ := c.ResolveAPIKey()
+	return key
+}
+
+// ResolveAPIKey returns the current profile's API key. Keys from the
+// environment, files or commands are resolved on first use and cached.
+func (c *Config) ResolveAPIKey() (string, error) {
 	if c.currentProfile == nil {
-		return ""
+		return "", fmt.Errorf("no active profile")
 	}
-	return c.currentProfile.APIKey
+	c.keyMu.Lock()
+	defer c.keyMu.Unlock()
+	if c.keyRes == nil {
+		key, err := c.currentProfile.ResolveAPIKey()
+		c.keyRes = &resolvedKey{key: key, err: err}
+	}
+	return c.keyRes.key, c.keyRes.err
 }
 
 func (c *Config) GetModel() string {
@@ -69,7 +227,74 @@ func (c *Config) GetBaseURL() string {
 	return c.currentProfile.BaseURL
 }
 
+// IsTextOnly reports whether the active profile's model rejects image input
+func (c *Config) IsTextOnly() bool {
+	return c.currentProfile != nil && c.currentProfile.IsTextOnly()
+}
+
+// GetEmbeddingModel returns the embedding model used for semantic search
+func (c *Config) GetEmbeddingModel() string {
+	if c.currentProfile == nil || c.currentProfile.EmbeddingModel == "" {
+		return "text-embedding-3-small"
+	}
+	return c.currentProfile.EmbeddingModel
+}
+
+// GetEmbeddingBaseURL returns the embeddings endpoint, defaulting to the chat base URL
+func (c *Config) GetEmbeddingBaseURL() string {
+	if c.currentProfile == nil {
+		return ""
+	}
+	if c.currentProfile.EmbeddingBaseURL != "" {
+		return c.currentProfile.EmbeddingBaseURL
+	}
+	return c.currentProfile.BaseURL
+}
+
+// RepoMapInSystemPrompt reports whether the repository map should be attached to the system prompt
+func (c *Config) RepoMapInSystemPrompt() bool {
+	return c.RepoMap != nil && c.RepoMap.InSystemPrompt
+}
+
+// GetRepoMapTokenBudget returns the token budget for the repository map (0 means default)
+func (c *Config) GetRepoMapTokenBudget() int {
+	if c.RepoMap == nil {
+		return 0
+	}
+	return c.RepoMap.TokenBudget
+}
+
+// GetToolOutputLimits returns the configured tool output limits; zero values
+// mean the defaults
+func (c *Config) GetToolOutputLimits() ToolOutputConfig {
+	if c.ToolOutput == nil {
+		return ToolOutputConfig{}
+	}
+	return *c.ToolOutput
+}
+
+// GetRedactionConfig returns the configured redaction rules
+func (c *Config) GetRedactionConfig() RedactionConfig {
+	if c.Redaction == nil {
+		return RedactionConfig{}
+	}
+	return *c.Redaction
+}
+
+// GetEgressConfig returns the configured egress rules; zero values mean the
+// defaults
+func (c *Config) GetEgressConfig() EgressConfig {
+	if c.Egress == nil {
+		return EgressConfig{}
+	}
+	return *c.Egress
+}
+
 func getConfigPath() (string, error) {
+	if path := configPathOverride(); path != "" {
+		return path, nil
+	}
+
 	var configDir string
 	
 	// Use RORICODE_HOME if set, otherwise use user's home directory
@@ -141,6 +366,12 @@ func saveConfig(config *Config, configPath string) error {
 }
 
 func (c *Config) Save() error {
+	if c.projectPath != "" {
+		return fmt.Errorf("config includes project settings from %s and cannot be saved; load it with LoadGlobalConfig", c.projectPath)
+	}
+	if c.overrideSources != nil {
+		return fmt.Errorf("config includes overrides for this run and cannot be saved; load it with LoadGlobalConfig")
+	}
 	configPath, err := getConfigPath()
 	if err != nil {
 		return fmt.Errorf("failed to get config path: %w", err)
@@ -149,17 +380,19 @@ func (c *Config) Save() error {
 	return saveConfig(c, configPath)
 }
 
-func (c *Config) setCurrentProfile() error {
+// setCurrentProfile resolves the active profile. When the extends chain is
+// broken it fails if requireResolved is set, and otherwise uses the profile
+// as written.
+func (c *Config) setCurrentProfile(requireResolved bool) error {
 	if c.Profiles == nil {
 		return fmt.Errorf("no profiles defined")
 	}
 
-	profile, exists := c.Profiles[c.ActiveProfile]
+	_, exists := c.Profiles[c.ActiveProfile]
 	if !exists {
 		// If active profile doesn't exist, try to use the first available profile
-		for name, p := range c.Profiles {
+		for name := range c.Profiles {
 			c.ActiveProfile = name
-			profile = p
 			exists = true
 			break
 		}
@@ -169,6 +402,23 @@ func (c *Config) setCurrentProfile() error {
 		return fmt.Errorf("no valid profiles found")
 	}
 
+	profile, err := c.ResolveProfile(c.ActiveProfile)
+	if err != nil {
+		if requireResolved {
+			return err
+		}
+		profile = c.Profiles[c.ActiveProfile] // checkExtends reported the problem
+	}
+
+	if c.Model != "" {
+		profile.Model = c.Model
+	}
+	if c.BaseURL != "" {
+		profile.BaseURL = c.BaseURL
+	}
 	c.currentProfile = &profile
+	c.keyMu.Lock()
+	c.keyRes = nil
+	c.keyMu.Unlock()
 	return nil
 }
\ No newline at end of file
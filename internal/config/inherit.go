@@ -0,0 +1,98 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// ResolveProfile returns the named profile with the fields it leaves unset
+// taken from the profile it extends, and so on up the chain
+func (c *Config) ResolveProfile(name string) (Profile, error) {
+	return resolveProfile(c.Profiles, name)
+}
+
+func resolveProfile(profiles map[string]Profile, name string) (Profile, error) {
+	chain, err := extendsChain(profiles, name)
+	if err != nil {
+		return Profile{}, err
+	}
+	resolved := profiles[chain[len(chain)-1]]
+	for i := len(chain) - 2; i >= 0; i-- {
+		resolved = inherit(profiles[chain[i]], resolved)
+	}
+	return resolved, nil
+}
+
+// extendsChain returns name followed by the profiles it extends, nearest
+// first. A missing base or a cycle is an error.
+func extendsChain(profiles map[string]Profile, name string) ([]string, error) {
+	if _, exists := profiles[name]; !exists {
+		return nil, fmt.Errorf("profile '%s' does not exist", name)
+	}
+	var chain []string
+	seen := make(map[string]bool)
+	for current := name; current != ""; current = profiles[current].Extends {
+		if seen[current] {
+			return nil, fmt.Errorf("profile '%s': extends cycle %s", name, strings.Join(append(chain, current), " -> "))
+		}
+		if _, exists := profiles[current]; !exists {
+			return nil, fmt.Errorf("profile '%s' extends '%s', which does not exist", chain[len(chain)-1], current)
+		}
+		seen[current] = true
+		chain = append(chain, current)
+	}
+	return chain, nil
+}
+
+// inherit fills the fields child leaves unset from base. The API key
+// sources are taken together: a child with any source of its own inherits
+// none, so a base's plaintext key never takes precedence over it.
+//
+// A field is unset when it holds its zero value. The numeric and boolean
+// settings are pointers, so a child can set them to 0 or false to override
+// its base; strings, stop sequences and headers cannot be cleared, only
+// replaced.
+func inherit(child, base Profile) Profile {
+	if child.HasAPIKey() {
+		base.APIKey, base.APIKeyEnv, base.APIKeyFile, base.APIKeyCommand = "", "", "", ""
+	}
+	c := reflect.ValueOf(&child).Elem()
+	b := reflect.ValueOf(base)
+	for i := 0; i < c.NumField(); i++ {
+		if c.Field(i).IsZero() {
+			c.Field(i).Set(b.Field(i))
+		}
+	}
+	return child
+}
+
+// checkExtends reports the profiles whose extends chain is broken, sorted by
+// name
+func checkExtends(profiles map[string]Profile) []string {
+	names := make([]string, 0, len(profiles))
+	for name := range profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var problems []string
+	for _, name := range names {
+		if _, err := extendsChain(profiles, name); err != nil {
+			problems = append(problems, err.Error())
+		}
+	}
+	return problems
+}
+
+// Dependents returns the profiles that extend name directly, sorted
+func (c *Config) Dependents(name string) []string {
+	var names []string
+	for other, profile := range c.Profiles {
+		if profile.Extends == name {
+			names = append(names, other)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
@@ -0,0 +1,75 @@
+package config
+
+import (
+	"fmt"
+	"sync"
+)
+
+// Overrides change the loaded config for a single run, e.g. from command
+// line flags or RORICODE_* environment variables. They are applied by
+// LoadConfig and never saved.
+var (
+	overridesMu sync.Mutex
+	overrides   = make(map[string]override) // Keyed by config key
+	configPath  string                      // Global config file to use instead of the default
+)
+
+type override struct {
+	value  string
+	source string // Where the value came from, e.g. "--model flag"
+}
+
+// overrideKeys are the settings that can be overridden
+var overrideKeys = map[string]bool{"active_profile": true, "model": true, "base_url": true}
+
+// SetOverride overrides the config key (active_profile, model or base_url)
+// with value for this run; source names where the value came from
+func SetOverride(key, value, source string) error {
+	if !overrideKeys[key] {
+		return fmt.Errorf("setting %q cannot be overridden", key)
+	}
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	overrides[key] = override{value: value, source: source}
+	return nil
+}
+
+// SetConfigPath replaces the global config file for this run
+func SetConfigPath(path string) {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	configPath = expandHome(path)
+}
+
+// configPathOverride returns the config file set with SetConfigPath, or ""
+func configPathOverride() string {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	return configPath
+}
+
+// applyOverrides applies the overrides on top of the loaded files
+func (c *Config) applyOverrides() error {
+	overridesMu.Lock()
+	defer overridesMu.Unlock()
+	if len(overrides) == 0 {
+		return nil
+	}
+
+	c.overrideSources = make(map[string]string)
+	for key, o := range overrides {
+		switch key {
+		case "active_profile":
+			if _, exists := c.Profiles[o.value]; !exists {
+				return fmt.Errorf("profile '%s' (from %s) does not exist", o.value, o.source)
+			}
+			c.ActiveProfile = o.value
+		case "model":
+			c.Model = o.value
+		case "base_url":
+			c.BaseURL = o.value
+		}
+		c.overrideSources[key] = o.source
+	}
+	return nil
+}
@@ -0,0 +1,142 @@
+package config
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// ReasoningEfforts are the accepted reasoning_effort values
+var ReasoningEfforts = []string{"minimal", "low", "medium", "high"}
+
+// toolNameRe matches a tool name given as tool_choice
+var toolNameRe = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// headerNameRe matches an HTTP header name
+var headerNameRe = regexp.MustCompile("^[!#$%&'*+.^_`|~0-9A-Za-z-]+$")
+
+// Validate checks the profile's model parameters
+func (p Profile) Validate() error {
+	var problems []string
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		problems = append(problems, "temperature must be between 0 and 2")
+	}
+	if p.TopP != nil && (*p.TopP < 0 || *p.TopP > 1) {
+		problems = append(problems, "top_p must be between 0 and 1")
+	}
+	if p.MaxTokens != nil && *p.MaxTokens < 0 {
+		problems = append(problems, "max_tokens must not be negative")
+	}
+	if len(p.Stop) > 4 {
+		problems = append(problems, "stop accepts at most 4 sequences")
+	}
+	for _, stop := range p.Stop {
+		if stop == "" {
+			problems = append(problems, "stop sequences must not be empty")
+			break
+		}
+	}
+	if p.ReasoningEffort != "" && !contains(ReasoningEfforts, p.ReasoningEffort) {
+		problems = append(problems, fmt.Sprintf("reasoning_effort must be one of %s", strings.Join(ReasoningEfforts, ", ")))
+	}
+	if err := ValidateToolChoice(p.ToolChoice); err != nil {
+		problems = append(problems, err.Error())
+	}
+	for name, value := range p.Headers {
+		if !headerNameRe.MatchString(name) {
+			problems = append(problems, fmt.Sprintf("invalid header name %q", name))
+		} else if strings.ContainsAny(value, "\r\n") {
+			problems = append(problems, fmt.Sprintf("header %s must not contain line breaks", name))
+		}
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("invalid model parameters: %s", strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// ValidateToolChoice checks a tool_choice value: auto, none, required or the
+// name of a tool
+func ValidateToolChoice(choice string) error {
+	switch choice {
+	case "", "auto", "none", "required":
+		return nil
+	}
+	if !toolNameRe.MatchString(choice) {
+		return fmt.Errorf("tool_choice must be auto, none, required or a tool name")
+	}
+	return nil
+}
+
+// ParameterLines describes the model parameters that are set, for display.
+// Header values are hidden since they often carry tokens.
+func (p Profile) ParameterLines() []string {
+	var lines []string
+	if p.Temperature != nil {
+		lines = append(lines, fmt.Sprintf("Temperature: %g", *p.Temperature))
+	}
+	if p.TopP != nil {
+		lines = append(lines, fmt.Sprintf("Top P: %g", *p.TopP))
+	}
+	if p.MaxTokens != nil {
+		if *p.MaxTokens == 0 {
+			lines = append(lines, "Max Tokens: no limit")
+		} else {
+			lines = append(lines, fmt.Sprintf("Max Tokens: %d", *p.MaxTokens))
+		}
+	}
+	if p.Seed != nil {
+		lines = append(lines, fmt.Sprintf("Seed: %d", *p.Seed))
+	}
+	if len(p.Stop) > 0 {
+		lines = append(lines, fmt.Sprintf("Stop: %q", p.Stop))
+	}
+	if p.ReasoningEffort != "" {
+		lines = append(lines, fmt.Sprintf("Reasoning Effort: %s", p.ReasoningEffort))
+	}
+	if p.ParallelToolCalls != nil {
+		lines = append(lines, fmt.Sprintf("Parallel Tool Calls: %t", *p.ParallelToolCalls))
+	}
+	if p.ToolChoice != "" {
+		lines = append(lines, fmt.Sprintf("Tool Choice: %s", p.ToolChoice))
+	}
+	if p.Organization != "" {
+		lines = append(lines, fmt.Sprintf("Organization: %s", p.Organization))
+	}
+	if p.Project != "" {
+		lines = append(lines, fmt.Sprintf("Project: %s", p.Project))
+	}
+	if len(p.Headers) > 0 {
+		names := make([]string, 0, len(p.Headers))
+		for name := range p.Headers {
+			names = append(names, http.CanonicalHeaderKey(name))
+		}
+		sort.Strings(names)
+		lines = append(lines, fmt.Sprintf("Headers: %s (values hidden)", strings.Join(names, ", ")))
+	}
+	return lines
+}
+
+// IsTextOnly reports whether the profile's model rejects image input
+func (p Profile) IsTextOnly() bool {
+	return p.TextOnly != nil && *p.TextOnly
+}
+
+// CurrentProfile returns the active profile with config overrides applied
+func (c *Config) CurrentProfile() Profile {
+	if c.currentProfile == nil {
+		return Profile{}
+	}
+	return *c.currentProfile
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
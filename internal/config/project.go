@@ -0,0 +1,355 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// projectConfigFile is the project config, looked up from the working
+// directory towards the filesystem root
+var projectConfigFile = filepath.Join(".roricode", "config.json")
+
+// projectForbiddenKeys may only be set in the global config: they hold API
+// keys or run commands, which a cloned repository must not control
+var projectForbiddenKeys = map[string]string{
+	"profiles":    "profiles hold API keys",
+	"credentials": "credentials hold secrets",
+	"lsp_servers": "language servers run commands",
+	"base_url":    "the API key is sent to it",
+}
+
+// projectTightenOnly lists the sections a project may only tighten, with the
+// keys it may set: they protect the user from what a repository's code and
+// pages can do
+var projectTightenOnly = map[string]map[string]bool{
+	"egress":    {"deny_hosts": true, "allow_hosts": true},
+	"redaction": {"patterns": true},
+}
+
+// Setting is one effective config value and where it came from
+type Setting struct {
+	Path   string // Dotted key, e.g. "egress.allow_hosts"
+	Value  interface{}
+	Source string // "global", "project" or the source of an override, e.g. "--model flag"
+}
+
+// findProjectConfig returns the nearest project config at or above dir,
+// skipping the global config file
+func findProjectConfig(dir, globalPath string) string {
+	for {
+		candidate := filepath.Join(dir, projectConfigFile)
+		if !sameFile(candidate, globalPath) {
+			if info, err := os.Stat(candidate); err == nil && !info.IsDir() {
+				return candidate
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+func sameFile(a, b string) bool {
+	infoA, errA := os.Stat(a)
+	infoB, errB := os.Stat(b)
+	if errA != nil || errB != nil {
+		return filepath.Clean(a) == filepath.Clean(b)
+	}
+	return os.SameFile(infoA, infoB)
+}
+
+// applyProject merges the project config over the loaded global config.
+// Objects are merged key by key; other values, including lists, replace the
+// global value.
+func (c *Config) applyProject(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	var layer map[string]interface{}
+	if err := json.Unmarshal(data, &layer); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	keys := make([]string, 0, len(layer))
+	for key := range layer {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	known := configKeys()
+	for _, key := range keys {
+		if reason, forbidden := projectForbiddenKeys[key]; forbidden {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s: %q is ignored in project config (%s); set it in the global config", path, key, reason))
+			delete(layer, key)
+		} else if !known[key] {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s: unknown setting %q", path, key))
+			delete(layer, key)
+		}
+	}
+	if name, ok := layer["active_profile"].(string); ok {
+		if _, exists := c.Profiles[name]; !exists {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s: active_profile %q does not exist", path, name))
+			delete(layer, "active_profile")
+		}
+	}
+
+	tightened, err := c.tightenFromProject(path, layer)
+	if err != nil {
+		return err
+	}
+
+	// Decoding over the loaded config merges objects field by field
+	merged, err := json.Marshal(layer)
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(merged, c); err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	c.projectPath = path
+	c.projectKeys = make(map[string]bool)
+	flatten("", layer, func(key string, _ interface{}) {
+		c.projectKeys[key] = true
+	})
+	for _, key := range tightened {
+		c.projectKeys[key] = true
+	}
+	return nil
+}
+
+// tightenFromProject applies the project's tool lists, egress and redaction
+// settings and removes them from layer. A project may add deny_hosts and
+// redaction patterns, and set allow_hosts when the global config has none;
+// anything that could loosen the global settings is ignored with a warning.
+// It returns the keys applied.
+func (c *Config) tightenFromProject(path string, layer map[string]interface{}) ([]string, error) {
+	applied, err := c.tightenToolsFromProject(path, layer)
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range []string{"egress", "redaction"} {
+		raw, ok := layer[section]
+		if !ok {
+			continue
+		}
+		delete(layer, section)
+
+		fields, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("%s: %s must be an object", path, section)
+		}
+		data, err := json.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		var egress EgressConfig
+		var redaction RedactionConfig
+		if section == "egress" {
+			err = json.Unmarshal(data, &egress)
+		} else {
+			err = json.Unmarshal(data, &redaction)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, section, err)
+		}
+
+		keys := make([]string, 0, len(fields))
+		for key := range fields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			name := section + "." + key
+			if !projectTightenOnly[section][key] {
+				c.warnings = append(c.warnings, fmt.Sprintf("%s: %q is ignored in project config (a project may only tighten %s); set it in the global config", path, name, section))
+				continue
+			}
+			if section == "egress" && c.Egress == nil {
+				c.Egress = &EgressConfig{}
+			}
+			if section == "redaction" && c.Redaction == nil {
+				c.Redaction = &RedactionConfig{}
+			}
+			switch name {
+			case "egress.deny_hosts":
+				for _, host := range egress.DenyHosts {
+					if !contains(c.Egress.DenyHosts, host) {
+						c.Egress.DenyHosts = append(c.Egress.DenyHosts, host)
+					}
+				}
+			case "egress.allow_hosts":
+				if len(c.Egress.AllowHosts) > 0 {
+					c.warnings = append(c.warnings, fmt.Sprintf("%s: %q is ignored in project config (the global config already limits the hosts)", path, name))
+					continue
+				}
+				c.Egress.AllowHosts = egress.AllowHosts
+			case "redaction.patterns":
+				c.Redaction.Patterns = append(c.Redaction.Patterns, redaction.Patterns...)
+			}
+			applied = append(applied, name)
+		}
+	}
+	return applied, nil
+}
+
+// tightenToolsFromProject applies the project's enabled_tools and
+// disabled_tools and removes them from layer. Disabled tools are added to the
+// global ones; enabled tools are kept only when the global config also
+// enables them, so a project can never offer a tool the user turned off.
+func (c *Config) tightenToolsFromProject(path string, layer map[string]interface{}) ([]string, error) {
+	var applied []string
+	for _, key := range []string{"disabled_tools", "enabled_tools"} {
+		raw, ok := layer[key]
+		if !ok {
+			continue
+		}
+		delete(layer, key)
+
+		data, err := json.Marshal(raw)
+		if err != nil {
+			return nil, err
+		}
+		var tools []string
+		if err := json.Unmarshal(data, &tools); err != nil {
+			return nil, fmt.Errorf("%s: %s: %w", path, key, err)
+		}
+
+		if key == "disabled_tools" {
+			for _, tool := range tools {
+				if !contains(c.DisabledTools, tool) {
+					c.DisabledTools = append(c.DisabledTools, tool)
+				}
+			}
+			applied = append(applied, key)
+			continue
+		}
+
+		// An empty list enables every tool, which would loosen the global one
+		if len(tools) == 0 {
+			if len(c.EnabledTools) > 0 {
+				c.warnings = append(c.warnings, fmt.Sprintf("%s: an empty %q is ignored in project config (a project may only tighten the tools); set it in the global config", path, key))
+			}
+			continue
+		}
+		if len(c.EnabledTools) == 0 {
+			c.EnabledTools = tools
+			applied = append(applied, key)
+			continue
+		}
+		var kept, dropped []string
+		for _, tool := range tools {
+			if contains(c.EnabledTools, tool) {
+				kept = append(kept, tool)
+			} else {
+				dropped = append(dropped, tool)
+			}
+		}
+		if len(dropped) > 0 {
+			c.warnings = append(c.warnings, fmt.Sprintf("%s: %q lists %s, which the global config does not enable; they are ignored", path, key, strings.Join(dropped, ", ")))
+		}
+		if len(kept) == 0 {
+			// An empty list would enable every tool; disable the global ones instead
+			for _, tool := range c.EnabledTools {
+				if !contains(c.DisabledTools, tool) {
+					c.DisabledTools = append(c.DisabledTools, tool)
+				}
+			}
+		} else {
+			c.EnabledTools = kept
+		}
+		applied = append(applied, key)
+	}
+	return applied, nil
+}
+
+// configKeys returns the JSON keys of Config
+func configKeys() map[string]bool {
+	keys := make(map[string]bool)
+	t := reflect.TypeOf(Config{})
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		if name != "" && name != "-" {
+			keys[name] = true
+		}
+	}
+	return keys
+}
+
+// flatten calls fn for each leaf of a decoded JSON object. Lists are leaves.
+func flatten(prefix string, value interface{}, fn func(key string, value interface{})) {
+	object, ok := value.(map[string]interface{})
+	if !ok || len(object) == 0 {
+		if prefix != "" {
+			fn(prefix, value)
+		}
+		return
+	}
+	for key, item := range object {
+		path := key
+		if prefix != "" {
+			path = prefix + "." + key
+		}
+		flatten(path, item, fn)
+	}
+}
+
+// Warnings lists problems found while loading the config, such as ignored
+// project settings
+func (c *Config) Warnings() []string {
+	return c.warnings
+}
+
+// GlobalPath returns the global config file
+func (c *Config) GlobalPath() string {
+	return c.globalPath
+}
+
+// ProjectPath returns the project config file merged into this config, or ""
+func (c *Config) ProjectPath() string {
+	return c.projectPath
+}
+
+// EffectiveSettings lists every set value of the merged config with the file
+// it came from, sorted by key
+func (c *Config) EffectiveSettings() ([]Setting, error) {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, err
+	}
+
+	var settings []Setting
+	flatten("", values, func(key string, value interface{}) {
+		settings = append(settings, Setting{Path: key, Value: value, Source: c.sourceOf(key)})
+	})
+	sort.Slice(settings, func(i, j int) bool { return settings[i].Path < settings[j].Path })
+	return settings, nil
+}
+
+// sourceOf returns where key or one of its parents was set
+func (c *Config) sourceOf(key string) string {
+	if source, ok := c.overrideSources[key]; ok {
+		return source
+	}
+	for path := key; ; {
+		if c.projectKeys[path] {
+			return "project"
+		}
+		i := strings.LastIndex(path, ".")
+		if i < 0 {
+			return "global"
+		}
+		path = path[:i]
+	}
+}
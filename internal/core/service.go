@@ -6,11 +6,17 @@ import (
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
 	"sync"
 
+	"github.com/Rorical/RoriCode/internal/attach"
 	"github.com/Rorical/RoriCode/internal/config"
 	"github.com/Rorical/RoriCode/internal/eventbus"
+	"github.com/Rorical/RoriCode/internal/mention"
+	"github.com/Rorical/RoriCode/internal/provider"
 	"github.com/Rorical/RoriCode/internal/models"
+	"github.com/Rorical/RoriCode/internal/redact"
 	"github.com/Rorical/RoriCode/internal/tools"
 	"github.com/sashabaranov/go-openai"
 )
@@ -21,6 +27,7 @@ type ChatService struct {
 	state            *ChatState
 	eventBus         *eventbus.EventBus
 	toolRegistry     *tools.Registry
+	redactor         *redact.Redactor // Removes secrets from tool results; nil when disabled
 	ctx              context.Context
 	cancel           context.CancelFunc
 	lastSentCount    int                            // Track how many messages we've sent to UI
@@ -33,21 +40,44 @@ type ChatService struct {
 func NewChatService(cfg *config.Config, eb *eventbus.EventBus) (*ChatService, error) {
 	var client *openai.Client
 
-	// Only create OpenAI client if config is valid
+	// Only create OpenAI client if config is valid and the key can be read
+	var keyErr error
 	if cfg.IsValid() {
-		clientConfig := openai.DefaultConfig(cfg.GetAPIKey())
-		if cfg.GetBaseURL() != "" {
-			clientConfig.BaseURL = cfg.GetBaseURL()
+		var apiKey string
+		apiKey, keyErr = cfg.ResolveAPIKey()
+		if keyErr == nil {
+			client = provider.NewClient(cfg.CurrentProfile(), apiKey)
 		}
-		client = openai.NewClientWithConfig(clientConfig)
 	}
 
 	state := NewChatState()
+	if cfg.RepoMapInSystemPrompt() {
+		state.EnableRepoMap(cfg.GetRepoMapTokenBudget())
+	}
+	state.SetInstructions(cfg.SystemPrompt)
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Initialize tool registry and register builtin tools
 	toolRegistry := tools.NewRegistry()
-	tools.RegisterBuiltinTools(toolRegistry)
+	tools.RegisterBuiltinTools(toolRegistry, cfg)
+	warnings := cfg.Warnings()
+	if err := cfg.CurrentProfile().Validate(); err != nil {
+		warnings = append(warnings, fmt.Sprintf("profile %s: %v", cfg.ActiveProfile, err))
+	}
+	if unknown := toolRegistry.Restrict(cfg.EnabledTools, cfg.DisabledTools); len(unknown) > 0 {
+		warnings = append(warnings, fmt.Sprintf("unknown tools in enabled_tools or disabled_tools: %s", strings.Join(unknown, ", ")))
+	}
+
+	// Secrets in tool results are replaced before they reach the model
+	var redactor *redact.Redactor
+	var redactErr error
+	if rc := cfg.GetRedactionConfig(); !rc.Disabled {
+		rules := redact.Rules{Allow: rc.Allow, DisableEntropy: rc.DisableEntropy}
+		for _, p := range rc.Patterns {
+			rules.Patterns = append(rules.Patterns, redact.Pattern{Name: p.Name, Regex: p.Regex})
+		}
+		redactor, redactErr = redact.New(rules)
+	}
 
 	service := &ChatService{
 		client:          client, // May be nil if config invalid
@@ -55,6 +85,7 @@ func NewChatService(cfg *config.Config, eb *eventbus.EventBus) (*ChatService, er
 		state:           state,
 		eventBus:        eb,
 		toolRegistry:    toolRegistry,
+		redactor:        redactor,
 		ctx:             ctx,
 		cancel:          cancel,
 		pendingConfirms: make(map[string]chan bool),
@@ -63,9 +94,22 @@ func NewChatService(cfg *config.Config, eb *eventbus.EventBus) (*ChatService, er
 
 	// Set the service as the confirmator for tools that need confirmation
 	toolRegistry.SetConfirmator(service)
+	toolRegistry.SetOutputHandler(service.sendToolOutput)
 
 	// Add welcome screen with better formatting
 	service.addWelcomeMessages(cfg)
+	if keyErr != nil {
+		warnings = append(warnings, fmt.Sprintf("could not read the API key for profile %s: %v", cfg.ActiveProfile, keyErr))
+	}
+	if redactErr != nil {
+		warnings = append(warnings, fmt.Sprintf("%v (the invalid rules are ignored)", redactErr))
+	}
+	for _, warning := range warnings {
+		service.state.AddProgramMessage("Warning: " + warning)
+	}
+	if len(warnings) > 0 {
+		service.state.AddProgramMessage("")
+	}
 
 	return service, nil
 }
@@ -79,6 +123,7 @@ func (cs *ChatService) Start() {
 
 func (cs *ChatService) Stop() {
 	cs.cancel()
+	cs.toolRegistry.Close()
 }
 
 func (cs *ChatService) eventLoop() {
@@ -98,16 +143,30 @@ func (cs *ChatService) eventLoop() {
 func (cs *ChatService) handleUIEvent(event eventbus.UIEvent) {
 	switch e := event.(type) {
 	case eventbus.SendMessageEvent:
-		cs.processMessage(e.Message)
+		cs.processMessage(e.Message, e.Images)
 	case eventbus.ConfirmationResponseEvent:
 		cs.handleConfirmationResponse(e)
 	}
 }
 
-func (cs *ChatService) processMessage(userMessage string) {
+func (cs *ChatService) processMessage(userMessage string, images []*attach.Image) {
+	// Text-only models would reject the request; refuse before it is recorded
+	if len(images) > 0 && cs.config.IsTextOnly() {
+		cs.state.FinishProcessingWithError(fmt.Errorf("profile '%s' is text-only; image attachments are not supported by model %s", cs.config.ActiveProfile, cs.config.GetModel()))
+		cs.pushStateToUI()
+		return
+	}
+
+	// Inline the files mentioned as @path or @path:from-to
+	var excerpts []*mention.Excerpt
+	if cwd, err := os.Getwd(); err == nil {
+		excerpts = mention.Expand(userMessage, cwd)
+	}
+
 	// Atomic update: Set processing and add user message
-	cs.state.StartProcessingWithUserMessage(userMessage)
+	cs.state.StartProcessingWithUserMessage(userMessage, excerpts, images)
 	cs.state.ResetRecursion() // Reset recursion depth for new conversation
+	cs.toolRegistry.ChangeTracker().Reset() // Track file changes per turn
 	cs.pushStateToUI()
 
 	// Start the recursive chat completion process
@@ -142,6 +201,7 @@ func (cs *ChatService) continueConversation() {
 		Messages: openaiMessages,
 		Tools:    cs.getToolsSpec(),
 	}
+	applyModelParams(&req, cs.config.CurrentProfile(), cs.state.IsFirstCall())
 
 	resp, err := cs.client.CreateChatCompletion(cs.ctx, req)
 
@@ -180,6 +240,52 @@ func (cs *ChatService) continueConversation() {
 	}
 }
 
+// applyModelParams sets the profile's model parameters on a request. A
+// forced tool choice only applies to the first call of a turn, so that the
+// model can answer once it has the tool results.
+func applyModelParams(req *openai.ChatCompletionRequest, profile config.Profile, firstCall bool) {
+	// A zero temperature or top_p is omitted by the request type; the client
+	// from provider.NewClient adds it to the body
+	if profile.Temperature != nil {
+		req.Temperature = *profile.Temperature
+	}
+	if profile.TopP != nil {
+		req.TopP = *profile.TopP
+	}
+	if profile.MaxTokens != nil && *profile.MaxTokens > 0 {
+		// Reasoning models only accept max_completion_tokens
+		if profile.ReasoningEffort != "" {
+			req.MaxCompletionTokens = *profile.MaxTokens
+		} else {
+			req.MaxTokens = *profile.MaxTokens
+		}
+	}
+	req.Seed = profile.Seed
+	req.Stop = profile.Stop
+	req.ReasoningEffort = profile.ReasoningEffort
+	if len(req.Tools) > 0 {
+		if profile.ParallelToolCalls != nil {
+			req.ParallelToolCalls = *profile.ParallelToolCalls
+		}
+		switch choice := profile.ToolChoice; choice {
+		case "auto", "none":
+			req.ToolChoice = choice
+		case "required":
+			if firstCall {
+				req.ToolChoice = choice
+			}
+		default:
+			if choice != "" && firstCall {
+				// Any other value names the tool the model must call
+				req.ToolChoice = openai.ToolChoice{
+					Type:     openai.ToolTypeFunction,
+					Function: openai.ToolFunction{Name: choice},
+				}
+			}
+		}
+	}
+}
+
 func (cs *ChatService) pushStateToUI() {
 	allMessages := cs.state.GetMessages()
 	isProcessing := cs.state.IsProcessing()
@@ -220,6 +326,9 @@ func (cs *ChatService) addWelcomeMessages(cfg *config.Config) {
 	} else {
 		cs.state.AddProgramMessage(fmt.Sprintf("Active Profile: %s [NOT CONFIGURED]", cfg.ActiveProfile))
 	}
+	if projectPath := cfg.ProjectPath(); projectPath != "" {
+		cs.state.AddProgramMessage(fmt.Sprintf("Project Config: %s", projectPath))
+	}
 	// Instructions
 	if cfg.IsValid() {
 		cs.state.AddProgramMessage("Ready to chat! Type your message and press Enter")
@@ -295,21 +404,35 @@ func (cs *ChatService) handleToolCalls(toolCalls []openai.ToolCall) {
 func (cs *ChatService) handleToolResult(resultChan <-chan tools.ToolResult) {
 	result := <-resultChan
 	
+	// Secrets are redacted before the result is recorded, so neither the
+	// model nor the transcript sees them
 	var resultContent string
+	var findings []redact.Finding
 	if result.Error != "" {
-		resultContent = fmt.Sprintf("Error: %s", result.Error)
-	} else {
+		errorText, found := cs.redactor.Redact(result.Error)
+		resultContent, findings = fmt.Sprintf("Error: %s", errorText), found
+	} else if value, found, err := cs.redactor.RedactValue(result.Result); err == nil {
 		// Convert result to JSON string for display
-		if resultBytes, err := json.MarshalIndent(result.Result, "", "  "); err == nil {
+		if resultBytes, err := json.MarshalIndent(value, "", "  "); err == nil {
 			resultContent = string(resultBytes)
 		} else {
-			resultContent = fmt.Sprintf("%v", result.Result)
+			resultContent = fmt.Sprintf("%v", value)
 		}
+		findings = found
+	} else {
+		resultContent, findings = cs.redactor.Redact(fmt.Sprintf("%v", result.Result))
 	}
 	
 	// Add tool result message
 	cs.state.AddToolResultMessage(result.CallID, result.Name, resultContent)
 	cs.pushStateToUI() // Show result immediately
+	if len(findings) > 0 {
+		cs.eventBus.SendToUI(eventbus.RedactionEvent{
+			CallID:   result.CallID,
+			ToolName: result.Name,
+			Summary:  redact.Summary(findings),
+		})
+	}
 	
 	// Mark this tool call as complete and check if all are done
 	allComplete := cs.state.CompletePendingToolCall(result.CallID)
@@ -318,6 +441,17 @@ func (cs *ChatService) handleToolResult(resultChan <-chan tools.ToolResult) {
 	}
 }
 
+// sendToolOutput forwards live output of a running tool to the UI. The model
+// only sees the final result, so lines are dropped rather than queued when
+// the UI falls behind.
+func (cs *ChatService) sendToolOutput(callID, toolName string, lines []string) {
+	cs.eventBus.TrySendToUI(eventbus.ToolOutputEvent{
+		CallID:   callID,
+		ToolName: toolName,
+		Lines:    lines,
+	})
+}
+
 // continueAfterAllToolsComplete continues the conversation after all tool calls are complete
 func (cs *ChatService) continueAfterAllToolsComplete() {
 	// All tool calls completed, continue the conversation recursively
@@ -1,12 +1,18 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"runtime"
+	"strings"
 	"sync"
+	"time"
 
+	"github.com/Rorical/RoriCode/internal/attach"
+	"github.com/Rorical/RoriCode/internal/mention"
 	"github.com/Rorical/RoriCode/internal/models"
+	"github.com/Rorical/RoriCode/internal/repomap"
 	"github.com/sashabaranov/go-openai"
 )
 
@@ -15,6 +21,7 @@ type ChatState struct {
 	mu                sync.RWMutex
 	chatHistory       []openai.ChatCompletionMessage // Single source of truth for conversation
 	programMessages   []models.Message               // Program messages (welcome, status, etc.)
+	displayText       map[int]string                 // Shown instead of user messages with inlined files, by history index
 	isProcessing      bool
 	lastError         error
 	conversationReady bool
@@ -22,12 +29,18 @@ type ChatState struct {
 	pendingToolCalls  map[string]bool // Track pending tool calls by ID
 	recursionDepth    int             // Current recursion depth for tool calls
 	maxRecursionDepth int             // Maximum allowed recursion depth
+	// Repository map attached to the system prompt
+	repoMapEnabled bool
+	repoMapBudget  int
+	// Configured instructions added to the system prompt
+	instructions string
 }
 
 func NewChatState() *ChatState {
 	return &ChatState{
 		chatHistory:       make([]openai.ChatCompletionMessage, 0),
 		programMessages:   make([]models.Message, 0),
+		displayText:       make(map[int]string),
 		isProcessing:      false,
 		lastError:         nil,
 		conversationReady: true,
@@ -37,8 +50,31 @@ func NewChatState() *ChatState {
 	}
 }
 
-// generateSystemPrompt creates a dynamic system prompt with current environment context
-func (cs *ChatState) generateSystemPrompt() string {
+// EnableRepoMap attaches a repository map of the working directory to the system prompt
+func (cs *ChatState) EnableRepoMap(tokenBudget int) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.repoMapEnabled = true
+	cs.repoMapBudget = tokenBudget
+}
+
+// SetInstructions adds configured instructions to the system prompt
+func (cs *ChatState) SetInstructions(instructions string) {
+	cs.mu.Lock()
+	defer cs.mu.Unlock()
+	cs.instructions = strings.TrimSpace(instructions)
+}
+
+// promptSettings are the parts of the state the system prompt depends on
+type promptSettings struct {
+	instructions   string
+	repoMapEnabled bool
+	repoMapBudget  int
+}
+
+// generateSystemPrompt creates a dynamic system prompt with current environment context.
+// It renders the repository map, which walks the tree, so it runs without the state lock.
+func generateSystemPrompt(settings promptSettings) string {
 	// Get current working directory
 	cwd, err := os.Getwd()
 	if err != nil {
@@ -61,7 +97,7 @@ func (cs *ChatState) generateSystemPrompt() string {
 	}
 
 	// Create system prompt template
-	return fmt.Sprintf(`You are an active coding assistant agent named RoriCode. Your role is to explore, understand, and cooperate with the user to complete coding tasks efficiently.
+	prompt := fmt.Sprintf(`You are an active coding assistant agent named RoriCode. Your role is to explore, understand, and cooperate with the user to complete coding tasks efficiently.
 
 ## Environment Context
 - **Current Working Directory**: %s
@@ -94,6 +130,21 @@ You have access to various tools that allow you to:
 - Be concise but thorough in explanations
 - Acknowledge limitations and ask for help when needed
 - Maintain a collaborative and helpful tone`, cwd, osName, systemOS, systemArch)
+
+	if settings.instructions != "" {
+		prompt += "\n\n## Additional Instructions\n" + settings.instructions
+	}
+
+	// Attach the repository map so the model does not have to re-explore the tree
+	if settings.repoMapEnabled && cwd != "unknown" {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		if _, repoMap, err := repomap.ForRoot(cwd).Render(ctx, settings.repoMapBudget); err == nil && repoMap != "" {
+			prompt += "\n\n## Repository Map\nFiles in the working directory with their top-level symbols (use the repo_map tool for a subdirectory or a larger budget):\n```\n" + repoMap + "```"
+		}
+	}
+
+	return prompt
 }
 
 func (cs *ChatState) GetChatHistory() []openai.ChatCompletionMessage {
@@ -107,10 +158,19 @@ func (cs *ChatState) GetChatHistory() []openai.ChatCompletionMessage {
 // GetChatHistoryWithSystemPrompt returns chat history with dynamic system prompt prepended
 func (cs *ChatState) GetChatHistoryWithSystemPrompt() []openai.ChatCompletionMessage {
 	cs.mu.RLock()
-	defer cs.mu.RUnlock()
+	settings := promptSettings{
+		instructions:   cs.instructions,
+		repoMapEnabled: cs.repoMapEnabled,
+		repoMapBudget:  cs.repoMapBudget,
+	}
+	cs.mu.RUnlock()
+
+	// Generate dynamic system prompt outside the lock, so a slow repository
+	// walk does not hold up writers and the UI
+	systemPrompt := generateSystemPrompt(settings)
 
-	// Generate dynamic system prompt
-	systemPrompt := cs.generateSystemPrompt()
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
 
 	// Create system message
 	systemMessage := openai.ChatCompletionMessage{
@@ -136,11 +196,15 @@ func (cs *ChatState) GetMessages() []models.Message {
 	result = append(result, cs.programMessages...)
 
 	// Convert chat history to UI messages
-	for _, openaiMsg := range cs.chatHistory {
+	for i, openaiMsg := range cs.chatHistory {
 		switch openaiMsg.Role {
 		case openai.ChatMessageRoleUser:
+			text, ok := cs.displayText[i]
+			if !ok {
+				text = userMessageText(openaiMsg)
+			}
 			result = append(result, models.Message{
-				Content: openaiMsg.Content,
+				Content: text,
 				Type:    models.User,
 			})
 		case openai.ChatMessageRoleAssistant:
@@ -173,6 +237,21 @@ func (cs *ChatState) GetMessages() []models.Message {
 	return result
 }
 
+// userMessageText returns the text of a user message; attached images are
+// shown by their placeholders
+func userMessageText(msg openai.ChatCompletionMessage) string {
+	if len(msg.MultiContent) == 0 {
+		return msg.Content
+	}
+	var parts []string
+	for _, part := range msg.MultiContent {
+		if part.Type == openai.ChatMessagePartTypeText && part.Text != "" {
+			parts = append(parts, part.Text)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
 // extractToolNameFromHistory finds the tool name for a given tool call ID
 func extractToolNameFromHistory(history []openai.ChatCompletionMessage, toolCallID string) string {
 	for _, msg := range history {
@@ -218,7 +297,7 @@ func (cs *ChatState) AddProgramMessage(content string) {
 }
 
 // Atomic operations for event ordering
-func (cs *ChatState) StartProcessingWithUserMessage(content string) {
+func (cs *ChatState) StartProcessingWithUserMessage(content string, excerpts []*mention.Excerpt, images []*attach.Image) {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
 
@@ -226,10 +305,49 @@ func (cs *ChatState) StartProcessingWithUserMessage(content string) {
 	cs.isProcessing = true
 	cs.lastError = nil
 
+	// Mentioned files are appended for the model; the conversation shows
+	// placeholders instead
+	if len(excerpts) > 0 {
+		display := content
+		var inlined strings.Builder
+		inlined.WriteString(content)
+		for _, excerpt := range excerpts {
+			display += " " + excerpt.Placeholder()
+			inlined.WriteString("\n\n" + excerpt.Render())
+		}
+		for _, img := range images {
+			display += " " + img.Placeholder()
+		}
+		cs.displayText[len(cs.chatHistory)] = display
+		content = inlined.String()
+	}
+
 	// Add to chat history (single source of truth)
 	openaiMsg := openai.ChatCompletionMessage{
-		Role:    openai.ChatMessageRoleUser,
-		Content: content,
+		Role: openai.ChatMessageRoleUser,
+	}
+	if len(images) == 0 {
+		openaiMsg.Content = content
+	} else {
+		// Images go in multimodal parts, each preceded by its placeholder so
+		// the model knows which file it is looking at
+		if content != "" {
+			openaiMsg.MultiContent = append(openaiMsg.MultiContent, openai.ChatMessagePart{
+				Type: openai.ChatMessagePartTypeText,
+				Text: content,
+			})
+		}
+		for _, img := range images {
+			openaiMsg.MultiContent = append(openaiMsg.MultiContent,
+				openai.ChatMessagePart{
+					Type: openai.ChatMessagePartTypeText,
+					Text: img.Placeholder(),
+				},
+				openai.ChatMessagePart{
+					Type:     openai.ChatMessagePartTypeImageURL,
+					ImageURL: &openai.ChatMessageImageURL{URL: img.DataURL(), Detail: openai.ImageURLDetailAuto},
+				})
+		}
 	}
 	cs.chatHistory = append(cs.chatHistory, openaiMsg)
 }
@@ -313,6 +431,13 @@ func (cs *ChatState) IncrementRecursion() {
 	cs.recursionDepth++
 }
 
+// IsFirstCall reports whether the current API call is the first of the turn
+func (cs *ChatState) IsFirstCall() bool {
+	cs.mu.RLock()
+	defer cs.mu.RUnlock()
+	return cs.recursionDepth <= 1
+}
+
 func (cs *ChatState) ResetRecursion() {
 	cs.mu.Lock()
 	defer cs.mu.Unlock()
@@ -4,6 +4,7 @@ import (
 	"errors"
 	"time"
 
+	"github.com/Rorical/RoriCode/internal/attach"
 	"github.com/Rorical/RoriCode/internal/models"
 )
 
@@ -20,6 +21,7 @@ type CoreEvent interface {
 // SendMessageEvent - UI requests core to send a message
 type SendMessageEvent struct {
 	Message string
+	Images  []*attach.Image // Images attached to the message
 }
 
 func (e SendMessageEvent) UIEvent() {}
@@ -51,6 +53,24 @@ type ConfirmationResponseEvent struct {
 
 func (e ConfirmationResponseEvent) UIEvent() {}
 
+// ToolOutputEvent - Core streams live output lines of a running tool call
+type ToolOutputEvent struct {
+	CallID   string   // Tool call producing the output
+	ToolName string
+	Lines    []string // New complete lines since the previous event
+}
+
+func (e ToolOutputEvent) CoreEvent() {}
+
+// RedactionEvent - Core reports secrets removed from a tool result before it reached the model
+type RedactionEvent struct {
+	CallID   string
+	ToolName string
+	Summary  string // What was redacted, e.g. "1 github_token, 2 high_entropy"
+}
+
+func (e RedactionEvent) CoreEvent() {}
+
 // EventBusError represents errors in event processing
 type EventBusError struct {
 	Operation string
@@ -182,6 +202,22 @@ func (eb *EventBus) SendToUI(event CoreEvent) error {
 	}
 }
 
+// TrySendToUI sends a best-effort event, such as live tool output, that may be
+// dropped when the UI is behind. Failures do not count towards the circuit
+// breaker.
+func (eb *EventBus) TrySendToUI(event CoreEvent) bool {
+	if eb.circuitBreaker.IsOpen() {
+		return false
+	}
+
+	select {
+	case eb.coreToUI <- event:
+		return true
+	default:
+		return false
+	}
+}
+
 func (eb *EventBus) UIToCore() <-chan UIEvent {
 	return eb.uiToCore
 }
@@ -0,0 +1,128 @@
+package fsutil
+
+import (
+	"bufio"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/bmatcuk/doublestar/v4"
+)
+
+// DefaultIgnoreFiles are the ignore files honoured when walking a workspace
+var DefaultIgnoreFiles = []string{".gitignore", ".ignore", ".roricodeignore"}
+
+// ignoreRule is a single gitignore-style pattern
+type ignoreRule struct {
+	base    string // Directory (slash separated, relative to root) the rule was declared in
+	pattern string // Doublestar pattern relative to base
+	negate  bool   // Rule starts with '!' and re-includes matches
+	dirOnly bool   // Rule ends with '/' and only matches directories
+}
+
+// IgnoreMatcher evaluates gitignore-style rules for paths relative to a root directory
+type IgnoreMatcher struct {
+	root  string
+	names []string
+	rules []ignoreRule
+}
+
+// NewIgnoreMatcher creates a matcher for root that reads the given ignore file names
+// (nil disables ignore files). Rules from the root directory are loaded immediately;
+// nested directories are loaded with LoadDir as a walk descends into them.
+func NewIgnoreMatcher(root string, names []string) *IgnoreMatcher {
+	m := &IgnoreMatcher{root: root, names: names}
+	m.LoadDir("")
+	return m
+}
+
+// LoadDir reads ignore files located in relDir (slash separated, relative to root)
+func (m *IgnoreMatcher) LoadDir(relDir string) {
+	for _, name := range m.names {
+		file, err := os.Open(filepath.Join(m.root, filepath.FromSlash(relDir), name))
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(file)
+		for scanner.Scan() {
+			if rule, ok := parseIgnoreLine(relDir, scanner.Text()); ok {
+				m.rules = append(m.rules, rule)
+			}
+		}
+		file.Close()
+	}
+}
+
+// AddPatterns adds extra root-level patterns, e.g. from tool arguments
+func (m *IgnoreMatcher) AddPatterns(patterns ...string) {
+	for _, p := range patterns {
+		if rule, ok := parseIgnoreLine("", p); ok {
+			m.rules = append(m.rules, rule)
+		}
+	}
+}
+
+// Match reports whether relPath (slash separated, relative to root) is ignored.
+// Later rules take precedence over earlier ones, as in git.
+func (m *IgnoreMatcher) Match(relPath string, isDir bool) bool {
+	relPath = filepath.ToSlash(relPath)
+	ignored := false
+	for _, rule := range m.rules {
+		if rule.dirOnly && !isDir {
+			continue
+		}
+
+		target := relPath
+		if rule.base != "" {
+			if !strings.HasPrefix(relPath, rule.base+"/") {
+				continue
+			}
+			target = strings.TrimPrefix(relPath, rule.base+"/")
+		}
+
+		if matched, _ := doublestar.Match(rule.pattern, target); matched {
+			ignored = !rule.negate
+		}
+	}
+	return ignored
+}
+
+// parseIgnoreLine converts one line of an ignore file into a rule
+func parseIgnoreLine(base, line string) (ignoreRule, bool) {
+	line = strings.TrimRight(line, " \t\r")
+	if line == "" || strings.HasPrefix(line, "#") {
+		return ignoreRule{}, false
+	}
+
+	rule := ignoreRule{base: strings.Trim(base, "/")}
+	if strings.HasPrefix(line, "!") {
+		rule.negate = true
+		line = line[1:]
+	}
+	line = strings.TrimPrefix(line, "\\")
+
+	if strings.HasSuffix(line, "/") {
+		rule.dirOnly = true
+		line = strings.TrimRight(line, "/")
+	}
+	if line == "" {
+		return ignoreRule{}, false
+	}
+
+	// Patterns containing a slash are anchored to the declaring directory,
+	// everything else matches at any depth
+	if strings.Contains(line, "/") {
+		rule.pattern = strings.TrimPrefix(line, "/")
+	} else {
+		rule.pattern = path.Join("**", line)
+	}
+
+	return rule, true
+}
+
+// IsHidden reports whether a file name is a dot file
+func IsHidden(name string) bool {
+	return strings.HasPrefix(name, ".") && name != "." && name != ".."
+}
@@ -0,0 +1,458 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ServerConfig describes how to start a language server and which files it handles
+type ServerConfig struct {
+	Language   string
+	Command    []string
+	Extensions []string
+}
+
+// DefaultServers returns the built-in language server configurations
+func DefaultServers() []ServerConfig {
+	return []ServerConfig{
+		{Language: "go", Command: []string{"gopls"}, Extensions: []string{".go"}},
+		{Language: "python", Command: []string{"pyright-langserver", "--stdio"}, Extensions: []string{".py", ".pyi"}},
+		{Language: "typescript", Command: []string{"typescript-language-server", "--stdio"}, Extensions: []string{".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs"}},
+	}
+}
+
+// languageIDs maps file extensions to LSP language identifiers
+var languageIDs = map[string]string{
+	".go":  "go",
+	".py":  "python",
+	".pyi": "python",
+	".ts":  "typescript",
+	".tsx": "typescriptreact",
+	".js":  "javascript",
+	".jsx": "javascriptreact",
+	".mjs": "javascript",
+	".cjs": "javascript",
+}
+
+// languageID returns the LSP language identifier for a file
+func languageID(path string) string {
+	ext := strings.ToLower(filepath.Ext(path))
+	if id, ok := languageIDs[ext]; ok {
+		return id
+	}
+	return strings.TrimPrefix(ext, ".")
+}
+
+// document is an open text document
+type document struct {
+	version int
+	content string
+	syncSeq int // Publish sequence number when the content was last sent
+}
+
+// diagnosticSet is the latest diagnostics published for a document
+type diagnosticSet struct {
+	version int // -1 when the server did not report a version
+	seq     int
+	items   []Diagnostic
+}
+
+// Client is a running language server for one workspace root
+type Client struct {
+	config ServerConfig
+	root   string
+	cmd    *exec.Cmd
+	conn   *Conn
+	stderr *tailBuffer
+
+	syncMu      sync.Mutex
+	mu          sync.Mutex
+	docs        map[string]*document
+	diagnostics map[string]diagnosticSet
+	publishSeq  int
+	published   chan struct{} // Closed and replaced whenever diagnostics arrive
+}
+
+// Start launches a language server and performs the initialize handshake
+func Start(ctx context.Context, root string, config ServerConfig) (*Client, error) {
+	if len(config.Command) == 0 {
+		return nil, fmt.Errorf("no command configured for %s language server", config.Language)
+	}
+	if _, err := exec.LookPath(config.Command[0]); err != nil {
+		return nil, fmt.Errorf("%s language server '%s' not found in PATH", config.Language, config.Command[0])
+	}
+
+	cmd := exec.Command(config.Command[0], config.Command[1:]...)
+	cmd.Dir = root
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdin pipe: %v", err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create stdout pipe: %v", err)
+	}
+	stderr := &tailBuffer{max: 4096}
+	cmd.Stderr = stderr
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start %s: %v", config.Command[0], err)
+	}
+
+	c := &Client{
+		config:      config,
+		root:        root,
+		cmd:         cmd,
+		stderr:      stderr,
+		docs:        make(map[string]*document),
+		diagnostics: make(map[string]diagnosticSet),
+		published:   make(chan struct{}),
+	}
+	c.conn = NewConn(stdout, stdin, c.handle)
+	go cmd.Wait()
+
+	initCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+	defer cancel()
+	if err := c.initialize(initCtx); err != nil {
+		cmd.Process.Kill()
+		if tail := strings.TrimSpace(stderr.String()); tail != "" {
+			return nil, fmt.Errorf("failed to initialize %s: %v\n%s", config.Command[0], err, tail)
+		}
+		return nil, fmt.Errorf("failed to initialize %s: %v", config.Command[0], err)
+	}
+	return c, nil
+}
+
+// initialize sends initialize and initialized
+func (c *Client) initialize(ctx context.Context) error {
+	rootURI := PathToURI(c.root)
+	params := map[string]interface{}{
+		"processId": os.Getpid(),
+		"clientInfo": map[string]interface{}{
+			"name": "roricode",
+		},
+		"rootUri":  rootURI,
+		"rootPath": c.root,
+		"workspaceFolders": []map[string]interface{}{
+			{"uri": rootURI, "name": filepath.Base(c.root)},
+		},
+		"capabilities": map[string]interface{}{
+			"general": map[string]interface{}{
+				"positionEncodings": []string{"utf-16"},
+			},
+			"workspace": map[string]interface{}{
+				"configuration":    true,
+				"workspaceFolders": true,
+			},
+			"textDocument": map[string]interface{}{
+				"synchronization": map[string]interface{}{
+					"didSave": true,
+				},
+				"publishDiagnostics": map[string]interface{}{
+					"versionSupport": true,
+				},
+				"hover": map[string]interface{}{
+					"contentFormat": []string{"markdown", "plaintext"},
+				},
+				"definition": map[string]interface{}{
+					"linkSupport": true,
+				},
+				"references": map[string]interface{}{},
+			},
+		},
+	}
+
+	if err := c.conn.Call(ctx, "initialize", params, nil); err != nil {
+		return err
+	}
+	return c.conn.Notify("initialized", map[string]interface{}{})
+}
+
+// handle answers server requests and records published diagnostics
+func (c *Client) handle(method string, params json.RawMessage) (interface{}, error) {
+	switch method {
+	case "textDocument/publishDiagnostics":
+		var p publishDiagnosticsParams
+		if err := json.Unmarshal(params, &p); err != nil {
+			return nil, nil
+		}
+		version := -1
+		if p.Version != nil {
+			version = *p.Version
+		}
+		c.mu.Lock()
+		c.publishSeq++
+		c.diagnostics[p.URI] = diagnosticSet{version: version, seq: c.publishSeq, items: p.Diagnostics}
+		close(c.published)
+		c.published = make(chan struct{})
+		c.mu.Unlock()
+		return nil, nil
+	case "workspace/configuration":
+		// No settings; answer one null per requested item
+		var p struct {
+			Items []json.RawMessage `json:"items"`
+		}
+		json.Unmarshal(params, &p)
+		return make([]interface{}, len(p.Items)), nil
+	case "workspace/workspaceFolders":
+		return []map[string]interface{}{
+			{"uri": PathToURI(c.root), "name": filepath.Base(c.root)},
+		}, nil
+	case "window/workDoneProgress/create", "client/registerCapability", "client/unregisterCapability", "window/showMessageRequest":
+		return nil, nil
+	case "window/logMessage", "window/showMessage", "$/progress", "telemetry/event", "$/logTrace":
+		return nil, nil
+	}
+	return nil, &ResponseError{Code: -32601, Message: "method not supported: " + method}
+}
+
+// Handles reports whether this server is configured for the file
+func (c *Client) Handles(path string) bool {
+	return handlesFile(c.config, path)
+}
+
+// handlesFile reports whether a server configuration covers the file extension
+func handlesFile(config ServerConfig, path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	for _, e := range config.Extensions {
+		if strings.ToLower(e) == ext {
+			return true
+		}
+	}
+	return false
+}
+
+// Alive reports whether the server connection is still open
+func (c *Client) Alive() bool {
+	select {
+	case <-c.conn.Done():
+		return false
+	default:
+		return true
+	}
+}
+
+// Sync opens the file in the server or sends its new content if it changed on
+// disk. It returns the document version.
+func (c *Client) Sync(path string) (int, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read file: %v", err)
+	}
+	uri := PathToURI(path)
+
+	// Serialise syncs so versions reach the server in order, but don't hold mu
+	// while writing: the read loop needs it to record diagnostics
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+
+	c.mu.Lock()
+	doc, open := c.docs[uri]
+	if open && doc.content == string(content) {
+		c.mu.Unlock()
+		return doc.version, nil
+	}
+	if !open {
+		doc = &document{version: 1}
+		c.docs[uri] = doc
+	} else {
+		doc.version++
+	}
+	doc.content = string(content)
+	doc.syncSeq = c.publishSeq
+	version := doc.version
+	c.mu.Unlock()
+
+	if !open {
+		return version, c.conn.Notify("textDocument/didOpen", map[string]interface{}{
+			"textDocument": map[string]interface{}{
+				"uri":        uri,
+				"languageId": languageID(path),
+				"version":    version,
+				"text":       string(content),
+			},
+		})
+	}
+
+	if err := c.conn.Notify("textDocument/didChange", map[string]interface{}{
+		"textDocument": map[string]interface{}{
+			"uri":     uri,
+			"version": version,
+		},
+		"contentChanges": []map[string]interface{}{
+			{"text": string(content)},
+		},
+	}); err != nil {
+		return version, err
+	}
+	return version, c.conn.Notify("textDocument/didSave", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	})
+}
+
+// SyncIfOpen sends new content for a file only if the server already has it open
+func (c *Client) SyncIfOpen(path string) error {
+	c.mu.Lock()
+	_, open := c.docs[PathToURI(path)]
+	c.mu.Unlock()
+	if !open {
+		return nil
+	}
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		return c.closeDocument(path)
+	}
+	_, err := c.Sync(path)
+	return err
+}
+
+// closeDocument tells the server a file is no longer open
+func (c *Client) closeDocument(path string) error {
+	uri := PathToURI(path)
+	c.syncMu.Lock()
+	defer c.syncMu.Unlock()
+	c.mu.Lock()
+	delete(c.docs, uri)
+	delete(c.diagnostics, uri)
+	c.mu.Unlock()
+	return c.conn.Notify("textDocument/didClose", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": uri},
+	})
+}
+
+// Diagnostics syncs the file and waits up to wait for the server to publish
+// diagnostics for its current version
+func (c *Client) Diagnostics(ctx context.Context, path string, wait time.Duration) ([]Diagnostic, bool, error) {
+	version, err := c.Sync(path)
+	if err != nil {
+		return nil, false, err
+	}
+	uri := PathToURI(path)
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+	for {
+		c.mu.Lock()
+		set, ok := c.diagnostics[uri]
+		published := c.published
+		var syncSeq int
+		if doc, open := c.docs[uri]; open {
+			syncSeq = doc.syncSeq
+		}
+		c.mu.Unlock()
+
+		// Without a version, any publish after the last sync is current
+		if ok && (set.version == version || (set.version == -1 && set.seq > syncSeq)) {
+			return set.items, true, nil
+		}
+
+		select {
+		case <-published:
+		case <-timer.C:
+			return set.items, false, nil
+		case <-ctx.Done():
+			return nil, false, ctx.Err()
+		case <-c.conn.Done():
+			return nil, false, fmt.Errorf("%s language server exited", c.config.Language)
+		}
+	}
+}
+
+// Definition returns the definition locations of the symbol at pos
+func (c *Client) Definition(ctx context.Context, path string, pos Position) ([]Location, error) {
+	return c.locations(ctx, "textDocument/definition", path, pos, nil)
+}
+
+// References returns the reference locations of the symbol at pos
+func (c *Client) References(ctx context.Context, path string, pos Position, includeDeclaration bool) ([]Location, error) {
+	return c.locations(ctx, "textDocument/references", path, pos, map[string]interface{}{
+		"includeDeclaration": includeDeclaration,
+	})
+}
+
+// locations performs a position request that returns locations
+func (c *Client) locations(ctx context.Context, method, path string, pos Position, extra map[string]interface{}) ([]Location, error) {
+	if _, err := c.Sync(path); err != nil {
+		return nil, err
+	}
+	params := map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": PathToURI(path)},
+		"position":     pos,
+	}
+	if extra != nil {
+		params["context"] = extra
+	}
+
+	var raw json.RawMessage
+	if err := c.conn.Call(ctx, method, params, &raw); err != nil {
+		return nil, err
+	}
+	return parseLocations(raw), nil
+}
+
+// Hover returns the hover documentation for the symbol at pos
+func (c *Client) Hover(ctx context.Context, path string, pos Position) (string, error) {
+	if _, err := c.Sync(path); err != nil {
+		return "", err
+	}
+
+	var result *hoverResult
+	if err := c.conn.Call(ctx, "textDocument/hover", map[string]interface{}{
+		"textDocument": map[string]interface{}{"uri": PathToURI(path)},
+		"position":     pos,
+	}, &result); err != nil {
+		return "", err
+	}
+	if result == nil {
+		return "", nil
+	}
+	return hoverText(result.Contents), nil
+}
+
+// Shutdown asks the server to exit and kills it if it does not
+func (c *Client) Shutdown() {
+	if c.Alive() {
+		ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+		if err := c.conn.Call(ctx, "shutdown", nil, nil); err == nil {
+			c.conn.Notify("exit", nil)
+		}
+		cancel()
+
+		select {
+		case <-c.conn.Done():
+		case <-time.After(2 * time.Second):
+		}
+	}
+	if c.cmd.Process != nil {
+		c.cmd.Process.Kill()
+	}
+}
+
+// tailBuffer keeps the last max bytes written to it
+type tailBuffer struct {
+	mu   sync.Mutex
+	max  int
+	data []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.data = append(t.data, p...)
+	if len(t.data) > t.max {
+		t.data = t.data[len(t.data)-t.max:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return string(t.data)
+}
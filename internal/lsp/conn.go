@@ -0,0 +1,256 @@
+package lsp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// message is a JSON-RPC 2.0 request, response or notification
+type message struct {
+	JSONRPC string           `json:"jsonrpc"`
+	ID      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  json.RawMessage  `json:"result,omitempty"`
+	Error   *ResponseError   `json:"error,omitempty"`
+}
+
+// ResponseError is a JSON-RPC error returned by the server
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return fmt.Sprintf("language server error %d: %s", e.Code, e.Message)
+}
+
+// Handler processes messages initiated by the server. For requests the
+// returned value is sent back as the result; for notifications it is ignored.
+type Handler func(method string, params json.RawMessage) (interface{}, error)
+
+// Conn is a JSON-RPC connection using LSP's Content-Length framing
+type Conn struct {
+	writer  io.Writer
+	reader  *bufio.Reader
+	handler Handler
+
+	writeMu sync.Mutex
+	mu      sync.Mutex
+	nextID  int64
+	pending map[int64]chan *message
+	closed  bool
+	done    chan struct{}
+	err     error
+}
+
+// NewConn creates a connection over the given streams and starts reading.
+// The handler is called from the read loop and must not block on calls.
+func NewConn(r io.Reader, w io.Writer, handler Handler) *Conn {
+	c := &Conn{
+		writer:  w,
+		reader:  bufio.NewReader(r),
+		handler: handler,
+		pending: make(map[int64]chan *message),
+		done:    make(chan struct{}),
+	}
+	go c.readLoop()
+	return c
+}
+
+// Call sends a request and decodes the response result into result (if non-nil)
+func (c *Conn) Call(ctx context.Context, method string, params, result interface{}) error {
+	c.mu.Lock()
+	if c.closed {
+		err := c.err
+		c.mu.Unlock()
+		return fmt.Errorf("language server connection closed: %v", err)
+	}
+	c.nextID++
+	id := c.nextID
+	ch := make(chan *message, 1)
+	c.pending[id] = ch
+	c.mu.Unlock()
+
+	defer func() {
+		c.mu.Lock()
+		delete(c.pending, id)
+		c.mu.Unlock()
+	}()
+
+	rawID := json.RawMessage(strconv.FormatInt(id, 10))
+	if err := c.send(&message{ID: &rawID, Method: method}, params); err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		// Tell the server we no longer need the answer
+		c.Notify("$/cancelRequest", map[string]interface{}{"id": id})
+		return ctx.Err()
+	case <-c.done:
+		return fmt.Errorf("language server connection closed: %v", c.err)
+	case resp := <-ch:
+		if resp.Error != nil {
+			return resp.Error
+		}
+		if result != nil && len(resp.Result) > 0 {
+			if err := json.Unmarshal(resp.Result, result); err != nil {
+				return fmt.Errorf("failed to decode %s response: %v", method, err)
+			}
+		}
+		return nil
+	}
+}
+
+// Notify sends a notification
+func (c *Conn) Notify(method string, params interface{}) error {
+	return c.send(&message{Method: method}, params)
+}
+
+// Done is closed when the connection stops reading
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// send encodes params into msg and writes it with a Content-Length header
+func (c *Conn) send(msg *message, params interface{}) error {
+	msg.JSONRPC = "2.0"
+	if params != nil {
+		data, err := json.Marshal(params)
+		if err != nil {
+			return fmt.Errorf("failed to encode %s params: %v", msg.Method, err)
+		}
+		msg.Params = data
+	}
+	return c.write(msg)
+}
+
+// write frames and writes a message
+func (c *Conn) write(msg *message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("failed to encode message: %v", err)
+	}
+
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n", len(body)); err != nil {
+		return fmt.Errorf("failed to write to language server: %v", err)
+	}
+	if _, err := c.writer.Write(body); err != nil {
+		return fmt.Errorf("failed to write to language server: %v", err)
+	}
+	return nil
+}
+
+// readLoop reads framed messages until the stream ends
+func (c *Conn) readLoop() {
+	var err error
+	for {
+		var msg *message
+		msg, err = c.readMessage()
+		if err != nil {
+			break
+		}
+
+		switch {
+		case msg.Method == "" && msg.ID != nil:
+			// Response to one of our calls
+			id, parseErr := strconv.ParseInt(string(*msg.ID), 10, 64)
+			if parseErr != nil {
+				continue
+			}
+			c.mu.Lock()
+			ch, ok := c.pending[id]
+			c.mu.Unlock()
+			if ok {
+				ch <- msg
+			}
+		case msg.ID != nil:
+			// Request from the server
+			go c.reply(msg)
+		default:
+			// Notification from the server
+			if c.handler != nil {
+				c.handler(msg.Method, msg.Params)
+			}
+		}
+	}
+
+	c.mu.Lock()
+	c.closed = true
+	c.err = err
+	c.mu.Unlock()
+	close(c.done)
+}
+
+// reply answers a server request through the handler
+func (c *Conn) reply(req *message) {
+	resp := &message{JSONRPC: "2.0", ID: req.ID}
+
+	var result interface{}
+	var err error
+	if c.handler != nil {
+		result, err = c.handler(req.Method, req.Params)
+	} else {
+		err = &ResponseError{Code: -32601, Message: "method not found: " + req.Method}
+	}
+
+	if err != nil {
+		if respErr, ok := err.(*ResponseError); ok {
+			resp.Error = respErr
+		} else {
+			resp.Error = &ResponseError{Code: -32603, Message: err.Error()}
+		}
+	} else {
+		data, marshalErr := json.Marshal(result)
+		if marshalErr != nil {
+			data = []byte("null")
+		}
+		resp.Result = data
+	}
+	c.write(resp)
+}
+
+// readMessage reads one Content-Length framed message
+func (c *Conn) readMessage() (*message, error) {
+	length := -1
+	for {
+		line, err := c.reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			break
+		}
+		name, value, found := strings.Cut(line, ":")
+		if found && strings.EqualFold(strings.TrimSpace(name), "Content-Length") {
+			length, err = strconv.Atoi(strings.TrimSpace(value))
+			if err != nil {
+				return nil, fmt.Errorf("invalid Content-Length: %s", value)
+			}
+		}
+	}
+	if length < 0 {
+		return nil, fmt.Errorf("message without Content-Length header")
+	}
+
+	body := make([]byte, length)
+	if _, err := io.ReadFull(c.reader, body); err != nil {
+		return nil, err
+	}
+
+	var msg message
+	if err := json.Unmarshal(body, &msg); err != nil {
+		return nil, fmt.Errorf("invalid message: %v", err)
+	}
+	return &msg, nil
+}
@@ -0,0 +1,142 @@
+package lsp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+)
+
+// Manager starts language servers on demand and keeps them running across
+// tool calls, one per language and workspace root
+type Manager struct {
+	servers []ServerConfig
+
+	mu       sync.Mutex
+	clients  map[string]*Client
+	starting map[string]*startCall
+	closed   bool
+}
+
+// startCall lets concurrent callers share one server startup
+type startCall struct {
+	done   chan struct{}
+	client *Client
+	err    error
+}
+
+// NewManager creates a manager for the given server configurations
+func NewManager(servers []ServerConfig) *Manager {
+	return &Manager{
+		servers:  servers,
+		clients:  make(map[string]*Client),
+		starting: make(map[string]*startCall),
+	}
+}
+
+// ServerFor returns the server configuration that handles a file
+func (m *Manager) ServerFor(path string) (ServerConfig, bool) {
+	for _, server := range m.servers {
+		if handlesFile(server, path) {
+			return server, true
+		}
+	}
+	return ServerConfig{}, false
+}
+
+// ClientFor returns a running server for the file, starting one if needed
+func (m *Manager) ClientFor(ctx context.Context, root, path string) (*Client, error) {
+	server, ok := m.ServerFor(path)
+	if !ok {
+		return nil, fmt.Errorf("no language server configured for %s files", filepath.Ext(path))
+	}
+	key := server.Language + "\x00" + root
+
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil, fmt.Errorf("language servers are shut down")
+	}
+	if client, ok := m.clients[key]; ok {
+		if client.Alive() {
+			m.mu.Unlock()
+			return client, nil
+		}
+		// Crashed; start a fresh one
+		delete(m.clients, key)
+	}
+	call, inProgress := m.starting[key]
+	if !inProgress {
+		call = &startCall{done: make(chan struct{})}
+		m.starting[key] = call
+	}
+	m.mu.Unlock()
+
+	if inProgress {
+		select {
+		case <-call.done:
+			return call.client, call.err
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	call.client, call.err = Start(ctx, root, server)
+
+	m.mu.Lock()
+	delete(m.starting, key)
+	if call.err == nil {
+		if m.closed {
+			// Shut down while we were starting
+			go call.client.Shutdown()
+			call.client, call.err = nil, fmt.Errorf("language servers are shut down")
+		} else {
+			m.clients[key] = call.client
+		}
+	}
+	m.mu.Unlock()
+	close(call.done)
+
+	return call.client, call.err
+}
+
+// NotifyChange sends the new content of a modified file to every running
+// server that has it open
+func (m *Manager) NotifyChange(path string) {
+	m.mu.Lock()
+	var clients []*Client
+	for _, client := range m.clients {
+		if client.Handles(path) && client.Alive() {
+			clients = append(clients, client)
+		}
+	}
+	m.mu.Unlock()
+
+	for _, client := range clients {
+		client.SyncIfOpen(path)
+	}
+}
+
+// Close shuts down all servers. The manager cannot be used afterwards.
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	if m.closed {
+		m.mu.Unlock()
+		return nil
+	}
+	m.closed = true
+	clients := m.clients
+	m.clients = make(map[string]*Client)
+	m.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, client := range clients {
+		wg.Add(1)
+		go func(c *Client) {
+			defer wg.Done()
+			c.Shutdown()
+		}(client)
+	}
+	wg.Wait()
+	return nil
+}
@@ -0,0 +1,202 @@
+package lsp
+
+import (
+	"encoding/json"
+	"net/url"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Position is a zero-based line and UTF-16 character offset
+type Position struct {
+	Line      int `json:"line"`
+	Character int `json:"character"`
+}
+
+// Range is a span in a document
+type Range struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// Location is a range in a document identified by URI
+type Location struct {
+	URI   string `json:"uri"`
+	Range Range  `json:"range"`
+}
+
+// locationLink is the alternative definition result shape
+type locationLink struct {
+	TargetURI            string `json:"targetUri"`
+	TargetRange          Range  `json:"targetRange"`
+	TargetSelectionRange Range  `json:"targetSelectionRange"`
+}
+
+// Diagnostic is a problem reported by a language server
+type Diagnostic struct {
+	Range    Range           `json:"range"`
+	Severity int             `json:"severity,omitempty"`
+	Code     json.RawMessage `json:"code,omitempty"`
+	Source   string          `json:"source,omitempty"`
+	Message  string          `json:"message"`
+}
+
+// SeverityName returns the textual name of a diagnostic severity
+func (d Diagnostic) SeverityName() string {
+	switch d.Severity {
+	case 1:
+		return "error"
+	case 2:
+		return "warning"
+	case 3:
+		return "info"
+	case 4:
+		return "hint"
+	}
+	return "error"
+}
+
+// CodeString returns the diagnostic code as text, whether sent as a number or string
+func (d Diagnostic) CodeString() string {
+	if len(d.Code) == 0 {
+		return ""
+	}
+	var s string
+	if json.Unmarshal(d.Code, &s) == nil {
+		return s
+	}
+	return string(d.Code)
+}
+
+// publishDiagnosticsParams is the payload of textDocument/publishDiagnostics
+type publishDiagnosticsParams struct {
+	URI         string       `json:"uri"`
+	Version     *int         `json:"version,omitempty"`
+	Diagnostics []Diagnostic `json:"diagnostics"`
+}
+
+// hoverResult is the payload of a textDocument/hover response
+type hoverResult struct {
+	Contents json.RawMessage `json:"contents"`
+}
+
+// PathToURI converts a file path to a file:// URI
+func PathToURI(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+	abs = filepath.ToSlash(abs)
+	if runtime.GOOS == "windows" {
+		abs = "/" + abs
+	}
+	return (&url.URL{Scheme: "file", Path: abs}).String()
+}
+
+// URIToPath converts a file:// URI to a file path
+func URIToPath(uri string) string {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != "file" {
+		return uri
+	}
+	path := u.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.FromSlash(path)
+}
+
+// UTF16Offset converts a zero-based rune offset within line to UTF-16 code units
+func UTF16Offset(line string, runeOffset int) int {
+	units := 0
+	for i, r := range []rune(line) {
+		if i >= runeOffset {
+			break
+		}
+		units += len(utf16.Encode([]rune{r}))
+	}
+	return units
+}
+
+// RuneOffset converts a UTF-16 offset within line to a zero-based rune offset
+func RuneOffset(line string, utf16Offset int) int {
+	units, runes := 0, 0
+	for len(line) > 0 && units < utf16Offset {
+		r, size := utf8.DecodeRuneInString(line)
+		units += len(utf16.Encode([]rune{r}))
+		runes++
+		line = line[size:]
+	}
+	return runes
+}
+
+// parseLocations decodes a definition or references result, which may be a
+// Location, a list of Locations or a list of LocationLinks
+func parseLocations(raw json.RawMessage) []Location {
+	if len(raw) == 0 || string(raw) == "null" {
+		return nil
+	}
+
+	var single Location
+	if json.Unmarshal(raw, &single) == nil && single.URI != "" {
+		return []Location{single}
+	}
+
+	var items []json.RawMessage
+	if json.Unmarshal(raw, &items) != nil {
+		return nil
+	}
+	var locations []Location
+	for _, item := range items {
+		var loc Location
+		if json.Unmarshal(item, &loc) == nil && loc.URI != "" {
+			locations = append(locations, loc)
+			continue
+		}
+		var link locationLink
+		if json.Unmarshal(item, &link) == nil && link.TargetURI != "" {
+			locations = append(locations, Location{URI: link.TargetURI, Range: link.TargetSelectionRange})
+		}
+	}
+	return locations
+}
+
+// hoverText flattens hover contents (MarkupContent, MarkedString or a list of
+// MarkedStrings) into plain markdown
+func hoverText(raw json.RawMessage) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+
+	var markup struct {
+		Kind     string `json:"kind"`
+		Language string `json:"language"`
+		Value    string `json:"value"`
+	}
+	if json.Unmarshal(raw, &markup) == nil && markup.Value != "" {
+		if markup.Language != "" {
+			return "```" + markup.Language + "\n" + markup.Value + "\n```"
+		}
+		return markup.Value
+	}
+
+	var items []json.RawMessage
+	if json.Unmarshal(raw, &items) == nil {
+		var parts []string
+		for _, item := range items {
+			if text := hoverText(item); text != "" {
+				parts = append(parts, text)
+			}
+		}
+		return strings.Join(parts, "\n\n")
+	}
+	return ""
+}
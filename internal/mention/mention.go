@@ -0,0 +1,250 @@
+package mention
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/Rorical/RoriCode/internal/attach"
+)
+
+// MaxExcerptBytes caps the text inlined for a single mention
+const MaxExcerptBytes = 32 * 1024
+
+// MaxTotalBytes caps the text inlined for all mentions of a message
+const MaxTotalBytes = 128 * 1024
+
+// tokenRe splits a mention into its path and optional line range:
+// @path, @path:10 or @path:10-40 (an open range runs to the end of the file)
+var tokenRe = regexp.MustCompile(`^@(.+?)(?::(\d+)(?:-(\d*))?)?$`)
+
+// Excerpt is a file, or a range of its lines, inlined into a user message
+type Excerpt struct {
+	Path      string // Slash separated, relative to the workspace root
+	From, To  int    // Lines included (1-based)
+	Total     int    // Lines in the file
+	Range     string // Line range as typed, e.g. ":10-40"; empty for the whole file
+	Content   string
+	Truncated bool // Content was cut to fit the size cap
+	Omitted   bool // Nothing was included because the message cap was reached
+}
+
+// Expand finds @path and @path:from-to mentions of files inside root and
+// reads them. Mentions that do not name a readable text file inside root,
+// such as @username or images (handled as attachments), are left alone.
+func Expand(message, root string) []*Excerpt {
+	var excerpts []*Excerpt
+	seen := make(map[string]bool)
+	budget := MaxTotalBytes
+
+	for _, field := range strings.Fields(message) {
+		if !strings.HasPrefix(field, "@") {
+			continue
+		}
+		token := strings.TrimRight(field, ",.;:!?)\"'")
+		match := tokenRe.FindStringSubmatch(token)
+		if match == nil || seen[token] {
+			continue
+		}
+		path := filepath.ToSlash(filepath.Clean(match[1]))
+		if attach.IsImagePath(path) {
+			continue
+		}
+		abs, ok := resolve(root, path)
+		if !ok {
+			continue
+		}
+		file, err := os.Open(abs)
+		if err != nil {
+			continue
+		}
+		reader := bufio.NewReaderSize(file, 64*1024)
+		if head, _ := reader.Peek(8000); isBinary(head) {
+			file.Close()
+			continue
+		}
+
+		excerpt := &Excerpt{Path: path}
+		if match[2] != "" {
+			excerpt.Range = token[len(match[1])+1:]
+			excerpt.From, _ = strconv.Atoi(match[2])
+			excerpt.To = excerpt.From
+			if strings.Contains(excerpt.Range, "-") {
+				excerpt.To = 0 // Open range
+				if match[3] != "" {
+					excerpt.To, _ = strconv.Atoi(match[3])
+				}
+			}
+		}
+		err = excerpt.read(reader, budget)
+		file.Close()
+		if err != nil {
+			continue
+		}
+		seen[token] = true
+		budget -= len(excerpt.Content)
+		excerpts = append(excerpts, excerpt)
+	}
+	return excerpts
+}
+
+// resolve joins a mentioned path to root, refusing absolute paths, paths
+// that climb out of root and symlinks that point outside it
+func resolve(root, path string) (string, bool) {
+	if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, "../") || strings.Contains(path, "/../") {
+		return "", false
+	}
+	abs := filepath.Join(root, filepath.FromSlash(path))
+	info, err := os.Stat(abs)
+	if err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+
+	realRoot, err := filepath.EvalSymlinks(root)
+	if err != nil {
+		return "", false
+	}
+	real, err := filepath.EvalSymlinks(abs)
+	if err != nil {
+		return "", false
+	}
+	rel, err := filepath.Rel(realRoot, real)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return abs, true
+}
+
+// read fills the excerpt from the file, within budget bytes. The file is
+// streamed: lines outside the excerpt are only counted, so mentioning a
+// large file does not load it into memory.
+func (e *Excerpt) read(r *bufio.Reader, budget int) error {
+	limit := MaxExcerptBytes
+	if budget < limit {
+		limit = budget
+	}
+	if limit <= 0 {
+		e.Omitted = true
+		return nil
+	}
+	if e.Range == "" {
+		e.From, e.To = 1, 0
+	}
+	if e.From < 1 {
+		e.From = 1
+	}
+
+	var b strings.Builder
+	var line []byte // Current line, kept only within the excerpt and capped past limit
+	n := 0          // Number of the current line
+	lineStart := true
+	full := false // The excerpt reached its size cap
+	inRange := func() bool { return !full && n >= e.From && (e.To == 0 || n <= e.To) }
+	endLine := func() {
+		if inRange() {
+			text := strings.TrimSuffix(strings.TrimSuffix(string(line), "\n"), "\r") + "\n"
+			if b.Len()+len(text) > limit {
+				e.To = n - 1
+				if b.Len() == 0 {
+					// A single overlong line is cut instead of dropped
+					b.WriteString(text[:limit] + "\n")
+					e.To = n
+				}
+				e.Truncated = true
+				full = true
+			} else {
+				b.WriteString(text)
+			}
+		}
+		line = line[:0]
+		lineStart = true
+	}
+
+	for {
+		chunk, err := r.ReadSlice('\n')
+		if len(chunk) > 0 {
+			if lineStart {
+				n++
+				lineStart = false
+			}
+			if inRange() && len(line) <= limit {
+				room := limit + 1 - len(line)
+				if len(chunk) < room {
+					room = len(chunk)
+				}
+				line = append(line, chunk[:room]...)
+			}
+			if chunk[len(chunk)-1] == '\n' {
+				endLine()
+			}
+		}
+		if err == bufio.ErrBufferFull {
+			continue
+		}
+		if err == io.EOF {
+			if !lineStart {
+				endLine()
+			}
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	e.Total = n
+	if !full && (e.To == 0 || e.To > e.Total) {
+		e.To = e.Total
+	}
+	e.Content = b.String()
+	return nil
+}
+
+// Placeholder is the text shown in place of the excerpt in the conversation
+func (e *Excerpt) Placeholder() string {
+	return fmt.Sprintf("[file: %s%s]", e.Path, e.Range)
+}
+
+// Render formats the excerpt as a fenced block for the model
+func (e *Excerpt) Render() string {
+	var b strings.Builder
+	switch {
+	case e.Omitted:
+		fmt.Fprintf(&b, "File %s was not inlined: the message already carries %d KB of file content. Use read_file to read it.\n", e.Path, MaxTotalBytes/1024)
+		return b.String()
+	case e.Total == 0:
+		fmt.Fprintf(&b, "File %s is empty.\n", e.Path)
+		return b.String()
+	case e.From > e.To:
+		fmt.Fprintf(&b, "File %s (%d lines): the requested lines are out of range.\n", e.Path, e.Total)
+		return b.String()
+	}
+
+	fmt.Fprintf(&b, "File %s (lines %d-%d of %d):\n", e.Path, e.From, e.To, e.Total)
+	fence := "```"
+	for strings.Contains(e.Content, fence) {
+		fence += "`"
+	}
+	lang := strings.TrimPrefix(filepath.Ext(e.Path), ".")
+	b.WriteString(fence + lang + "\n")
+	b.WriteString(e.Content)
+	b.WriteString(fence + "\n")
+	if e.Truncated {
+		fmt.Fprintf(&b, "(Truncated to fit the size limit; use read_file to read past line %d.)\n", e.To)
+	}
+	return b.String()
+}
+
+// isBinary reports whether data looks like a binary file
+func isBinary(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
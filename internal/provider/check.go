@@ -0,0 +1,192 @@
+package provider
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/Rorical/RoriCode/internal/config"
+)
+
+// Status is the outcome of one check
+type Status int
+
+const (
+	Pass Status = iota
+	Warn        // The check could not be completed, but nothing is known to be wrong
+	Fail
+)
+
+// CheckResult is the outcome of one step of a profile check
+type CheckResult struct {
+	Name   string
+	Status Status
+	Detail string
+}
+
+// probeTool is offered to the model to check that it can call tools
+const probeTool = "report_status"
+
+// ListModels returns the IDs of the models the endpoint offers, sorted
+func ListModels(ctx context.Context, client *openai.Client) ([]string, error) {
+	list, err := client.ListModels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	ids := make([]string, 0, len(list.Models))
+	for _, model := range list.Models {
+		ids = append(ids, model.ID)
+	}
+	sort.Strings(ids)
+	return ids, nil
+}
+
+// CheckProfile checks that a profile works: its API key can be read, the
+// endpoint is reachable and accepts the key, the model exists and it can
+// call tools. Checks after a failure that makes them pointless are skipped.
+func CheckProfile(ctx context.Context, profile config.Profile) []CheckResult {
+	var results []CheckResult
+	add := func(name string, status Status, format string, args ...interface{}) {
+		results = append(results, CheckResult{Name: name, Status: status, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	apiKey, err := profile.ResolveAPIKey()
+	if err != nil {
+		add("API key", Fail, "%v", err)
+		return results
+	}
+	add("API key", Pass, "read from %s", profile.APIKeySource())
+
+	client := NewClient(profile, apiKey)
+	models, err := ListModels(ctx, client)
+	switch {
+	case err == nil:
+		add("Endpoint", Pass, "reachable, API key accepted, %d models listed", len(models))
+		if len(models) == 0 {
+			add("Model", Warn, "the endpoint lists no models, %s not checked", profile.Model)
+		} else if contains(models, profile.Model) {
+			add("Model", Pass, "%s is available", profile.Model)
+		} else {
+			add("Model", Fail, "%s is not among the listed models (see roricode models)", profile.Model)
+		}
+	case statusCode(err) == http.StatusUnauthorized || statusCode(err) == http.StatusForbidden:
+		add("Endpoint", Fail, "reachable, but the API key was rejected: %s", describe(err))
+		return results
+	case statusCode(err) != 0:
+		// Some OpenAI-compatible servers do not implement /models; the probe
+		// below still checks the key and the model
+		add("Endpoint", Warn, "reachable, but listing models failed: %s", describe(err))
+		add("Model", Warn, "could not list models, %s not checked", profile.Model)
+	case isNetworkError(err):
+		add("Endpoint", Fail, "%s is unreachable: %v", baseURL(profile), err)
+		return results
+	default:
+		add("Endpoint", Fail, "%s did not answer like an OpenAI-compatible API: %s", baseURL(profile), describe(err))
+		return results
+	}
+
+	calls, err := probe(ctx, client, profile)
+	switch {
+	case err != nil:
+		add("Tool calls", Fail, "probe request failed: %s", describe(err))
+	case calls:
+		add("Tool calls", Pass, "the model called the probe tool")
+	default:
+		add("Tool calls", Warn, "the model answered without calling the probe tool; it may not support tool calls")
+	}
+	return results
+}
+
+// probe sends a tiny request offering one tool and reports whether the model
+// called it
+func probe(ctx context.Context, client *openai.Client, profile config.Profile) (bool, error) {
+	req := openai.ChatCompletionRequest{
+		Model: profile.Model,
+		Messages: []openai.ChatCompletionMessage{{
+			Role:    openai.ChatMessageRoleUser,
+			Content: fmt.Sprintf("This is a connection test. Call the %s tool with status \"ok\".", probeTool),
+		}},
+		Tools: []openai.Tool{{
+			Type: openai.ToolTypeFunction,
+			Function: &openai.FunctionDefinition{
+				Name:        probeTool,
+				Description: "Report the status of a connection test",
+				Parameters: map[string]interface{}{
+					"type": "object",
+					"properties": map[string]interface{}{
+						"status": map[string]interface{}{"type": "string"},
+					},
+					"required": []string{"status"},
+				},
+			},
+		}},
+	}
+	// No token limit: reasoning models reject max_tokens and need room to
+	// think before calling the tool
+	req.ReasoningEffort = profile.ReasoningEffort
+
+	resp, err := client.CreateChatCompletion(ctx, req)
+	if err != nil {
+		return false, err
+	}
+	for _, choice := range resp.Choices {
+		for _, call := range choice.Message.ToolCalls {
+			if call.Function.Name == probeTool {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// statusCode returns the HTTP status of an API error response, or 0 for
+// other errors
+func statusCode(err error) int {
+	var apiErr *openai.APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.HTTPStatusCode
+	}
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) {
+		return reqErr.HTTPStatusCode
+	}
+	return 0
+}
+
+// describe formats an API error. Responses that are not API errors, such as
+// HTML error pages, are reduced to their status.
+func describe(err error) string {
+	var reqErr *openai.RequestError
+	if errors.As(err, &reqErr) && reqErr.HTTPStatusCode != 0 {
+		return fmt.Sprintf("HTTP %s, not an API error response (check the base URL, which usually ends in /v1)", reqErr.HTTPStatus)
+	}
+	return err.Error()
+}
+
+// isNetworkError reports whether err means no HTTP response was received
+func isNetworkError(err error) bool {
+	var urlErr *url.Error
+	return errors.As(err, &urlErr)
+}
+
+func baseURL(profile config.Profile) string {
+	if profile.BaseURL != "" {
+		return strings.TrimSuffix(profile.BaseURL, "/")
+	}
+	return openai.DefaultConfig("").BaseURL
+}
+
+func contains(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
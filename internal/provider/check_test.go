@@ -0,0 +1,258 @@
+package provider
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/Rorical/RoriCode/internal/config"
+)
+
+const testKey = "sk-test"
+
+// fakeAPI is an OpenAI-compatible endpoint with configurable answers
+type fakeAPI struct {
+	models       []string
+	modelsStatus int    // Status of /models when not OK
+	modelsBody   string // Body sent with modelsStatus
+	toolCall     bool   // Whether the chat completion calls the probe tool
+
+	chatRequest map[string]interface{}
+	headers     http.Header
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.headers = r.Header.Clone()
+	if r.Header.Get("Authorization") != "Bearer "+testKey {
+		apiError(w, http.StatusUnauthorized, "Incorrect API key provided")
+		return
+	}
+
+	switch r.URL.Path {
+	case "/v1/models":
+		if f.modelsStatus != 0 {
+			w.WriteHeader(f.modelsStatus)
+			io.WriteString(w, f.modelsBody)
+			return
+		}
+		data := make([]map[string]string, 0, len(f.models))
+		for _, id := range f.models {
+			data = append(data, map[string]string{"id": id, "object": "model", "owned_by": "test"})
+		}
+		writeJSON(w, map[string]interface{}{"object": "list", "data": data})
+
+	case "/v1/chat/completions":
+		json.NewDecoder(r.Body).Decode(&f.chatRequest)
+		message := map[string]interface{}{"role": "assistant", "content": "ok"}
+		if f.toolCall {
+			message = map[string]interface{}{
+				"role": "assistant",
+				"tool_calls": []map[string]interface{}{{
+					"id":       "call_1",
+					"type":     "function",
+					"function": map[string]string{"name": probeTool, "arguments": `{"status":"ok"}`},
+				}},
+			}
+		}
+		writeJSON(w, map[string]interface{}{
+			"id":      "chatcmpl-1",
+			"object":  "chat.completion",
+			"choices": []map[string]interface{}{{"index": 0, "message": message, "finish_reason": "stop"}},
+		})
+
+	default:
+		apiError(w, http.StatusNotFound, "not found")
+	}
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(v)
+}
+
+func apiError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"error": map[string]string{"message": message, "type": "invalid_request_error"},
+	})
+}
+
+// serve starts api and returns a profile using it
+func serve(t *testing.T, api *fakeAPI) config.Profile {
+	t.Helper()
+	server := httptest.NewServer(api)
+	t.Cleanup(server.Close)
+	return config.Profile{Model: "test-model", BaseURL: server.URL + "/v1", APIKey: testKey}
+}
+
+// checkResults compares the name and status of each result, reporting
+// details on mismatch
+func checkResults(t *testing.T, results []CheckResult, want []CheckResult) {
+	t.Helper()
+	if len(results) != len(want) {
+		t.Fatalf("got %d results %+v, want %d", len(results), results, len(want))
+	}
+	for i := range want {
+		if results[i].Name != want[i].Name || results[i].Status != want[i].Status {
+			t.Errorf("result %d: got %s %v (%s), want %s %v", i, results[i].Name, results[i].Status, results[i].Detail, want[i].Name, want[i].Status)
+		}
+	}
+}
+
+func TestListModelsSorted(t *testing.T) {
+	profile := serve(t, &fakeAPI{models: []string{"zeta", "alpha", "mid"}})
+
+	models, err := ListModels(context.Background(), NewClient(profile, testKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(models, ",") != "alpha,mid,zeta" {
+		t.Errorf("got %v, want sorted IDs", models)
+	}
+}
+
+func TestListModelsRejectedKey(t *testing.T) {
+	profile := serve(t, &fakeAPI{})
+
+	_, err := ListModels(context.Background(), NewClient(profile, "sk-wrong"))
+	if statusCode(err) != http.StatusUnauthorized {
+		t.Errorf("got error %v, want HTTP 401", err)
+	}
+}
+
+func TestCheckProfilePasses(t *testing.T) {
+	api := &fakeAPI{models: []string{"other", "test-model"}, toolCall: true}
+	profile := serve(t, api)
+	profile.Headers = map[string]string{"X-Team": "core"}
+	profile.Project = "proj_1"
+
+	results := CheckProfile(context.Background(), profile)
+	checkResults(t, results, []CheckResult{
+		{Name: "API key", Status: Pass},
+		{Name: "Endpoint", Status: Pass},
+		{Name: "Model", Status: Pass},
+		{Name: "Tool calls", Status: Pass},
+	})
+	if !strings.Contains(results[1].Detail, "2 models") {
+		t.Errorf("endpoint detail %q does not count the models", results[1].Detail)
+	}
+
+	if api.headers.Get("X-Team") != "core" || api.headers.Get("OpenAI-Project") != "proj_1" {
+		t.Errorf("profile headers not sent: %v", api.headers)
+	}
+	if api.chatRequest["model"] != "test-model" {
+		t.Errorf("probe used model %v", api.chatRequest["model"])
+	}
+	if _, ok := api.chatRequest["max_tokens"]; ok {
+		t.Errorf("probe sent max_tokens, which reasoning models reject")
+	}
+}
+
+func TestCheckProfileWithoutAPIKey(t *testing.T) {
+	profile := serve(t, &fakeAPI{})
+	profile.APIKey = ""
+
+	checkResults(t, CheckProfile(context.Background(), profile), []CheckResult{
+		{Name: "API key", Status: Fail},
+	})
+}
+
+func TestCheckProfileRejectedKey(t *testing.T) {
+	profile := serve(t, &fakeAPI{models: []string{"test-model"}, toolCall: true})
+	profile.APIKey = "sk-wrong"
+
+	results := CheckProfile(context.Background(), profile)
+	checkResults(t, results, []CheckResult{
+		{Name: "API key", Status: Pass},
+		{Name: "Endpoint", Status: Fail},
+	})
+	if !strings.Contains(results[1].Detail, "rejected") {
+		t.Errorf("endpoint detail %q does not say the key was rejected", results[1].Detail)
+	}
+}
+
+func TestCheckProfileWithoutModelsEndpoint(t *testing.T) {
+	// Servers without /models still get the probe, which checks key and model
+	profile := serve(t, &fakeAPI{modelsStatus: http.StatusNotFound, modelsBody: `{"error":{"message":"no such route"}}`, toolCall: true})
+
+	checkResults(t, CheckProfile(context.Background(), profile), []CheckResult{
+		{Name: "API key", Status: Pass},
+		{Name: "Endpoint", Status: Warn},
+		{Name: "Model", Status: Warn},
+		{Name: "Tool calls", Status: Pass},
+	})
+}
+
+func TestCheckProfileHTMLErrorPage(t *testing.T) {
+	profile := serve(t, &fakeAPI{modelsStatus: http.StatusNotFound, modelsBody: "<html>Not Found</html>"})
+
+	results := CheckProfile(context.Background(), profile)
+	if len(results) < 2 || results[1].Status != Warn {
+		t.Fatalf("got %+v, want an endpoint warning", results)
+	}
+	if !strings.Contains(results[1].Detail, "not an API error response") || strings.Contains(results[1].Detail, "<html>") {
+		t.Errorf("endpoint detail %q should describe the status, not the page", results[1].Detail)
+	}
+}
+
+func TestCheckProfileMissingModel(t *testing.T) {
+	profile := serve(t, &fakeAPI{models: []string{"other-model"}, toolCall: true})
+
+	results := CheckProfile(context.Background(), profile)
+	checkResults(t, results, []CheckResult{
+		{Name: "API key", Status: Pass},
+		{Name: "Endpoint", Status: Pass},
+		{Name: "Model", Status: Fail},
+		{Name: "Tool calls", Status: Pass},
+	})
+	if !strings.Contains(results[2].Detail, "test-model") {
+		t.Errorf("model detail %q does not name the model", results[2].Detail)
+	}
+}
+
+func TestCheckProfileEmptyModelList(t *testing.T) {
+	profile := serve(t, &fakeAPI{toolCall: true})
+
+	checkResults(t, CheckProfile(context.Background(), profile), []CheckResult{
+		{Name: "API key", Status: Pass},
+		{Name: "Endpoint", Status: Pass},
+		{Name: "Model", Status: Warn},
+		{Name: "Tool calls", Status: Pass},
+	})
+}
+
+func TestCheckProfileProbeWithoutToolCall(t *testing.T) {
+	api := &fakeAPI{models: []string{"test-model"}}
+	profile := serve(t, api)
+
+	checkResults(t, CheckProfile(context.Background(), profile), []CheckResult{
+		{Name: "API key", Status: Pass},
+		{Name: "Endpoint", Status: Pass},
+		{Name: "Model", Status: Pass},
+		{Name: "Tool calls", Status: Warn},
+	})
+	tools, _ := api.chatRequest["tools"].([]interface{})
+	if len(tools) != 1 {
+		t.Errorf("probe offered %d tools, want 1", len(tools))
+	}
+}
+
+func TestCheckProfileUnreachable(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	server.Close()
+	profile := config.Profile{Model: "test-model", BaseURL: server.URL + "/v1", APIKey: testKey}
+
+	results := CheckProfile(context.Background(), profile)
+	checkResults(t, results, []CheckResult{
+		{Name: "API key", Status: Pass},
+		{Name: "Endpoint", Status: Fail},
+	})
+	if !strings.Contains(results[1].Detail, "unreachable") {
+		t.Errorf("endpoint detail %q does not say it is unreachable", results[1].Detail)
+	}
+}
@@ -0,0 +1,112 @@
+package provider
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sashabaranov/go-openai"
+
+	"github.com/Rorical/RoriCode/internal/config"
+)
+
+// NewClient creates an API client for a profile: its base URL, organization,
+// extra headers and any model parameters explicitly set to zero
+func NewClient(profile config.Profile, apiKey string) *openai.Client {
+	clientConfig := openai.DefaultConfig(apiKey)
+	if profile.BaseURL != "" {
+		clientConfig.BaseURL = profile.BaseURL
+	}
+	clientConfig.OrgID = profile.Organization
+
+	var transport http.RoundTripper = http.DefaultTransport
+	if headers := requestHeaders(profile); len(headers) > 0 {
+		transport = &headerTransport{base: transport, headers: headers}
+	}
+	if params := zeroParams(profile); len(params) > 0 {
+		transport = &zeroParamsTransport{base: transport, params: params}
+	}
+	if transport != http.DefaultTransport {
+		clientConfig.HTTPClient = &http.Client{Transport: transport}
+	}
+	return openai.NewClientWithConfig(clientConfig)
+}
+
+// requestHeaders returns the extra headers sent with API requests
+func requestHeaders(profile config.Profile) map[string]string {
+	headers := make(map[string]string)
+	for name, value := range profile.Headers {
+		headers[name] = value
+	}
+	if profile.Project != "" {
+		headers["OpenAI-Project"] = profile.Project
+	}
+	return headers
+}
+
+// headerTransport adds fixed headers to every request
+type headerTransport struct {
+	base    http.RoundTripper
+	headers map[string]string
+}
+
+func (t *headerTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	for name, value := range t.headers {
+		req.Header.Set(name, value)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// zeroParams returns the chat parameters the profile sets to zero. The
+// request type omits zero values, so they are added to the body instead.
+func zeroParams(profile config.Profile) []string {
+	var params []string
+	if profile.Temperature != nil && *profile.Temperature == 0 {
+		params = append(params, "temperature")
+	}
+	if profile.TopP != nil && *profile.TopP == 0 {
+		params = append(params, "top_p")
+	}
+	return params
+}
+
+// zeroParamsTransport sets params to 0 in chat completion requests that do
+// not send them
+type zeroParamsTransport struct {
+	base   http.RoundTripper
+	params []string
+}
+
+func (t *zeroParamsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Body == nil || req.Method != http.MethodPost || !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+		return t.base.RoundTrip(req)
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	var body map[string]json.RawMessage
+	if json.Unmarshal(data, &body) == nil {
+		for _, param := range t.params {
+			if _, ok := body[param]; !ok {
+				body[param] = json.RawMessage("0")
+			}
+		}
+		if patched, err := json.Marshal(body); err == nil {
+			data = patched
+		}
+	}
+
+	req = req.Clone(req.Context())
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(data)), nil
+	}
+	req.ContentLength = int64(len(data))
+	return t.base.RoundTrip(req)
+}
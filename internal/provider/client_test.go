@@ -0,0 +1,50 @@
+package provider
+
+import (
+	"context"
+	"testing"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+func chat(t *testing.T, client *openai.Client, req openai.ChatCompletionRequest) {
+	t.Helper()
+	req.Model = "test-model"
+	req.Messages = []openai.ChatCompletionMessage{{Role: openai.ChatMessageRoleUser, Content: "hi"}}
+	if _, err := client.CreateChatCompletion(context.Background(), req); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNewClientSendsZeroParams(t *testing.T) {
+	api := &fakeAPI{}
+	profile := serve(t, api)
+	zero := float32(0)
+	profile.Temperature = &zero
+	profile.TopP = &zero
+
+	chat(t, NewClient(profile, testKey), openai.ChatCompletionRequest{})
+	for _, param := range []string{"temperature", "top_p"} {
+		if value, ok := api.chatRequest[param]; !ok || value != float64(0) {
+			t.Errorf("%s: got %v, want an explicit 0", param, value)
+		}
+	}
+
+	// Values set on the request are left alone
+	chat(t, NewClient(profile, testKey), openai.ChatCompletionRequest{Temperature: 0.5})
+	if value := api.chatRequest["temperature"]; value != 0.5 {
+		t.Errorf("temperature: got %v, want 0.5", value)
+	}
+}
+
+func TestNewClientOmitsUnsetParams(t *testing.T) {
+	api := &fakeAPI{}
+	profile := serve(t, api)
+
+	chat(t, NewClient(profile, testKey), openai.ChatCompletionRequest{})
+	for _, param := range []string{"temperature", "top_p"} {
+		if value, ok := api.chatRequest[param]; ok {
+			t.Errorf("%s: sent %v for a profile without it", param, value)
+		}
+	}
+}
@@ -0,0 +1,317 @@
+package redact
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Pattern is a named regular expression matching secrets. When the
+// expression has a capture group, only the first group is redacted, so the
+// surrounding context (such as "password=") can be part of the match.
+type Pattern struct {
+	Name  string
+	Regex string
+}
+
+// Rules configure a Redactor
+type Rules struct {
+	Patterns       []Pattern // User patterns, checked after the built-in ones
+	Allow          []string  // Expressions for values that are never redacted
+	DisableEntropy bool      // Skip the high-entropy string detector
+}
+
+// Finding counts the values of one kind redacted from a text
+type Finding struct {
+	Kind  string
+	Count int
+}
+
+// builtinPatterns detect common credential formats. Specific formats come
+// first so that a token is named after the most precise match.
+var builtinPatterns = []struct {
+	Pattern
+	check func(value string) bool // Extra test for patterns matched by context
+}{
+	{Pattern{"private_key", `-----BEGIN (?:[A-Z0-9]+ )*PRIVATE KEY-----[\s\S]*?-----END (?:[A-Z0-9]+ )*PRIVATE KEY-----`}, nil},
+	{Pattern{"aws_access_key", `\b(?:AKIA|ASIA|ABIA|ACCA)[0-9A-Z]{16}\b`}, nil},
+	{Pattern{"aws_secret_key", `(?i)aws_?secret_?(?:access_?)?key["']?\s*[:=]\s*["']?([A-Za-z0-9/+=]{40})\b`}, nil},
+	{Pattern{"github_token", `\b(?:gh[pousr]_[A-Za-z0-9]{36,255}|github_pat_[A-Za-z0-9_]{22,255})\b`}, nil},
+	{Pattern{"anthropic_key", `\bsk-ant-[A-Za-z0-9_-]{20,}`}, nil},
+	{Pattern{"openai_key", `\bsk-(?:proj-|svcacct-|admin-)?[A-Za-z0-9_-]{20,}`}, nil},
+	{Pattern{"slack_token", `\bxox[abposr]-[A-Za-z0-9-]{10,}`}, nil},
+	{Pattern{"google_api_key", `\bAIza[0-9A-Za-z_-]{35}\b`}, nil},
+	{Pattern{"stripe_key", `\b(?:sk|rk)_(?:live|test)_[0-9A-Za-z]{16,}\b`}, nil},
+	{Pattern{"jwt", `\beyJ[A-Za-z0-9_-]{8,}\.eyJ[A-Za-z0-9_-]{8,}\.[A-Za-z0-9_-]{8,}`}, nil},
+	{Pattern{"password", `(?i)(?:password|passwd|pwd|secret|api_?key|access_?token|auth_?token)["']?\s*[:=]\s*["']?([^\s"',;()]{8,})`}, isLiteralSecret},
+}
+
+// entropyCandidateRe matches long runs of token characters that may be
+// random secrets
+var entropyCandidateRe = regexp.MustCompile(`[A-Za-z0-9+/_=-]{32,}`)
+
+// hashPrefixes mark checksums that look random but are not secrets, such as
+// go.sum hashes and package-lock integrity values
+var hashPrefixes = []string{"h1:", "sha1-", "sha256-", "sha384-", "sha512-", "sha256:", "sha512:"}
+
+// minEntropy is the Shannon entropy, in bits per character, above which a
+// token counts as random. Hex strings such as commit hashes stay below it.
+const minEntropy = 4.3
+
+type matcher struct {
+	kind  string
+	re    *regexp.Regexp
+	check func(value string) bool
+}
+
+// Redactor replaces secrets with placeholders. The same value always gets
+// the same placeholder, so the model can still tell values apart.
+type Redactor struct {
+	matchers []matcher
+	allow    []*regexp.Regexp
+	entropy  bool
+
+	mu           sync.Mutex
+	placeholders map[string]string // Secret value to placeholder
+	counts       map[string]int    // Placeholders issued per kind
+}
+
+// New creates a Redactor with the built-in detectors and the user rules. An
+// invalid user expression is reported and skipped.
+func New(rules Rules) (*Redactor, error) {
+	r := &Redactor{
+		entropy:      !rules.DisableEntropy,
+		placeholders: make(map[string]string),
+		counts:       make(map[string]int),
+	}
+	for _, p := range builtinPatterns {
+		r.matchers = append(r.matchers, matcher{kind: p.Name, re: regexp.MustCompile(p.Regex), check: p.check})
+	}
+
+	var errs []string
+	for _, p := range rules.Patterns {
+		re, err := regexp.Compile(p.Regex)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("pattern %s: %v", p.Name, err))
+			continue
+		}
+		kind := p.Name
+		if kind == "" {
+			kind = "custom"
+		}
+		r.matchers = append(r.matchers, matcher{kind: kind, re: re})
+	}
+	for _, expr := range rules.Allow {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			errs = append(errs, fmt.Sprintf("allow %q: %v", expr, err))
+			continue
+		}
+		r.allow = append(r.allow, re)
+	}
+
+	if len(errs) > 0 {
+		return r, fmt.Errorf("invalid redaction rules: %s", strings.Join(errs, "; "))
+	}
+	return r, nil
+}
+
+// Redact replaces the secrets in text and reports what was found
+func (r *Redactor) Redact(text string) (string, []Finding) {
+	if r == nil {
+		return text, nil
+	}
+	counts := make(map[string]int)
+	text = r.redact(text, counts)
+	return text, findings(counts)
+}
+
+// RedactValue replaces the secrets in the strings of a JSON-encodable value,
+// such as a tool result. The value is returned in its decoded JSON form.
+func (r *Redactor) RedactValue(value interface{}) (interface{}, []Finding, error) {
+	if r == nil {
+		return value, nil, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, nil, err
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, nil, err
+	}
+
+	counts := make(map[string]int)
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case string:
+			return r.redact(v, counts)
+		case map[string]interface{}:
+			for key, item := range v {
+				v[key] = walk(item)
+			}
+		case []interface{}:
+			for i, item := range v {
+				v[i] = walk(item)
+			}
+		}
+		return v
+	}
+	return walk(decoded), findings(counts), nil
+}
+
+// redact replaces the secrets in text, counting them by kind
+func (r *Redactor) redact(text string, counts map[string]int) string {
+	for _, m := range r.matchers {
+		m := m
+		text = r.replace(text, m.re, func(value string) (string, bool) {
+			return m.kind, m.check == nil || m.check(value)
+		}, counts)
+	}
+	if r.entropy {
+		text = r.replace(text, entropyCandidateRe, func(value string) (string, bool) {
+			return "high_entropy", isHighEntropy(value)
+		}, counts)
+	}
+	return text
+}
+
+// findings lists the counts by kind
+func findings(counts map[string]int) []Finding {
+	var list []Finding
+	for kind, count := range counts {
+		list = append(list, Finding{Kind: kind, Count: count})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Kind < list[j].Kind })
+	return list
+}
+
+// replace redacts the matches of re for which classify returns true. Only
+// the first capture group is redacted when re has one.
+func (r *Redactor) replace(text string, re *regexp.Regexp, classify func(string) (string, bool), counts map[string]int) string {
+	matches := re.FindAllStringSubmatchIndex(text, -1)
+	if len(matches) == 0 {
+		return text
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, loc := range matches {
+		start, end := loc[0], loc[1]
+		if len(loc) >= 4 && loc[2] >= 0 {
+			start, end = loc[2], loc[3]
+		}
+		value := text[start:end]
+		if isPlaceholder(value) || r.allowed(value) || hasHashPrefix(text, start) {
+			continue
+		}
+		kind, ok := classify(value)
+		if !ok {
+			continue
+		}
+		b.WriteString(text[last:start])
+		b.WriteString(r.placeholder(kind, value))
+		last = end
+		counts[kind]++
+	}
+	b.WriteString(text[last:])
+	return b.String()
+}
+
+// placeholder returns the stable placeholder for a secret value
+func (r *Redactor) placeholder(kind, value string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if placeholder, ok := r.placeholders[value]; ok {
+		return placeholder
+	}
+	r.counts[kind]++
+	placeholder := fmt.Sprintf("[REDACTED:%s#%d]", kind, r.counts[kind])
+	r.placeholders[value] = placeholder
+	return placeholder
+}
+
+func (r *Redactor) allowed(value string) bool {
+	for _, re := range r.allow {
+		if re.MatchString(value) {
+			return true
+		}
+	}
+	return false
+}
+
+// isPlaceholder reports whether value is already redacted
+func isPlaceholder(value string) bool {
+	return strings.HasPrefix(value, "[REDACTED:")
+}
+
+// hasHashPrefix reports whether the token at start is labelled as a checksum
+func hasHashPrefix(text string, start int) bool {
+	for _, prefix := range hashPrefixes {
+		if strings.HasSuffix(text[:start], prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// isLiteralSecret reports whether a value assigned to a secret-like name is
+// a literal rather than a reference such as $DB_PASSWORD or cfg.Password
+func isLiteralSecret(value string) bool {
+	switch value[0] {
+	case '$', '%', '<', '{', '[':
+		return false
+	}
+	return strings.ContainsAny(value, "0123456789")
+}
+
+// isHighEntropy reports whether a token looks like a random secret: mixed
+// letters and digits with high Shannon entropy
+func isHighEntropy(token string) bool {
+	var upper, lower, digit bool
+	for _, c := range token {
+		switch {
+		case c >= 'A' && c <= 'Z':
+			upper = true
+		case c >= 'a' && c <= 'z':
+			lower = true
+		case c >= '0' && c <= '9':
+			digit = true
+		}
+	}
+	if !upper || !lower || !digit {
+		return false
+	}
+	return shannonEntropy(token) >= minEntropy
+}
+
+func shannonEntropy(s string) float64 {
+	freq := make(map[rune]int)
+	for _, c := range s {
+		freq[c]++
+	}
+	n := float64(len(s))
+	entropy := 0.0
+	for _, count := range freq {
+		p := float64(count) / n
+		entropy -= p * math.Log2(p)
+	}
+	return entropy
+}
+
+// Summary describes findings for the user, e.g. "2 github_token, 1 jwt"
+func Summary(findings []Finding) string {
+	parts := make([]string, len(findings))
+	for i, f := range findings {
+		parts[i] = fmt.Sprintf("%d %s", f.Count, f.Kind)
+	}
+	return strings.Join(parts, ", ")
+}
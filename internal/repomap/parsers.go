@@ -0,0 +1,217 @@
+package repomap
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Symbol is a top-level declaration found in a source file
+type Symbol struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	Line     int    `json:"line"`
+	Exported bool   `json:"exported"`
+}
+
+// symbolPattern extracts a symbol of the given kind from a matching line
+type symbolPattern struct {
+	re   *regexp.Regexp
+	kind string
+}
+
+// Lightweight line-based parsers for common non-Go languages. They only look at
+// top-level (unindented) declarations, which is enough for an overview map.
+var languagePatterns = map[string][]symbolPattern{
+	"python": {
+		{regexp.MustCompile(`^class\s+([A-Za-z_]\w*)`), "class"},
+		{regexp.MustCompile(`^(?:async\s+)?def\s+([A-Za-z_]\w*)`), "func"},
+	},
+	"javascript": {
+		{regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:abstract\s+)?class\s+([A-Za-z_$][\w$]*)`), "class"},
+		{regexp.MustCompile(`^(?:export\s+)?(?:default\s+)?(?:async\s+)?function\*?\s+([A-Za-z_$][\w$]*)`), "func"},
+		{regexp.MustCompile(`^(?:export\s+)?interface\s+([A-Za-z_$][\w$]*)`), "interface"},
+		{regexp.MustCompile(`^(?:export\s+)?type\s+([A-Za-z_$][\w$]*)\s*(?:<[^=]*>)?\s*=`), "type"},
+		{regexp.MustCompile(`^(?:export\s+)?(?:const\s+)?enum\s+([A-Za-z_$][\w$]*)`), "enum"},
+		{regexp.MustCompile(`^export\s+(?:const|let|var)\s+([A-Za-z_$][\w$]*)`), "var"},
+	},
+	"rust": {
+		{regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?(?:async\s+)?(?:unsafe\s+)?fn\s+([A-Za-z_]\w*)`), "func"},
+		{regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?struct\s+([A-Za-z_]\w*)`), "struct"},
+		{regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?enum\s+([A-Za-z_]\w*)`), "enum"},
+		{regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?trait\s+([A-Za-z_]\w*)`), "trait"},
+		{regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?type\s+([A-Za-z_]\w*)`), "type"},
+		{regexp.MustCompile(`^(?:pub(?:\([^)]*\))?\s+)?mod\s+([A-Za-z_]\w*)`), "mod"},
+		{regexp.MustCompile(`^impl(?:<[^>]*>)?\s+(?:[\w:<>, ]+\s+for\s+)?([A-Za-z_]\w*)`), "impl"},
+	},
+	"java": {
+		{regexp.MustCompile(`^(?:public\s+|protected\s+|private\s+)?(?:abstract\s+|final\s+|static\s+|sealed\s+)*(?:class|interface|enum|record)\s+([A-Za-z_]\w*)`), "class"},
+		{regexp.MustCompile(`^\s{4}(?:public|protected)\s+(?:static\s+|final\s+|abstract\s+|synchronized\s+)*[\w<>\[\], ?]+\s+([A-Za-z_]\w*)\s*\(`), "method"},
+	},
+	"c": {
+		{regexp.MustCompile(`^(?:typedef\s+)?struct\s+([A-Za-z_]\w*)\s*\{`), "struct"},
+		{regexp.MustCompile(`^(?:typedef\s+)?enum\s+([A-Za-z_]\w*)`), "enum"},
+		{regexp.MustCompile(`^class\s+([A-Za-z_]\w*)`), "class"},
+		{regexp.MustCompile(`^#define\s+([A-Za-z_]\w*)`), "macro"},
+		{regexp.MustCompile(`^(?:static\s+|inline\s+|extern\s+)*[A-Za-z_][\w\s\*&:<>]*?\b([A-Za-z_]\w*)\s*\([^;]*\)\s*\{?\s*$`), "func"},
+	},
+	"ruby": {
+		{regexp.MustCompile(`^\s*(?:class|module)\s+([A-Z]\w*)`), "class"},
+		{regexp.MustCompile(`^\s{0,2}def\s+(?:self\.)?([A-Za-z_]\w*[?!]?)`), "func"},
+	},
+}
+
+// languageByExt maps file extensions to the parser used for them
+var languageByExt = map[string]string{
+	".go":   "go",
+	".py":   "python",
+	".js":   "javascript",
+	".jsx":  "javascript",
+	".mjs":  "javascript",
+	".cjs":  "javascript",
+	".ts":   "javascript",
+	".tsx":  "javascript",
+	".rs":   "rust",
+	".java": "java",
+	".kt":   "java",
+	".cs":   "java",
+	".c":    "c",
+	".h":    "c",
+	".cc":   "c",
+	".cpp":  "c",
+	".hpp":  "c",
+	".rb":   "ruby",
+}
+
+// LanguageForFile returns the parser language for a file name, or "" if unsupported
+func LanguageForFile(name string) string {
+	return languageByExt[strings.ToLower(filepath.Ext(name))]
+}
+
+// ParseSymbols extracts top-level symbols from source content
+func ParseSymbols(filename string, content []byte) []Symbol {
+	lang := LanguageForFile(filename)
+	switch lang {
+	case "":
+		return nil
+	case "go":
+		return parseGoSymbols(filename, content)
+	}
+
+	var symbols []Symbol
+	for i, line := range strings.Split(string(content), "\n") {
+		for _, p := range languagePatterns[lang] {
+			m := p.re.FindStringSubmatch(line)
+			if m == nil {
+				continue
+			}
+			name := m[1]
+			if lang == "c" && p.kind == "func" && isCKeyword(name) {
+				continue
+			}
+			symbols = append(symbols, Symbol{
+				Name:     name,
+				Kind:     p.kind,
+				Line:     i + 1,
+				Exported: !strings.HasPrefix(name, "_"),
+			})
+			break
+		}
+	}
+	return symbols
+}
+
+// parseGoSymbols lists top-level Go declarations using go/parser
+func parseGoSymbols(filename string, content []byte) []Symbol {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, filename, content, parser.SkipObjectResolution)
+	if file == nil {
+		return nil
+	}
+	_ = err // Partial ASTs still carry useful declarations
+
+	var symbols []Symbol
+	for _, d := range file.Decls {
+		switch decl := d.(type) {
+		case *ast.FuncDecl:
+			name := decl.Name.Name
+			kind := "func"
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				kind = "method"
+				if recv := goReceiverName(decl.Recv.List[0].Type); recv != "" {
+					name = recv + "." + name
+				}
+			}
+			symbols = append(symbols, Symbol{
+				Name:     name,
+				Kind:     kind,
+				Line:     fset.Position(decl.Pos()).Line,
+				Exported: decl.Name.IsExported(),
+			})
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					kind := "type"
+					switch s.Type.(type) {
+					case *ast.StructType:
+						kind = "struct"
+					case *ast.InterfaceType:
+						kind = "interface"
+					}
+					symbols = append(symbols, Symbol{
+						Name:     s.Name.Name,
+						Kind:     kind,
+						Line:     fset.Position(s.Pos()).Line,
+						Exported: s.Name.IsExported(),
+					})
+				case *ast.ValueSpec:
+					kind := "var"
+					if decl.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, ident := range s.Names {
+						if ident.Name == "_" {
+							continue
+						}
+						symbols = append(symbols, Symbol{
+							Name:     ident.Name,
+							Kind:     kind,
+							Line:     fset.Position(ident.Pos()).Line,
+							Exported: ident.IsExported(),
+						})
+					}
+				}
+			}
+		}
+	}
+	return symbols
+}
+
+// goReceiverName extracts the base type name of a method receiver
+func goReceiverName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexExpr:
+		return goReceiverName(t.X)
+	case *ast.IndexListExpr:
+		return goReceiverName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// isCKeyword filters control-flow statements the C function heuristic can match
+func isCKeyword(name string) bool {
+	switch name {
+	case "if", "for", "while", "switch", "return", "sizeof", "else", "do":
+		return true
+	}
+	return !unicode.IsLetter(rune(name[0])) && name[0] != '_'
+}
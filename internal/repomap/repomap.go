@@ -0,0 +1,335 @@
+package repomap
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Rorical/RoriCode/internal/fsutil"
+)
+
+const (
+	// DefaultTokenBudget is used when no budget is configured
+	DefaultTokenBudget = 2000
+	// maxParseSize skips symbol extraction for very large (usually generated) files
+	maxParseSize = 512 * 1024
+	// maxFiles bounds the walk on very large trees
+	maxFiles = 20000
+	// maxSymbolsPerFile keeps one huge file from dominating the map
+	maxSymbolsPerFile = 40
+)
+
+// skippedDirs are never useful in a repository overview
+var skippedDirs = map[string]bool{
+	".git": true, "node_modules": true, "vendor": true, "__pycache__": true,
+	"dist": true, "build": true, "target": true, ".venv": true, "venv": true,
+}
+
+// FileEntry is one file in the repository map
+type FileEntry struct {
+	Path     string    `json:"path"`
+	Size     int64     `json:"size"`
+	ModTime  time.Time `json:"modified"`
+	Language string    `json:"language,omitempty"`
+	Symbols  []Symbol  `json:"symbols,omitempty"`
+}
+
+// Map is a snapshot of the repository tree with per-file symbols
+type Map struct {
+	Root      string
+	Files     []FileEntry
+	Truncated bool // Walk stopped at maxFiles
+}
+
+// cachedFile holds parsed symbols together with the stat data they were parsed from
+type cachedFile struct {
+	size    int64
+	modTime time.Time
+	symbols []Symbol
+}
+
+// Cache builds repository maps for one root, reparsing only files whose
+// modification time or size changed since the previous build
+type Cache struct {
+	mu          sync.Mutex
+	root        string
+	files       map[string]cachedFile
+	fingerprint uint64
+	rendered    map[int]string // Rendered text per token budget for the current fingerprint
+}
+
+var (
+	cachesMu sync.Mutex
+	caches   = make(map[string]*Cache)
+)
+
+// ForRoot returns the shared cache for a root directory
+func ForRoot(root string) *Cache {
+	root = filepath.Clean(root)
+
+	cachesMu.Lock()
+	defer cachesMu.Unlock()
+
+	cache, exists := caches[root]
+	if !exists {
+		cache = &Cache{
+			root:     root,
+			files:    make(map[string]cachedFile),
+			rendered: make(map[int]string),
+		}
+		caches[root] = cache
+	}
+	return cache
+}
+
+// Build walks the tree and returns an up-to-date map
+func (c *Cache) Build(ctx context.Context) (*Map, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.buildLocked(ctx)
+}
+
+// Render builds the map and renders it within the token budget. The rendered
+// text is reused as long as no file in the tree changed.
+func (c *Cache) Render(ctx context.Context, tokenBudget int) (*Map, string, error) {
+	if tokenBudget <= 0 {
+		tokenBudget = DefaultTokenBudget
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	m, err := c.buildLocked(ctx)
+	if err != nil {
+		return nil, "", err
+	}
+	if text, ok := c.rendered[tokenBudget]; ok {
+		return m, text, nil
+	}
+
+	text := m.Render(tokenBudget)
+	c.rendered[tokenBudget] = text
+	return m, text, nil
+}
+
+func (c *Cache) buildLocked(ctx context.Context) (*Map, error) {
+	info, err := os.Stat(c.root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to access %s: %v", c.root, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", c.root)
+	}
+
+	m := &Map{Root: c.root}
+	seen := make(map[string]bool)
+	hash := fnv.New64a()
+	ignore := fsutil.NewIgnoreMatcher(c.root, fsutil.DefaultIgnoreFiles)
+
+	err = filepath.WalkDir(c.root, func(fullPath string, d fs.DirEntry, walkErr error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walkErr != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fullPath == c.root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(c.root, fullPath)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if fsutil.IsHidden(d.Name()) || ignore.Match(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			if skippedDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			ignore.LoadDir(rel)
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			return nil
+		}
+		if len(m.Files) >= maxFiles {
+			m.Truncated = true
+			return filepath.SkipAll
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+
+		entry := FileEntry{
+			Path:     rel,
+			Size:     info.Size(),
+			ModTime:  info.ModTime(),
+			Language: LanguageForFile(rel),
+		}
+		fmt.Fprintf(hash, "%s\x00%d\x00%d\n", rel, entry.Size, entry.ModTime.UnixNano())
+
+		cached, ok := c.files[rel]
+		if ok && cached.size == entry.Size && cached.modTime.Equal(entry.ModTime) {
+			entry.Symbols = cached.symbols
+		} else if entry.Language != "" && entry.Size <= maxParseSize {
+			if content, err := os.ReadFile(fullPath); err == nil {
+				entry.Symbols = ParseSymbols(rel, content)
+			}
+			c.files[rel] = cachedFile{size: entry.Size, modTime: entry.ModTime, symbols: entry.Symbols}
+		}
+
+		seen[rel] = true
+		m.Files = append(m.Files, entry)
+		return nil
+	})
+	if err != nil && err != ctx.Err() {
+		return nil, fmt.Errorf("failed to walk %s: %v", c.root, err)
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// Forget deleted files
+	for rel := range c.files {
+		if !seen[rel] {
+			delete(c.files, rel)
+		}
+	}
+
+	// Any added, removed or modified file invalidates rendered text
+	if sum := hash.Sum64(); sum != c.fingerprint {
+		c.fingerprint = sum
+		c.rendered = make(map[int]string)
+	}
+
+	sort.Slice(m.Files, func(i, j int) bool { return m.Files[i].Path < m.Files[j].Path })
+	return m, nil
+}
+
+// detail levels tried in order until the rendered map fits the budget
+const (
+	detailAllSymbols = iota
+	detailExportedSymbols
+	detailFilesOnly
+	detailDirectoriesOnly
+)
+
+// Render formats the map as an indented tree, dropping detail until it fits
+// within tokenBudget (estimated at four characters per token)
+func (m *Map) Render(tokenBudget int) string {
+	if tokenBudget <= 0 {
+		tokenBudget = DefaultTokenBudget
+	}
+
+	var text string
+	for level := detailAllSymbols; level <= detailDirectoriesOnly; level++ {
+		text = m.render(level)
+		if EstimateTokens(text) <= tokenBudget {
+			return text
+		}
+	}
+
+	// Even the directory outline is too large: cut it off
+	maxChars := tokenBudget * 4
+	if idx := strings.LastIndex(text[:maxChars], "\n"); idx > 0 {
+		text = text[:idx+1]
+	}
+	return text + "... (repository map truncated)\n"
+}
+
+func (m *Map) render(level int) string {
+	var b strings.Builder
+
+	if level == detailDirectoriesOnly {
+		counts := make(map[string]int)
+		var dirs []string
+		for _, f := range m.Files {
+			dir := path.Dir(f.Path)
+			if _, ok := counts[dir]; !ok {
+				dirs = append(dirs, dir)
+			}
+			counts[dir]++
+		}
+		sort.Strings(dirs)
+		for _, dir := range dirs {
+			fmt.Fprintf(&b, "%s/ (%d files)\n", dir, counts[dir])
+		}
+		return b.String()
+	}
+
+	var prevDirs []string
+	for _, f := range m.Files {
+		dir := path.Dir(f.Path)
+		var dirs []string
+		if dir != "." {
+			dirs = strings.Split(dir, "/")
+		}
+
+		// Print directory headers that differ from the previous file
+		common := 0
+		for common < len(dirs) && common < len(prevDirs) && dirs[common] == prevDirs[common] {
+			common++
+		}
+		for i := common; i < len(dirs); i++ {
+			fmt.Fprintf(&b, "%s%s/\n", strings.Repeat("  ", i), dirs[i])
+		}
+		prevDirs = dirs
+
+		b.WriteString(strings.Repeat("  ", len(dirs)))
+		b.WriteString(path.Base(f.Path))
+		if level != detailFilesOnly {
+			if names := symbolNames(f.Symbols, level == detailExportedSymbols); len(names) > 0 {
+				b.WriteString(": ")
+				b.WriteString(strings.Join(names, ", "))
+			}
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// symbolNames formats symbols compactly; functions and methods get a "()" suffix
+func symbolNames(symbols []Symbol, exportedOnly bool) []string {
+	var names []string
+	for _, s := range symbols {
+		if exportedOnly && !s.Exported {
+			continue
+		}
+		if len(names) >= maxSymbolsPerFile {
+			names = append(names, "...")
+			break
+		}
+		name := s.Name
+		switch s.Kind {
+		case "func", "method":
+			name += "()"
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+// EstimateTokens approximates the token count of text
+func EstimateTokens(text string) int {
+	return (len(text) + 3) / 4
+}
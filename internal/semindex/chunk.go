@@ -0,0 +1,88 @@
+package semindex
+
+import (
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// chunkLines is the target size of a chunk in lines
+	chunkLines = 60
+	// chunkOverlap is how many lines consecutive chunks share
+	chunkOverlap = 10
+	// maxChunkChars bounds the text sent to the embedder per chunk
+	maxChunkChars = 4000
+)
+
+// indexedExts lists the file types worth indexing
+var indexedExts = map[string]bool{
+	".go": true, ".py": true, ".js": true, ".jsx": true, ".mjs": true, ".ts": true, ".tsx": true,
+	".rs": true, ".java": true, ".kt": true, ".cs": true, ".c": true, ".h": true, ".cc": true,
+	".cpp": true, ".hpp": true, ".rb": true, ".php": true, ".swift": true, ".scala": true,
+	".sh": true, ".sql": true, ".proto": true, ".vue": true, ".svelte": true, ".css": true,
+	".html": true, ".md": true, ".txt": true, ".yaml": true, ".yml": true, ".toml": true,
+}
+
+// Chunk is an embedded excerpt of a file
+type Chunk struct {
+	Path      string
+	StartLine int
+	EndLine   int
+	Text      string
+	Vector    []float32
+}
+
+// IsIndexable reports whether a file type is indexed
+func IsIndexable(name string) bool {
+	return indexedExts[strings.ToLower(filepath.Ext(name))]
+}
+
+// ChunkFile splits file content into overlapping line windows. Blank-only
+// windows are dropped.
+func ChunkFile(path, content string) []Chunk {
+	lines := strings.Split(content, "\n")
+
+	var chunks []Chunk
+	for start := 0; start < len(lines); start += chunkLines - chunkOverlap {
+		end := start + chunkLines
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		text := strings.Join(lines[start:end], "\n")
+		if len(text) > maxChunkChars {
+			text = truncateRunes(text, maxChunkChars)
+		}
+		if strings.TrimSpace(text) != "" {
+			chunks = append(chunks, Chunk{
+				Path:      path,
+				StartLine: start + 1,
+				EndLine:   end,
+				Text:      text,
+			})
+		}
+
+		if end == len(lines) {
+			break
+		}
+	}
+	return chunks
+}
+
+// embeddingInput prefixes chunk text with its path, which carries a lot of meaning
+func embeddingInput(c Chunk) string {
+	return "File: " + c.Path + "\n" + c.Text
+}
+
+// truncateRunes cuts text to at most n bytes without splitting a UTF-8
+// sequence
+func truncateRunes(text string, n int) string {
+	if len(text) <= n {
+		return text
+	}
+	for n > 0 && !utf8.RuneStart(text[n]) {
+		n--
+	}
+	return text[:n]
+}
@@ -0,0 +1,107 @@
+package semindex
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+// numberedLines returns n lines "line 1" to "line n" without a final newline
+func numberedLines(n int) string {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = fmt.Sprintf("line %d", i+1)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func TestChunkFileWindows(t *testing.T) {
+	chunks := ChunkFile("a.go", numberedLines(130))
+
+	want := [][2]int{{1, 60}, {51, 110}, {101, 130}}
+	if len(chunks) != len(want) {
+		t.Fatalf("got %d chunks, want %d", len(chunks), len(want))
+	}
+	for i, c := range chunks {
+		if c.StartLine != want[i][0] || c.EndLine != want[i][1] {
+			t.Errorf("chunk %d covers lines %d-%d, want %d-%d", i, c.StartLine, c.EndLine, want[i][0], want[i][1])
+		}
+		if c.Path != "a.go" {
+			t.Errorf("chunk %d has path %q", i, c.Path)
+		}
+		first := fmt.Sprintf("line %d\n", c.StartLine)
+		last := fmt.Sprintf("\nline %d", c.EndLine)
+		if !strings.HasPrefix(c.Text, first) || !strings.HasSuffix(c.Text, last) {
+			t.Errorf("chunk %d text does not match its lines: %.40q...", i, c.Text)
+		}
+	}
+}
+
+func TestChunkFileShortFile(t *testing.T) {
+	chunks := ChunkFile("a.go", "package a\n")
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	if chunks[0].StartLine != 1 || chunks[0].EndLine != 2 {
+		t.Errorf("chunk covers lines %d-%d, want 1-2", chunks[0].StartLine, chunks[0].EndLine)
+	}
+}
+
+func TestChunkFileDropsBlankWindows(t *testing.T) {
+	if chunks := ChunkFile("a.go", ""); len(chunks) != 0 {
+		t.Errorf("empty file: got %d chunks, want 0", len(chunks))
+	}
+
+	content := strings.Repeat("\n", 120) + "func main() {}"
+	chunks := ChunkFile("a.go", content)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want only the window with code", len(chunks))
+	}
+	if !strings.Contains(chunks[0].Text, "func main") {
+		t.Errorf("kept the wrong window: %q", chunks[0].Text)
+	}
+}
+
+func TestChunkFileCapsText(t *testing.T) {
+	content := strings.Repeat("x", maxChunkChars*2)
+	chunks := ChunkFile("a.txt", content)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	if len(chunks[0].Text) != maxChunkChars {
+		t.Errorf("chunk text is %d bytes, want %d", len(chunks[0].Text), maxChunkChars)
+	}
+}
+
+func TestChunkFileCapsTextAtRuneBoundary(t *testing.T) {
+	// "é" is two bytes, so maxChunkChars falls inside one after the "x"
+	content := "x" + strings.Repeat("é", maxChunkChars)
+	chunks := ChunkFile("a.txt", content)
+	if len(chunks) != 1 {
+		t.Fatalf("got %d chunks, want 1", len(chunks))
+	}
+	text := chunks[0].Text
+	if !utf8.ValidString(text) {
+		t.Errorf("chunk text ends in a split UTF-8 sequence: %q", text[len(text)-4:])
+	}
+	if len(text) != maxChunkChars-1 {
+		t.Errorf("chunk text is %d bytes, want %d", len(text), maxChunkChars-1)
+	}
+}
+
+func TestIsIndexable(t *testing.T) {
+	tests := map[string]bool{
+		"main.go":        true,
+		"README.md":      true,
+		"src/App.TSX":    true,
+		"image.png":      false,
+		"Makefile":       false,
+		"archive.tar.gz": false,
+	}
+	for name, want := range tests {
+		if got := IsIndexable(name); got != want {
+			t.Errorf("IsIndexable(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
@@ -0,0 +1,170 @@
+package semindex
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"math"
+	"strings"
+	"unicode"
+
+	"github.com/sashabaranov/go-openai"
+)
+
+// LocalEmbeddingModel selects the deterministic HashEmbedder instead of a remote endpoint
+const LocalEmbeddingModel = "local"
+
+// Embedder turns text into vectors
+type Embedder interface {
+	// Name identifies the embedding model; indexes built with a different name are rebuilt
+	Name() string
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// OpenAIEmbedder embeds text through an OpenAI-compatible /embeddings endpoint
+type OpenAIEmbedder struct {
+	client *openai.Client
+	model  string
+}
+
+// NewOpenAIEmbedder creates an embedder for the given client and model
+func NewOpenAIEmbedder(client *openai.Client, model string) *OpenAIEmbedder {
+	return &OpenAIEmbedder{client: client, model: model}
+}
+
+func (e *OpenAIEmbedder) Name() string {
+	return "openai:" + e.model
+}
+
+func (e *OpenAIEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	resp, err := e.client.CreateEmbeddings(ctx, openai.EmbeddingRequestStrings{
+		Input: texts,
+		Model: openai.EmbeddingModel(e.model),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("embeddings request failed: %w", err)
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings response has %d vectors for %d inputs", len(resp.Data), len(texts))
+	}
+
+	vectors := make([][]float32, len(texts))
+	for _, item := range resp.Data {
+		if item.Index < 0 || item.Index >= len(vectors) {
+			return nil, fmt.Errorf("embeddings response has out of range index %d", item.Index)
+		}
+		vectors[item.Index] = normalize(item.Embedding)
+	}
+	return vectors, nil
+}
+
+// HashEmbedder is a deterministic, offline embedder based on feature hashing of
+// identifier-aware tokens. It needs no network access, which makes it suitable
+// for tests and for machines without an embeddings endpoint, at the cost of only
+// capturing lexical similarity.
+type HashEmbedder struct {
+	Dims int
+}
+
+// NewHashEmbedder creates a HashEmbedder with the given dimensionality (default 256)
+func NewHashEmbedder(dims int) *HashEmbedder {
+	if dims <= 0 {
+		dims = 256
+	}
+	return &HashEmbedder{Dims: dims}
+}
+
+func (e *HashEmbedder) Name() string {
+	return fmt.Sprintf("hash:%d", e.Dims)
+}
+
+func (e *HashEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	vectors := make([][]float32, len(texts))
+	for i, text := range texts {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		vector := make([]float32, e.Dims)
+		tokens := tokenize(text)
+		for j, token := range tokens {
+			e.add(vector, token, 1)
+			// Adjacent pairs capture a little phrase structure
+			if j > 0 {
+				e.add(vector, tokens[j-1]+" "+token, 0.5)
+			}
+		}
+		vectors[i] = normalize(vector)
+	}
+	return vectors, nil
+}
+
+// add hashes a feature into a bucket with a hash-derived sign
+func (e *HashEmbedder) add(vector []float32, feature string, weight float32) {
+	h := fnv.New64a()
+	h.Write([]byte(feature))
+	sum := h.Sum64()
+	bucket := int(sum % uint64(e.Dims))
+	if sum&(1<<63) != 0 {
+		weight = -weight
+	}
+	vector[bucket] += weight
+}
+
+// tokenize lowercases text and splits it into words, breaking identifiers on
+// camelCase and snake_case boundaries so "retryUpload" matches "retry upload"
+func tokenize(text string) []string {
+	var tokens []string
+	var current []rune
+
+	flush := func() {
+		if len(current) > 1 {
+			tokens = append(tokens, strings.ToLower(string(current)))
+		}
+		current = current[:0]
+	}
+
+	runes := []rune(text)
+	for i, r := range runes {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// camelCase boundary: lower followed by upper
+			if unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]) {
+				flush()
+			}
+			current = append(current, r)
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// normalize scales a vector to unit length so dot products are cosine similarities
+func normalize(vector []float32) []float32 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	if sum == 0 {
+		return vector
+	}
+	norm := float32(math.Sqrt(sum))
+	for i := range vector {
+		vector[i] /= norm
+	}
+	return vector
+}
+
+// dot returns the dot product of two vectors of equal length
+func dot(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	var sum float32
+	for i := range a {
+		sum += a[i] * b[i]
+	}
+	return sum
+}
@@ -0,0 +1,343 @@
+package semindex
+
+import (
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/Rorical/RoriCode/internal/fsutil"
+)
+
+const (
+	// indexVersion is bumped whenever the on-disk format or chunking changes
+	indexVersion = 1
+	// maxIndexedFileSize skips large (usually generated) files
+	maxIndexedFileSize = 256 * 1024
+	// maxIndexedFiles bounds the walk on very large trees
+	maxIndexedFiles = 10000
+	// embedBatchSize is the number of chunks sent per embeddings request
+	embedBatchSize = 64
+)
+
+// IndexDir is the index location relative to the workspace root
+var IndexDir = filepath.Join(".roricode", "index")
+
+// fileRecord stores the chunks of one file with the stat data they were built from
+type fileRecord struct {
+	Size    int64
+	ModTime int64
+	Chunks  []Chunk
+}
+
+// indexData is the persisted index
+type indexData struct {
+	Version  int
+	Embedder string
+	Files    map[string]*fileRecord
+}
+
+// UpdateStats summarises an incremental update
+type UpdateStats struct {
+	Files   int `json:"files"`
+	Chunks  int `json:"chunks"`
+	Added   int `json:"added"`
+	Updated int `json:"updated"`
+	Removed int `json:"removed"`
+}
+
+// Result is a ranked search hit
+type Result struct {
+	Path      string  `json:"path"`
+	StartLine int     `json:"start_line"`
+	EndLine   int     `json:"end_line"`
+	Score     float32 `json:"score"`
+	Snippet   string  `json:"snippet"`
+}
+
+// Index is an embedding index of a workspace, persisted under IndexDir
+type Index struct {
+	mu       sync.Mutex
+	root     string
+	embedder Embedder
+	data     *indexData
+}
+
+// Open returns an index for root using the given embedder. The persisted index
+// is loaded lazily; an index built with a different embedder is discarded.
+func Open(root string, embedder Embedder) *Index {
+	return &Index{root: root, embedder: embedder}
+}
+
+// Path returns the file the index is persisted to
+func (ix *Index) Path() string {
+	return filepath.Join(ix.root, IndexDir, "index.gob")
+}
+
+// Update brings the index in line with the workspace, re-embedding only files
+// whose size or modification time changed
+func (ix *Index) Update(ctx context.Context) (UpdateStats, error) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.loadLocked()
+	var stats UpdateStats
+
+	type pendingFile struct {
+		rel    string
+		record *fileRecord
+		isNew  bool
+	}
+	var pending []pendingFile
+	seen := make(map[string]bool)
+	ignore := fsutil.NewIgnoreMatcher(ix.root, fsutil.DefaultIgnoreFiles)
+
+	err := filepath.WalkDir(ix.root, func(fullPath string, d fs.DirEntry, walkErr error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walkErr != nil {
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fullPath == ix.root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(ix.root, fullPath)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+
+		if fsutil.IsHidden(d.Name()) || ignore.Match(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			switch d.Name() {
+			case "node_modules", "vendor", "dist", "build", "target", "__pycache__":
+				return filepath.SkipDir
+			}
+			ignore.LoadDir(rel)
+			return nil
+		}
+		if !d.Type().IsRegular() || !IsIndexable(rel) || len(seen) >= maxIndexedFiles {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil || info.Size() > maxIndexedFileSize {
+			return nil
+		}
+		seen[rel] = true
+
+		existing, ok := ix.data.Files[rel]
+		if ok && existing.Size == info.Size() && existing.ModTime == info.ModTime().UnixNano() {
+			return nil
+		}
+
+		content, err := os.ReadFile(fullPath)
+		if err != nil {
+			return nil
+		}
+		pending = append(pending, pendingFile{
+			rel: rel,
+			record: &fileRecord{
+				Size:    info.Size(),
+				ModTime: info.ModTime().UnixNano(),
+				Chunks:  ChunkFile(rel, string(content)),
+			},
+			isNew: !ok,
+		})
+		return nil
+	})
+	if err != nil {
+		return stats, fmt.Errorf("failed to scan workspace: %v", err)
+	}
+
+	// Embed the new chunks in batches. A file's record is committed once all
+	// of its chunks have vectors, so when a batch fails the files embedded
+	// before it are kept and need not be embedded again.
+	var queue []*Chunk
+	ends := make([]int, len(pending)) // Queue position after each file's chunks
+	for i, p := range pending {
+		for j := range p.record.Chunks {
+			queue = append(queue, &p.record.Chunks[j])
+		}
+		ends[i] = len(queue)
+	}
+	committed := 0
+	commit := func(embedded int) {
+		for ; committed < len(pending) && ends[committed] <= embedded; committed++ {
+			p := pending[committed]
+			ix.data.Files[p.rel] = p.record
+			if p.isNew {
+				stats.Added++
+			} else {
+				stats.Updated++
+			}
+		}
+	}
+	commit(0)
+	for start := 0; start < len(queue); start += embedBatchSize {
+		end := start + embedBatchSize
+		if end > len(queue) {
+			end = len(queue)
+		}
+
+		inputs := make([]string, 0, end-start)
+		for _, c := range queue[start:end] {
+			inputs = append(inputs, embeddingInput(*c))
+		}
+		vectors, err := ix.embedder.Embed(ctx, inputs)
+		if err == nil && len(vectors) != len(inputs) {
+			err = fmt.Errorf("embedder returned %d vectors for %d inputs", len(vectors), len(inputs))
+		}
+		if err != nil {
+			if committed > 0 {
+				if saveErr := ix.saveLocked(); saveErr != nil {
+					return stats, fmt.Errorf("%v; %v", err, saveErr)
+				}
+			}
+			return stats, err
+		}
+		for i, c := range queue[start:end] {
+			c.Vector = vectors[i]
+		}
+		commit(end)
+	}
+
+	for rel := range ix.data.Files {
+		if !seen[rel] {
+			delete(ix.data.Files, rel)
+			stats.Removed++
+		}
+	}
+
+	stats.Files = len(ix.data.Files)
+	for _, record := range ix.data.Files {
+		stats.Chunks += len(record.Chunks)
+	}
+
+	if stats.Added > 0 || stats.Updated > 0 || stats.Removed > 0 {
+		if err := ix.saveLocked(); err != nil {
+			return stats, err
+		}
+	}
+	return stats, nil
+}
+
+// Search returns the topK chunks most similar to query, optionally restricted to
+// paths under pathPrefix
+func (ix *Index) Search(ctx context.Context, query string, topK int, pathPrefix string) ([]Result, error) {
+	vectors, err := ix.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+	queryVector := vectors[0]
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.loadLocked()
+
+	pathPrefix = strings.TrimPrefix(filepath.ToSlash(pathPrefix), "./")
+	if pathPrefix == "." {
+		pathPrefix = ""
+	}
+
+	var results []Result
+	for rel, record := range ix.data.Files {
+		if pathPrefix != "" && rel != pathPrefix && !strings.HasPrefix(rel, strings.TrimSuffix(pathPrefix, "/")+"/") {
+			continue
+		}
+		for _, c := range record.Chunks {
+			results = append(results, Result{
+				Path:      c.Path,
+				StartLine: c.StartLine,
+				EndLine:   c.EndLine,
+				Score:     dot(queryVector, c.Vector),
+				Snippet:   c.Text,
+			})
+		}
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Score == results[j].Score {
+			if results[i].Path == results[j].Path {
+				return results[i].StartLine < results[j].StartLine
+			}
+			return results[i].Path < results[j].Path
+		}
+		return results[i].Score > results[j].Score
+	})
+	if topK > 0 && len(results) > topK {
+		results = results[:topK]
+	}
+	return results, nil
+}
+
+// loadLocked reads the persisted index, starting fresh if it is missing,
+// unreadable, from an older version or built with another embedder
+func (ix *Index) loadLocked() {
+	if ix.data != nil {
+		return
+	}
+
+	fresh := &indexData{
+		Version:  indexVersion,
+		Embedder: ix.embedder.Name(),
+		Files:    make(map[string]*fileRecord),
+	}
+
+	file, err := os.Open(ix.Path())
+	if err != nil {
+		ix.data = fresh
+		return
+	}
+	defer file.Close()
+
+	var data indexData
+	if err := gob.NewDecoder(file).Decode(&data); err != nil ||
+		data.Version != indexVersion || data.Embedder != ix.embedder.Name() || data.Files == nil {
+		ix.data = fresh
+		return
+	}
+	ix.data = &data
+}
+
+// saveLocked persists the index atomically
+func (ix *Index) saveLocked() error {
+	path := ix.Path()
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create index directory: %v", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), "index-*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to write index: %v", err)
+	}
+	if err := gob.NewEncoder(tmp).Encode(ix.data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to encode index: %v", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write index: %v", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to save index: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package semindex
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// writeFile creates a file under root, with its directories
+func writeFile(t *testing.T, root, rel, content string) {
+	t.Helper()
+	path := filepath.Join(root, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func update(t *testing.T, ix *Index) UpdateStats {
+	t.Helper()
+	stats, err := ix.Update(context.Background())
+	if err != nil {
+		t.Fatalf("Update failed: %v", err)
+	}
+	return stats
+}
+
+func checkStats(t *testing.T, got, want UpdateStats) {
+	t.Helper()
+	if got != want {
+		t.Errorf("got stats %+v, want %+v", got, want)
+	}
+}
+
+func TestUpdateIsIncremental(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "main.go", "package main\n\nfunc main() {}\n")
+	writeFile(t, root, "docs/guide.md", "# Guide\n\nHow to use it.\n")
+	writeFile(t, root, "logo.png", "not indexed")
+	writeFile(t, root, ".hidden/secret.go", "package hidden\n")
+	writeFile(t, root, "node_modules/lib/index.js", "module.exports = {}\n")
+	writeFile(t, root, "generated.go", "package main\n")
+	writeFile(t, root, ".gitignore", "generated.go\n")
+
+	ix := Open(root, NewHashEmbedder(64))
+	checkStats(t, update(t, ix), UpdateStats{Files: 2, Chunks: 2, Added: 2})
+
+	// Nothing changed
+	checkStats(t, update(t, ix), UpdateStats{Files: 2, Chunks: 2})
+
+	// A changed file is embedded again, a deleted one dropped
+	writeFile(t, root, "main.go", "package main\n\nfunc main() {\n\tprintln(\"hi\")\n}\n")
+	if err := os.Remove(filepath.Join(root, "docs", "guide.md")); err != nil {
+		t.Fatal(err)
+	}
+	checkStats(t, update(t, ix), UpdateStats{Files: 1, Chunks: 1, Updated: 1, Removed: 1})
+}
+
+func TestUpdateDetectsModTimeChange(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n")
+	ix := Open(root, NewHashEmbedder(64))
+	update(t, ix)
+
+	// Same size, new content
+	writeFile(t, root, "a.go", "package b\n")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(filepath.Join(root, "a.go"), later, later); err != nil {
+		t.Fatal(err)
+	}
+	checkStats(t, update(t, ix), UpdateStats{Files: 1, Chunks: 1, Updated: 1})
+}
+
+func TestIndexIsPersisted(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "a.go", "package a\n")
+	writeFile(t, root, "b.go", "package b\n")
+	update(t, Open(root, NewHashEmbedder(64)))
+
+	if _, err := os.Stat(filepath.Join(root, IndexDir, "index.gob")); err != nil {
+		t.Fatalf("index not saved: %v", err)
+	}
+
+	// A new index loads the saved one and finds nothing to do
+	checkStats(t, update(t, Open(root, NewHashEmbedder(64))), UpdateStats{Files: 2, Chunks: 2})
+
+	// A different embedder rebuilds it
+	checkStats(t, update(t, Open(root, NewHashEmbedder(32))), UpdateStats{Files: 2, Chunks: 2, Added: 2})
+}
+
+// failingEmbedder fails every call after the first ok calls
+type failingEmbedder struct {
+	*HashEmbedder
+	ok    int
+	calls int
+}
+
+var errEmbed = errors.New("embeddings endpoint unavailable")
+
+func (e *failingEmbedder) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	e.calls++
+	if e.calls > e.ok {
+		return nil, errEmbed
+	}
+	return e.HashEmbedder.Embed(ctx, texts)
+}
+
+func TestUpdateKeepsEmbeddedFilesWhenABatchFails(t *testing.T) {
+	root := t.TempDir()
+	// 30 files of 3 chunks: the first batch of 64 chunks completes 21 files
+	for i := 0; i < 30; i++ {
+		writeFile(t, root, fmt.Sprintf("f%02d.go", i), numberedLines(130))
+	}
+	complete := embedBatchSize / 3
+
+	embedder := &failingEmbedder{HashEmbedder: NewHashEmbedder(64), ok: 1}
+	stats, err := Open(root, embedder).Update(context.Background())
+	if !errors.Is(err, errEmbed) {
+		t.Fatalf("got error %v, want %v", err, errEmbed)
+	}
+	if stats.Added != complete {
+		t.Errorf("added %d files before the failure, want %d", stats.Added, complete)
+	}
+
+	// The completed files were saved and are not embedded again
+	ix := Open(root, NewHashEmbedder(64))
+	checkStats(t, update(t, ix), UpdateStats{Files: 30, Chunks: 90, Added: 30 - complete})
+
+	results, err := ix.Search(context.Background(), "line 1", 0, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, r := range results {
+		if r.Score == 0 {
+			t.Fatalf("chunk %s:%d has no vector", r.Path, r.StartLine)
+		}
+	}
+}
+
+func TestSearchRanksRelevantChunksFirst(t *testing.T) {
+	root := t.TempDir()
+	writeFile(t, root, "upload/retry.go", `package upload
+
+// retryUpload sends the file again with exponential backoff
+func retryUpload(file string, attempts int) error {
+	return backoff(attempts, func() error { return send(file) })
+}
+`)
+	writeFile(t, root, "auth/login.go", `package auth
+
+// checkPassword compares a password with its stored hash
+func checkPassword(password, hash string) bool {
+	return compareHash(hash, password)
+}
+`)
+	writeFile(t, root, "render/color.go", `package render
+
+// parseColor reads a hex color such as #ff8800
+func parseColor(hex string) (r, g, b uint8) {
+	return decodeHex(hex)
+}
+`)
+
+	ix := Open(root, NewHashEmbedder(256))
+	update(t, ix)
+	ctx := context.Background()
+
+	queries := map[string]string{
+		"retry upload with backoff": "upload/retry.go",
+		"check the password hash":   "auth/login.go",
+		"parse hex color":           "render/color.go",
+	}
+	for query, want := range queries {
+		results, err := ix.Search(ctx, query, 3, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 3 {
+			t.Fatalf("%q: got %d results, want 3", query, len(results))
+		}
+		if results[0].Path != want {
+			t.Errorf("%q: top result is %s, want %s", query, results[0].Path, want)
+		}
+		for i := 1; i < len(results); i++ {
+			if results[i].Score > results[i-1].Score {
+				t.Errorf("%q: results are not sorted by score", query)
+			}
+		}
+	}
+
+	results, err := ix.Search(ctx, "retry upload with backoff", 0, "auth/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Path != "auth/login.go" {
+		t.Errorf("path prefix auth/: got %+v, want only auth/login.go", results)
+	}
+
+	results, err = ix.Search(ctx, "retry upload with backoff", 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 {
+		t.Errorf("topK 1: got %d results", len(results))
+	}
+}
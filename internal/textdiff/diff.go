@@ -0,0 +1,244 @@
+// Package textdiff computes line and word diffs and renders them as unified
+// or side-by-side text
+package textdiff
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Op is the kind of an edit
+type Op int
+
+const (
+	Equal Op = iota
+	Delete
+	Insert
+)
+
+// Edit is one element of an edit script. OldIndex is set for Equal and
+// Delete, NewIndex for Equal and Insert; the other is -1.
+type Edit struct {
+	Op       Op
+	OldIndex int
+	NewIndex int
+}
+
+// maxEditCost bounds the work done on very different inputs; past it the
+// remaining difference is reported as a plain delete and insert
+const maxEditCost = 4000
+
+// Diff returns a minimal edit script turning a into b, comparing elements for
+// equality (Myers' O(ND) algorithm after trimming the common prefix and suffix)
+func Diff(a, b []string) []Edit {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	edits := make([]Edit, 0, len(a)+len(b))
+	for i := 0; i < prefix; i++ {
+		edits = append(edits, Edit{Op: Equal, OldIndex: i, NewIndex: i})
+	}
+
+	middle := myers(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])
+	for _, e := range middle {
+		if e.OldIndex >= 0 {
+			e.OldIndex += prefix
+		}
+		if e.NewIndex >= 0 {
+			e.NewIndex += prefix
+		}
+		edits = append(edits, e)
+	}
+
+	for i := 0; i < suffix; i++ {
+		edits = append(edits, Edit{Op: Equal, OldIndex: len(a) - suffix + i, NewIndex: len(b) - suffix + i})
+	}
+	return edits
+}
+
+// myers computes the edit script for a and b
+func myers(a, b []string) []Edit {
+	n, m := len(a), len(b)
+	if n == 0 || m == 0 {
+		return replaceAll(0, 0, n, m)
+	}
+
+	max := n + m
+	if max > maxEditCost {
+		max = maxEditCost
+	}
+	offset := max + 1
+	v := make([]int, 2*max+3)
+	// trace[d] holds v[k] for k in [-d, d] after step d
+	var trace [][]int
+
+	found := false
+	for d := 0; d <= max && !found; d++ {
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Step down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // Step right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break
+			}
+		}
+		snapshot := make([]int, 2*d+1)
+		copy(snapshot, v[offset-d:offset+d+1])
+		trace = append(trace, snapshot)
+	}
+
+	if !found {
+		return replaceAll(0, 0, n, m)
+	}
+
+	// Walk the trace backwards to recover the path
+	var reversed []Edit
+	x, y := n, m
+	for d := len(trace) - 1; d > 0; d-- {
+		prev := trace[d-1]
+		at := func(k int) int { return prev[k+(d-1)] }
+
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && at(k-1) < at(k+1)) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := at(prevK)
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			x--
+			y--
+			reversed = append(reversed, Edit{Op: Equal, OldIndex: x, NewIndex: y})
+		}
+		if x == prevX {
+			y--
+			reversed = append(reversed, Edit{Op: Insert, OldIndex: -1, NewIndex: y})
+		} else {
+			x--
+			reversed = append(reversed, Edit{Op: Delete, OldIndex: x, NewIndex: -1})
+		}
+	}
+	for x > 0 && y > 0 {
+		x--
+		y--
+		reversed = append(reversed, Edit{Op: Equal, OldIndex: x, NewIndex: y})
+	}
+
+	edits := make([]Edit, len(reversed))
+	for i, e := range reversed {
+		edits[len(reversed)-1-i] = e
+	}
+	return edits
+}
+
+// replaceAll deletes a[oldStart:oldEnd] and inserts b[newStart:newEnd]
+func replaceAll(oldStart, newStart, oldEnd, newEnd int) []Edit {
+	var edits []Edit
+	for i := oldStart; i < oldEnd; i++ {
+		edits = append(edits, Edit{Op: Delete, OldIndex: i, NewIndex: -1})
+	}
+	for j := newStart; j < newEnd; j++ {
+		edits = append(edits, Edit{Op: Insert, OldIndex: -1, NewIndex: j})
+	}
+	return edits
+}
+
+// SplitLines splits text into lines without their terminators. A final
+// newline does not produce an empty last line.
+func SplitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	lines := strings.Split(text, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// normalizeWhitespace collapses runs of whitespace and trims the ends, for
+// comparisons that ignore whitespace changes
+func normalizeWhitespace(line string) string {
+	return strings.Join(strings.Fields(line), " ")
+}
+
+// splitWords tokenises a line into words, whitespace runs and single
+// punctuation characters, so that joining the tokens restores the line
+func splitWords(line string) []string {
+	var tokens []string
+	runes := []rune(line)
+	for i := 0; i < len(runes); {
+		j := i + 1
+		switch {
+		case unicode.IsSpace(runes[i]):
+			for j < len(runes) && unicode.IsSpace(runes[j]) {
+				j++
+			}
+		case isWordRune(runes[i]):
+			for j < len(runes) && isWordRune(runes[j]) {
+				j++
+			}
+		}
+		tokens = append(tokens, string(runes[i:j]))
+		i = j
+	}
+	return tokens
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// WordDiff marks the differences between two versions of a line inline, using
+// [-removed-] and {+added+} markers
+func WordDiff(oldLine, newLine string) string {
+	a, b := splitWords(oldLine), splitWords(newLine)
+	edits := Diff(a, b)
+
+	var out strings.Builder
+	var deleted, inserted strings.Builder
+	flush := func() {
+		if deleted.Len() > 0 {
+			out.WriteString("[-" + deleted.String() + "-]")
+			deleted.Reset()
+		}
+		if inserted.Len() > 0 {
+			out.WriteString("{+" + inserted.String() + "+}")
+			inserted.Reset()
+		}
+	}
+
+	for _, e := range edits {
+		switch e.Op {
+		case Equal:
+			flush()
+			out.WriteString(a[e.OldIndex])
+		case Delete:
+			deleted.WriteString(a[e.OldIndex])
+		case Insert:
+			inserted.WriteString(b[e.NewIndex])
+		}
+	}
+	flush()
+	return out.String()
+}
@@ -0,0 +1,304 @@
+package textdiff
+
+import (
+	"fmt"
+	"strings"
+	"unicode/utf8"
+)
+
+// Options controls how a diff is computed
+type Options struct {
+	Context          int  // Unchanged lines shown around each change
+	IgnoreWhitespace bool // Treat lines differing only in whitespace as equal
+}
+
+// Line is one line of a hunk. Kind is ' ' for context, '-' for a removed line
+// and '+' for an added line; OldLine and NewLine are 1-based, 0 when absent.
+type Line struct {
+	Kind    byte
+	Text    string
+	OldLine int
+	NewLine int
+}
+
+// Hunk is a group of nearby changes with surrounding context
+type Hunk struct {
+	OldStart  int
+	OldLines  int
+	NewStart  int
+	NewLines  int
+	Additions int
+	Deletions int
+	Lines     []Line
+}
+
+// Result is a computed line diff
+type Result struct {
+	Hunks     []Hunk
+	Additions int
+	Deletions int
+}
+
+// Lines diffs two texts line by line
+func Lines(oldText, newText string, opts Options) *Result {
+	oldLines, newLines := SplitLines(oldText), SplitLines(newText)
+	if opts.Context < 0 {
+		opts.Context = 0
+	}
+
+	oldKeys, newKeys := oldLines, newLines
+	if opts.IgnoreWhitespace {
+		oldKeys = make([]string, len(oldLines))
+		for i, l := range oldLines {
+			oldKeys[i] = normalizeWhitespace(l)
+		}
+		newKeys = make([]string, len(newLines))
+		for i, l := range newLines {
+			newKeys[i] = normalizeWhitespace(l)
+		}
+	}
+
+	edits := Diff(oldKeys, newKeys)
+	result := &Result{}
+
+	// Find the runs of edits that belong to each hunk: changes closer than
+	// twice the context are merged
+	i := 0
+	for i < len(edits) {
+		for i < len(edits) && edits[i].Op == Equal {
+			i++
+		}
+		if i == len(edits) {
+			break
+		}
+
+		start := i - opts.Context
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(edits) {
+			if edits[end].Op != Equal {
+				end++
+				continue
+			}
+			run := end
+			for run < len(edits) && edits[run].Op == Equal {
+				run++
+			}
+			if run == len(edits) || run-end > 2*opts.Context {
+				break
+			}
+			end = run
+		}
+		stop := end + opts.Context
+		if stop > len(edits) {
+			stop = len(edits)
+		}
+
+		oldBefore, newBefore := 0, 0
+		for _, e := range edits[:start] {
+			if e.OldIndex >= 0 {
+				oldBefore = e.OldIndex + 1
+			}
+			if e.NewIndex >= 0 {
+				newBefore = e.NewIndex + 1
+			}
+		}
+		result.Hunks = append(result.Hunks, buildHunk(edits[start:stop], oldLines, newLines, oldBefore, newBefore))
+		i = end
+	}
+
+	for _, h := range result.Hunks {
+		result.Additions += h.Additions
+		result.Deletions += h.Deletions
+	}
+	return result
+}
+
+// buildHunk converts a run of edits into a hunk; oldBefore and newBefore are
+// the numbers of lines on each side that precede it
+func buildHunk(edits []Edit, oldLines, newLines []string, oldBefore, newBefore int) Hunk {
+	var h Hunk
+	for _, e := range edits {
+		switch e.Op {
+		case Equal:
+			h.Lines = append(h.Lines, Line{Kind: ' ', Text: newLines[e.NewIndex], OldLine: e.OldIndex + 1, NewLine: e.NewIndex + 1})
+			h.OldLines++
+			h.NewLines++
+		case Delete:
+			h.Lines = append(h.Lines, Line{Kind: '-', Text: oldLines[e.OldIndex], OldLine: e.OldIndex + 1})
+			h.OldLines++
+			h.Deletions++
+		case Insert:
+			h.Lines = append(h.Lines, Line{Kind: '+', Text: newLines[e.NewIndex], NewLine: e.NewIndex + 1})
+			h.NewLines++
+			h.Additions++
+		}
+	}
+
+	// Start positions follow the unified format: the line before the hunk
+	// when it covers no lines of that side
+	h.OldStart, h.NewStart = oldBefore, newBefore
+	if h.OldLines > 0 {
+		h.OldStart++
+	}
+	if h.NewLines > 0 {
+		h.NewStart++
+	}
+	return h
+}
+
+// Header returns the @@ line of a hunk
+func (h Hunk) Header() string {
+	return fmt.Sprintf("@@ -%s +%s @@", hunkRange(h.OldStart, h.OldLines), hunkRange(h.NewStart, h.NewLines))
+}
+
+func hunkRange(start, count int) string {
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+// Unified renders the diff in unified format. With words set, removed lines
+// directly followed by added lines are shown as single "~" lines with inline
+// [-removed-] and {+added+} markers.
+func (r *Result) Unified(oldName, newName string, words bool) string {
+	if len(r.Hunks) == 0 {
+		return ""
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+	for _, h := range r.Hunks {
+		b.WriteString(h.Header())
+		b.WriteByte('\n')
+		if !words {
+			for _, l := range h.Lines {
+				b.WriteByte(l.Kind)
+				b.WriteString(l.Text)
+				b.WriteByte('\n')
+			}
+			continue
+		}
+		for _, block := range changeBlocks(h.Lines) {
+			for _, l := range block.context {
+				b.WriteString(" " + l.Text + "\n")
+			}
+			for i := 0; i < len(block.removed) || i < len(block.added); i++ {
+				switch {
+				case i < len(block.removed) && i < len(block.added):
+					b.WriteString("~" + WordDiff(block.removed[i].Text, block.added[i].Text) + "\n")
+				case i < len(block.removed):
+					b.WriteString("-" + block.removed[i].Text + "\n")
+				default:
+					b.WriteString("+" + block.added[i].Text + "\n")
+				}
+			}
+		}
+	}
+	return b.String()
+}
+
+// SideBySide renders the diff as two columns, old on the left and new on the
+// right, within the given total width
+func (r *Result) SideBySide(width int) string {
+	if len(r.Hunks) == 0 {
+		return ""
+	}
+	if width < 40 {
+		width = 40
+	}
+	// Each side: 5-digit line number, space, text; separated by " | " or
+	// a change marker
+	column := (width - 3) / 2
+	textWidth := column - 6
+
+	cell := func(num int, text string) string {
+		if num == 0 {
+			return strings.Repeat(" ", column)
+		}
+		return fmt.Sprintf("%5d %s", num, fitWidth(text, textWidth))
+	}
+
+	var b strings.Builder
+	for _, h := range r.Hunks {
+		b.WriteString(h.Header())
+		b.WriteByte('\n')
+		for _, block := range changeBlocks(h.Lines) {
+			for _, l := range block.context {
+				b.WriteString(strings.TrimRight(cell(l.OldLine, l.Text)+" | "+cell(l.NewLine, l.Text), " "))
+				b.WriteByte('\n')
+			}
+			for i := 0; i < len(block.removed) || i < len(block.added); i++ {
+				var left, right, marker string
+				switch {
+				case i < len(block.removed) && i < len(block.added):
+					left = cell(block.removed[i].OldLine, block.removed[i].Text)
+					right = cell(block.added[i].NewLine, block.added[i].Text)
+					marker = " ~ "
+				case i < len(block.removed):
+					left = cell(block.removed[i].OldLine, block.removed[i].Text)
+					right = cell(0, "")
+					marker = " < "
+				default:
+					left = cell(0, "")
+					right = cell(block.added[i].NewLine, block.added[i].Text)
+					marker = " > "
+				}
+				b.WriteString(strings.TrimRight(left+marker+right, " "))
+				b.WriteByte('\n')
+			}
+		}
+	}
+	return b.String()
+}
+
+// changeBlock is a run of context lines followed by the removed and added
+// lines that come after it
+type changeBlock struct {
+	context []Line
+	removed []Line
+	added   []Line
+}
+
+// changeBlocks groups hunk lines so that removals can be paired with the
+// additions that replace them
+func changeBlocks(lines []Line) []changeBlock {
+	var blocks []changeBlock
+	var cur changeBlock
+	for _, l := range lines {
+		if l.Kind == ' ' && (len(cur.removed) > 0 || len(cur.added) > 0) {
+			blocks = append(blocks, cur)
+			cur = changeBlock{}
+		}
+		switch l.Kind {
+		case ' ':
+			cur.context = append(cur.context, l)
+		case '-':
+			if len(cur.added) > 0 {
+				blocks = append(blocks, cur)
+				cur = changeBlock{}
+			}
+			cur.removed = append(cur.removed, l)
+		case '+':
+			cur.added = append(cur.added, l)
+		}
+	}
+	if len(cur.context) > 0 || len(cur.removed) > 0 || len(cur.added) > 0 {
+		blocks = append(blocks, cur)
+	}
+	return blocks
+}
+
+// fitWidth pads or truncates text to exactly width runes, expanding tabs
+func fitWidth(text string, width int) string {
+	text = strings.ReplaceAll(text, "\t", "    ")
+	n := utf8.RuneCountInString(text)
+	if n > width {
+		runes := []rune(text)
+		return string(runes[:width-1]) + "…"
+	}
+	return text + strings.Repeat(" ", width-n)
+}
@@ -1,9 +1,56 @@
 package tools
 
+import (
+	"sort"
+
+	"github.com/Rorical/RoriCode/internal/config"
+	"github.com/Rorical/RoriCode/internal/lsp"
+	"github.com/Rorical/RoriCode/internal/provider"
+	"github.com/Rorical/RoriCode/internal/semindex"
+)
+
 // RegisterBuiltinTools registers all builtin tools to a registry
-func RegisterBuiltinTools(registry *Registry) {
+func RegisterBuiltinTools(registry *Registry, cfg *config.Config) {
+	// Oversized output is capped and spilled to files for read_output
+	limits := cfg.GetToolOutputLimits()
+	registry.OutputStore().SetLimits(OutputLimits{
+		MaxBytes:  limits.MaxBytes,
+		HeadBytes: limits.HeadBytes,
+		TailBytes: limits.TailBytes,
+	})
+	registry.AddCloser(registry.OutputStore())
+
+	// Network tools may only reach the hosts the egress policy allows
+	egress := cfg.GetEgressConfig()
+	registry.EgressPolicy().SetRules(EgressRules{
+		AllowHosts:        egress.AllowHosts,
+		DenyHosts:         egress.DenyHosts,
+		AllowPrivateHosts: egress.AllowPrivateHosts,
+		MaxResponseBytes:  egress.MaxResponseBytes,
+		MaxRedirects:      egress.MaxRedirects,
+	})
+
+	// Named credentials are added to requests without the model seeing them
+	var creds []Credential
+	for name, cred := range cfg.Credentials {
+		creds = append(creds, Credential{
+			Name:      name,
+			Type:      cred.Type,
+			Hosts:     cred.Hosts,
+			Header:    cred.Header,
+			Param:     cred.Param,
+			Username:  cred.Username,
+			ValueEnv:  cred.ValueEnv,
+			ValueFile: cred.ValueFile,
+			AllowHTTP: cred.AllowHTTP,
+		})
+	}
+	registry.Credentials().Set(creds)
+
 	// Basic tools
-	registry.Register(&ShellTool{})
+	shellTool := &ShellTool{}
+	registry.Register(shellTool)
+	registry.AddCloser(shellTool)
 	registry.Register(&CurrentTimeTool{})
 	
 	// File operations
@@ -14,12 +61,29 @@ func RegisterBuiltinTools(registry *Registry) {
 	registry.Register(&FileSearchReplaceTool{})
 	registry.Register(&FileInsertTool{})
 	registry.Register(&FileManageTool{})
+	registry.Register(&FindFilesTool{})
+	registry.Register(&FileDiffTool{})
+	registry.Register(&ReadOutputTool{})
 	
 	// Directory operations
 	registry.Register(&DirectoryManageTool{})
 	
 	// Development tools
 	registry.Register(&CodeFormatterTool{})
+	registry.Register(&GoNavTool{})
+	registry.Register(&RepoMapTool{})
+	registry.Register(&SemanticSearchTool{embedder: newEmbedder(cfg)})
+	registry.Register(&RunTestsTool{})
+	registry.Register(&DiagnosticsTool{})
+	
+	// Language server tools share one set of servers, kept in sync with file edits
+	lspManager := lsp.NewManager(lspServers(cfg))
+	registry.ChangeTracker().OnChange(lspManager.NotifyChange)
+	registry.AddCloser(lspManager)
+	registry.Register(&LspDiagnosticsTool{lspTool{manager: lspManager}})
+	registry.Register(&LspDefinitionTool{lspTool{manager: lspManager}})
+	registry.Register(&LspReferencesTool{lspTool{manager: lspManager}})
+	registry.Register(&LspHoverTool{lspTool{manager: lspManager}})
 	
 	// Data tools
 	registry.Register(&DataEditTool{})
@@ -27,9 +91,66 @@ func RegisterBuiltinTools(registry *Registry) {
 	
 	// Network tools
 	registry.Register(&HttpRequestTool{})
+	registry.Register(&FetchURLTool{})
 	
-	// System tools (to be implemented in separate files)
-	// registry.Register(&FileDiffTool{})
-	// registry.Register(&EnvManageTool{})
-	// registry.Register(&ProcessManageTool{})
-}
\ No newline at end of file
+	// System tools
+	processManager := &ProcessManageTool{}
+	registry.Register(processManager)
+	registry.AddCloser(processManager)
+	registry.Register(&EnvManageTool{})
+}
+
+// newEmbedder creates the embedder for semantic search from the active profile.
+// Returns nil when no embeddings endpoint is available.
+func newEmbedder(cfg *config.Config) semindex.Embedder {
+	if cfg.GetEmbeddingModel() == semindex.LocalEmbeddingModel {
+		return semindex.NewHashEmbedder(0)
+	}
+	if !cfg.IsValid() {
+		return nil
+	}
+	apiKey := cfg.GetAPIKey()
+	if apiKey == "" {
+		return nil
+	}
+	// The profile's organization, project and headers apply to embeddings too
+	profile := cfg.CurrentProfile()
+	profile.BaseURL = cfg.GetEmbeddingBaseURL()
+	return semindex.NewOpenAIEmbedder(provider.NewClient(profile, apiKey), cfg.GetEmbeddingModel())
+}
+
+// lspServers merges the configured language servers over the defaults.
+// Entries for new languages need extensions to be used.
+func lspServers(cfg *config.Config) []lsp.ServerConfig {
+	servers := lsp.DefaultServers()
+
+	languages := make([]string, 0, len(cfg.LSPServers))
+	for language := range cfg.LSPServers {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	for _, language := range languages {
+		override := cfg.LSPServers[language]
+		replaced := false
+		for i := range servers {
+			if servers[i].Language == language {
+				if len(override.Command) > 0 {
+					servers[i].Command = override.Command
+				}
+				if len(override.Extensions) > 0 {
+					servers[i].Extensions = override.Extensions
+				}
+				replaced = true
+			}
+		}
+		if !replaced && len(override.Command) > 0 && len(override.Extensions) > 0 {
+			servers = append(servers, lsp.ServerConfig{
+				Language:   language,
+				Command:    override.Command,
+				Extensions: override.Extensions,
+			})
+		}
+	}
+	return servers
+}
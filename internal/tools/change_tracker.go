@@ -0,0 +1,84 @@
+package tools
+
+import (
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// ChangeTracker records the files modified by tools since the last reset.
+// The core resets it at the start of every user message, so it holds the
+// files changed during the current turn.
+type ChangeTracker struct {
+	mu        sync.Mutex
+	files     map[string]bool // Absolute paths
+	listeners []func(path string)
+}
+
+// ChangeTrackingTool is a tool that reports the files it modifies
+type ChangeTrackingTool interface {
+	Tool
+	SetChangeTracker(tracker *ChangeTracker)
+}
+
+// NewChangeTracker creates an empty change tracker
+func NewChangeTracker() *ChangeTracker {
+	return &ChangeTracker{files: make(map[string]bool)}
+}
+
+// Record marks paths as changed. It is safe to call on a nil tracker.
+func (c *ChangeTracker) Record(paths ...string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	var recorded []string
+	for _, path := range paths {
+		if abs, err := filepath.Abs(path); err == nil {
+			c.files[abs] = true
+			recorded = append(recorded, abs)
+		}
+	}
+	listeners := c.listeners
+	c.mu.Unlock()
+
+	for _, path := range recorded {
+		for _, listener := range listeners {
+			listener(path)
+		}
+	}
+}
+
+// OnChange registers a function called with the absolute path of every
+// recorded change
+func (c *ChangeTracker) OnChange(listener func(path string)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+// Changed returns the absolute paths changed since the last reset, sorted
+func (c *ChangeTracker) Changed() []string {
+	if c == nil {
+		return nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	files := make([]string, 0, len(c.files))
+	for path := range c.files {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// Reset forgets all recorded changes
+func (c *ChangeTracker) Reset() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.files = make(map[string]bool)
+}
@@ -12,6 +12,8 @@ import (
 // CodeFormatterTool runs code formatters and linters
 type CodeFormatterTool struct {
 	confirmator Confirmator
+	changes     *ChangeTracker
+	env         *EnvOverlay
 }
 
 func (c *CodeFormatterTool) Name() string {
@@ -56,6 +58,14 @@ func (c *CodeFormatterTool) SetConfirmator(confirmator Confirmator) {
 	c.confirmator = confirmator
 }
 
+func (c *CodeFormatterTool) SetChangeTracker(tracker *ChangeTracker) {
+	c.changes = tracker
+}
+
+func (c *CodeFormatterTool) SetEnvOverlay(env *EnvOverlay) {
+	c.env = env
+}
+
 func (c *CodeFormatterTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	tool, ok := args["tool"].(string)
 	if !ok {
@@ -108,15 +118,19 @@ func (c *CodeFormatterTool) Execute(ctx context.Context, args map[string]interfa
 		if fix {
 			operation = "Format"
 		}
-		message := fmt.Sprintf("%s %s with %s", operation, path, tool)
-		dangerous := fix // Fixing code is more dangerous than just checking
+		message := c.env.confirmationText(fmt.Sprintf("%s %s with %s", operation, path, tool))
+		dangerous := fix || c.env.HasDangerous() // Fixing code is more dangerous than just checking
 		if !c.confirmator.RequestConfirmation(operation, message, dangerous) {
 			return nil, fmt.Errorf("operation cancelled by user")
 		}
 	}
 
 	// Run the formatter
-	return c.runFormatter(tool, fullPath, path, fix, config)
+	result, err := c.runFormatter(tool, fullPath, path, fix, config)
+	if err == nil && fix {
+		c.changes.Record(fullPath)
+	}
+	return result, err
 }
 
 func (c *CodeFormatterTool) runFormatter(tool, fullPath, relativePath string, fix bool, config string) (interface{}, error) {
@@ -189,8 +203,9 @@ func (c *CodeFormatterTool) runFormatter(tool, fullPath, relativePath string, fi
 		return nil, fmt.Errorf("unsupported formatter: %s", tool)
 	}
 
-	// Set working directory
+	// Set working directory and session environment
 	cmd.Dir = filepath.Dir(fullPath)
+	cmd.Env = c.env.Environ()
 	
 	// Capture output
 	output, err := cmd.CombinedOutput()
@@ -0,0 +1,325 @@
+package tools
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Credential is a named secret added to HTTP requests for matching hosts.
+// The secret is read from an environment variable or a file when a request
+// is sent, so it never appears in tool arguments or the conversation.
+type Credential struct {
+	Name      string
+	Type      string   // bearer, basic, header or query
+	Hosts     []string // Host globs the credential is sent to
+	Header    string   // Header name, for the header type
+	Param     string   // Query parameter name, for the query type
+	Username  string   // User name, for the basic type
+	ValueEnv  string   // Environment variable holding the secret
+	ValueFile string   // File holding the secret
+	AllowHTTP bool     // Also send over plain http, where the secret is visible on the wire
+}
+
+// CredentialStore holds the configured credentials
+type CredentialStore struct {
+	mu    sync.RWMutex
+	creds []Credential
+}
+
+// CredentialTool is a tool that sends credentials from the store
+type CredentialTool interface {
+	Tool
+	SetCredentials(store *CredentialStore)
+}
+
+// NewCredentialStore creates an empty store
+func NewCredentialStore() *CredentialStore {
+	return &CredentialStore{}
+}
+
+// Set replaces the credentials
+func (s *CredentialStore) Set(creds []Credential) {
+	sorted := append([]Credential(nil), creds...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i].Name < sorted[j].Name })
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.creds = sorted
+}
+
+// ForHost returns the credentials bound to host
+func (s *CredentialStore) ForHost(host string) []Credential {
+	if s == nil {
+		return nil
+	}
+	host = strings.ToLower(host)
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var matched []Credential
+	for _, cred := range s.creds {
+		if _, ok := matchHost(cred.Hosts, host); ok {
+			matched = append(matched, cred)
+		}
+	}
+	return matched
+}
+
+// Describe lists the credentials and their hosts, for tool descriptions
+func (s *CredentialStore) Describe() string {
+	if s == nil {
+		return ""
+	}
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	var parts []string
+	for _, cred := range s.creds {
+		parts = append(parts, fmt.Sprintf("%s (%s)", cred.Name, strings.Join(cred.Hosts, ", ")))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// secret reads the credential's secret
+func (c Credential) secret() (string, error) {
+	switch {
+	case c.ValueEnv != "":
+		value := os.Getenv(c.ValueEnv)
+		if value == "" {
+			return "", fmt.Errorf("credential %s: environment variable %s is not set", c.Name, c.ValueEnv)
+		}
+		return value, nil
+	case c.ValueFile != "":
+		path := c.ValueFile
+		if strings.HasPrefix(path, "~/") {
+			if home, err := os.UserHomeDir(); err == nil {
+				path = filepath.Join(home, path[2:])
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("credential %s: failed to read %s: %v", c.Name, c.ValueFile, err)
+		}
+		return strings.TrimSpace(string(data)), nil
+	}
+	return "", fmt.Errorf("credential %s: no value_env or value_file configured", c.Name)
+}
+
+// apply adds the credential to req and returns the strings that reveal the
+// secret, for redaction
+func (c Credential) apply(req *http.Request) ([]string, error) {
+	value, err := c.secret()
+	if err != nil {
+		return nil, err
+	}
+
+	switch c.Type {
+	case "bearer":
+		req.Header.Set("Authorization", "Bearer "+value)
+		return []string{value}, nil
+	case "basic":
+		req.SetBasicAuth(c.Username, value)
+		encoded := base64.StdEncoding.EncodeToString([]byte(c.Username + ":" + value))
+		return []string{value, encoded}, nil
+	case "header":
+		if c.Header == "" {
+			return nil, fmt.Errorf("credential %s: header type needs a header name", c.Name)
+		}
+		req.Header.Set(c.Header, value)
+		return []string{value}, nil
+	case "query":
+		if c.Param == "" {
+			return nil, fmt.Errorf("credential %s: query type needs a param name", c.Name)
+		}
+		query := req.URL.Query()
+		query.Set(c.Param, value)
+		req.URL.RawQuery = query.Encode()
+		// The value may be echoed back in its encoded form
+		return []string{value, url.QueryEscape(value)}, nil
+	}
+	return nil, fmt.Errorf("credential %s: unknown type %q (use bearer, basic, header or query)", c.Name, c.Type)
+}
+
+// credentialTransport adds credentials to each request as it is sent,
+// including redirects, so that a credential only ever reaches the hosts it
+// is bound to
+type credentialTransport struct {
+	base    http.RoundTripper
+	store   *CredentialStore
+	secrets *secretSet
+}
+
+func (t *credentialTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	creds := t.store.ForHost(req.URL.Hostname())
+	if len(creds) == 0 {
+		return t.base.RoundTrip(req)
+	}
+
+	req = req.Clone(req.Context())
+	for _, cred := range creds {
+		// A plain http URL, or a redirect from https to http, would send the
+		// secret in clear text
+		if req.URL.Scheme != "https" && !cred.AllowHTTP {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, fmt.Errorf("credential %s is only sent over https, refusing %s (set allow_http to permit it)", cred.Name, req.URL.Redacted())
+		}
+		secrets, err := cred.apply(req)
+		if err != nil {
+			if req.Body != nil {
+				req.Body.Close()
+			}
+			return nil, err
+		}
+		t.secrets.add(cred.Name, secrets)
+	}
+	return t.base.RoundTrip(req)
+}
+
+// withCredentials wraps client so that requests carry the credentials for
+// their host. The returned set collects the secrets that were sent.
+func (s *CredentialStore) withCredentials(client *http.Client) *secretSet {
+	secrets := &secretSet{values: make(map[string]string)}
+	base := client.Transport
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	client.Transport = &credentialTransport{base: base, store: s, secrets: secrets}
+	return secrets
+}
+
+// secretSet is the set of secrets sent with a request, each mapped to the
+// name of its credential
+type secretSet struct {
+	mu     sync.Mutex
+	values map[string]string
+}
+
+func (s *secretSet) add(name string, secrets []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, secret := range secrets {
+		// Very short values would redact unrelated text
+		if len(secret) >= 4 {
+			s.values[secret] = name
+		}
+	}
+}
+
+// names returns the credentials that were sent
+func (s *secretSet) names() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	seen := make(map[string]bool)
+	var names []string
+	for _, name := range s.values {
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// replacer returns a replacer of secrets by placeholders, longest first
+func (s *secretSet) replacer() *strings.Replacer {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	secrets := make([]string, 0, len(s.values))
+	for secret := range s.values {
+		secrets = append(secrets, secret)
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	var pairs []string
+	for _, secret := range secrets {
+		pairs = append(pairs, secret, "[credential:"+s.values[secret]+"]")
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// maxLen returns the length of the longest secret
+func (s *secretSet) maxLen() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := 0
+	for secret := range s.values {
+		if len(secret) > n {
+			n = len(secret)
+		}
+	}
+	return n
+}
+
+// redact replaces secrets in the strings of a tool result
+func (s *secretSet) redact(value interface{}) interface{} {
+	if s.maxLen() == 0 {
+		return value
+	}
+	replacer := s.replacer()
+	var walk func(v interface{}) interface{}
+	walk = func(v interface{}) interface{} {
+		switch v := v.(type) {
+		case string:
+			return replacer.Replace(v)
+		case map[string]interface{}:
+			for key, item := range v {
+				v[key] = walk(item)
+			}
+			return v
+		case map[string]string:
+			for key, item := range v {
+				v[key] = replacer.Replace(item)
+			}
+			return v
+		case []interface{}:
+			for i, item := range v {
+				v[i] = walk(item)
+			}
+			return v
+		}
+		return v
+	}
+	return walk(value)
+}
+
+// redactingWriter replaces secrets in a stream before passing it on. The
+// end of each write is held back until the next one, so a secret split
+// across writes is still found.
+type redactingWriter struct {
+	w       io.Writer
+	secrets *secretSet
+	pending []byte
+}
+
+func (r *redactingWriter) Write(p []byte) (int, error) {
+	r.pending = append(r.pending, p...)
+	keep := r.secrets.maxLen() - 1
+	if keep < 0 {
+		keep = 0
+	}
+	if len(r.pending) <= keep {
+		return len(p), nil
+	}
+	text := r.secrets.replacer().Replace(string(r.pending))
+	cut := len(text) - keep
+	if _, err := io.WriteString(r.w, text[:cut]); err != nil {
+		return 0, err
+	}
+	r.pending = append(r.pending[:0], text[cut:]...)
+	return len(p), nil
+}
+
+// Flush writes the held back end of the stream
+func (r *redactingWriter) Flush() error {
+	text := r.secrets.replacer().Replace(string(r.pending))
+	r.pending = nil
+	_, err := io.WriteString(r.w, text)
+	return err
+}
@@ -14,6 +14,7 @@ import (
 // DataEditTool handles JSON and YAML data manipulation
 type DataEditTool struct {
 	confirmator Confirmator
+	changes     *ChangeTracker
 }
 
 func (d *DataEditTool) Name() string {
@@ -63,6 +64,10 @@ func (d *DataEditTool) SetConfirmator(confirmator Confirmator) {
 	d.confirmator = confirmator
 }
 
+func (d *DataEditTool) SetChangeTracker(tracker *ChangeTracker) {
+	d.changes = tracker
+}
+
 func (d *DataEditTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	operation, ok := args["operation"].(string)
 	if !ok {
@@ -207,6 +212,7 @@ func (d *DataEditTool) writeData(fullPath, relativePath, format string, data int
 	if err != nil {
 		return nil, fmt.Errorf("failed to write file: %v", err)
 	}
+	d.changes.Record(fullPath)
 
 	return map[string]interface{}{
 		"operation": "write",
@@ -299,6 +305,7 @@ func (d *DataEditTool) formatData(fullPath, relativePath, format string, indent
 	if err != nil {
 		return nil, fmt.Errorf("failed to write formatted file: %v", err)
 	}
+	d.changes.Record(fullPath)
 
 	return map[string]interface{}{
 		"operation": "format",
@@ -408,6 +415,7 @@ func (d *DataEditTool) mergeData(fullPath, relativePath, format string, newData
 	if err != nil {
 		return nil, fmt.Errorf("failed to write merged file: %v", err)
 	}
+	d.changes.Record(fullPath)
 
 	return map[string]interface{}{
 		"operation": "merge",
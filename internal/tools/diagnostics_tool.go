@@ -0,0 +1,420 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DiagnosticsTool runs the project's build and static checks and parses their output
+type DiagnosticsTool struct {
+	confirmator Confirmator
+	changes     *ChangeTracker
+	env         *EnvOverlay
+}
+
+// Diagnostic is a single compiler or linter message
+type Diagnostic struct {
+	File     string `json:"file"`
+	Line     int    `json:"line"`
+	Column   int    `json:"column,omitempty"`
+	Severity string `json:"severity"`
+	Code     string `json:"code,omitempty"`
+	Message  string `json:"message"`
+	Source   string `json:"source"`
+}
+
+// diagnosticCommand is a check to run and the name reported as the diagnostic source
+type diagnosticCommand struct {
+	source string
+	args   []string
+}
+
+// gccStyleRe matches "file:line[:col]: [severity:] message" as printed by go, gcc, clang, mypy and most linters
+var gccStyleRe = regexp.MustCompile(`^((?:[A-Za-z]:)?[^:\s][^:]*):(\d+)(?::(\d+))?:\s*(?:(error|warning|note|info|fatal error)\s*:\s*)?(.+)$`)
+
+// tscStyleRe matches TypeScript compiler output, e.g. "src/a.ts(12,5): error TS2322: message"
+var tscStyleRe = regexp.MustCompile(`^(.+?)\((\d+),(\d+)\):\s*(error|warning|message)\s+(TS\d+):\s*(.+)$`)
+
+// maxDiagnostics bounds the number of entries returned
+const maxDiagnostics = 200
+
+func (d *DiagnosticsTool) Name() string {
+	return "diagnostics"
+}
+
+func (d *DiagnosticsTool) Description() string {
+	return "Run the project's build, vet and type checks and return compiler/linter errors as structured entries (file, line, column, severity, message), deduplicated. Detects Go (go build + go vet) and TypeScript (tsc --noEmit) projects, or runs a custom command. Use scope 'changed' to only report problems in files modified during the current turn."
+}
+
+func (d *DiagnosticsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"scope": map[string]interface{}{
+			"type":        "string",
+			"description": "'all' for every diagnostic, or 'changed' for only files modified by tools during the current turn (default: all)",
+			"enum":        []string{"all", "changed"},
+		},
+		"checks": map[string]interface{}{
+			"type":        "string",
+			"description": "For Go projects: 'build', 'vet' or 'all' (default: all)",
+			"enum":        []string{"build", "vet", "all"},
+		},
+		"command": map[string]interface{}{
+			"type":        "string",
+			"description": "Custom check command to run instead of the detected one, e.g. 'mypy src' or 'make -k' (requires confirmation)",
+		},
+		"working_dir": map[string]interface{}{
+			"type":        "string",
+			"description": "Project directory relative to current working directory (default: '.')",
+		},
+		"timeout": map[string]interface{}{
+			"type":        "number",
+			"description": "Timeout in seconds per command (default: 120, max: 600)",
+		},
+	}
+}
+
+func (d *DiagnosticsTool) RequiredParameters() []string {
+	return []string{}
+}
+
+func (d *DiagnosticsTool) SetConfirmator(confirmator Confirmator) {
+	d.confirmator = confirmator
+}
+
+func (d *DiagnosticsTool) SetChangeTracker(tracker *ChangeTracker) {
+	d.changes = tracker
+}
+
+func (d *DiagnosticsTool) SetEnvOverlay(env *EnvOverlay) {
+	d.env = env
+}
+
+func (d *DiagnosticsTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	scope := "all"
+	if val, exists := args["scope"]; exists {
+		if s, ok := val.(string); ok && s != "" {
+			scope = s
+		}
+	}
+	if scope != "all" && scope != "changed" {
+		return nil, fmt.Errorf("invalid scope: %s (use 'all' or 'changed')", scope)
+	}
+
+	checks := "all"
+	if val, exists := args["checks"]; exists {
+		if s, ok := val.(string); ok && s != "" {
+			checks = s
+		}
+	}
+
+	var command string
+	if val, exists := args["command"]; exists {
+		if s, ok := val.(string); ok {
+			command = strings.TrimSpace(s)
+		}
+	}
+
+	workingDir := "."
+	if val, exists := args["working_dir"]; exists {
+		if s, ok := val.(string); ok && s != "" {
+			workingDir = s
+		}
+	}
+
+	timeout := 120.0
+	if val, exists := args["timeout"]; exists {
+		if t, ok := val.(float64); ok && t > 0 {
+			if t > 600 {
+				timeout = 600
+			} else {
+				timeout = t
+			}
+		}
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(workingDir) {
+		return nil, fmt.Errorf("working_dir must be relative, not absolute: %s", workingDir)
+	}
+	if strings.Contains(workingDir, "..") {
+		return nil, fmt.Errorf("working_dir cannot contain parent directory references (..): %s", workingDir)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+	dir := filepath.Join(cwd, workingDir)
+
+	var changed []string
+	if scope == "changed" {
+		changed = d.changes.Changed()
+		if len(changed) == 0 {
+			return map[string]interface{}{
+				"scope":       scope,
+				"count":       0,
+				"diagnostics": []Diagnostic{},
+				"summary":     "No files were changed during this turn",
+			}, nil
+		}
+	}
+
+	var commands []diagnosticCommand
+	if command != "" {
+		fields := strings.Fields(command)
+		if d.confirmator != nil {
+			if !d.confirmator.RequestConfirmation("Run diagnostics", d.env.confirmationText(command), d.env.HasDangerous()) {
+				return nil, fmt.Errorf("operation cancelled by user")
+			}
+		}
+		commands = []diagnosticCommand{{source: fields[0], args: fields}}
+	} else {
+		commands, err = detectDiagnosticCommands(dir, checks)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var all []Diagnostic
+	var ran []string
+	var unparsed []string
+	success := true
+
+	for _, c := range commands {
+		output, exitCode, err := runDiagnosticCommand(ctx, dir, c.args, d.env.Environ(), time.Duration(timeout)*time.Second)
+		if err != nil {
+			return nil, err
+		}
+		ran = append(ran, strings.Join(c.args, " "))
+		if exitCode != 0 {
+			success = false
+		}
+
+		diags := parseDiagnostics(output, c.source)
+		if exitCode != 0 && len(diags) == 0 {
+			// Failed without anything we could parse; keep the raw output
+			unparsed = append(unparsed, strings.Join(c.args, " ")+":\n"+tailText(strings.TrimSpace(output), maxTestOutput))
+		}
+		all = append(all, diags...)
+	}
+
+	// Report paths relative to the current working directory
+	for i := range all {
+		all[i].File = relativeTo(cwd, resolveDiagnosticPath(dir, all[i].File))
+	}
+
+	all = dedupeDiagnostics(all)
+
+	if scope == "changed" {
+		all = filterDiagnosticsToFiles(all, cwd, changed)
+	}
+
+	errors, warnings := 0, 0
+	for _, diag := range all {
+		if diag.Severity == "warning" {
+			warnings++
+		} else if diag.Severity == "error" {
+			errors++
+		}
+	}
+
+	total := len(all)
+	if len(all) > maxDiagnostics {
+		all = all[:maxDiagnostics]
+	}
+
+	result := map[string]interface{}{
+		"commands":    ran,
+		"scope":       scope,
+		"success":     success,
+		"count":       total,
+		"errors":      errors,
+		"warnings":    warnings,
+		"diagnostics": all,
+	}
+	if total > len(all) {
+		result["truncated"] = true
+	}
+	if len(unparsed) > 0 {
+		result["output"] = strings.Join(unparsed, "\n\n")
+	}
+	if scope == "changed" {
+		files := make([]string, 0, len(changed))
+		for _, path := range changed {
+			files = append(files, relativeTo(cwd, path))
+		}
+		result["changed_files"] = files
+	}
+
+	switch {
+	case total == 0 && success:
+		result["summary"] = "No problems found"
+	case total == 0:
+		result["summary"] = "Checks failed without parseable diagnostics"
+	default:
+		result["summary"] = fmt.Sprintf("Found %d problems (%d errors, %d warnings)", total, errors, warnings)
+	}
+	return result, nil
+}
+
+// detectDiagnosticCommands picks the checks for the project in dir
+func detectDiagnosticCommands(dir, checks string) ([]diagnosticCommand, error) {
+	switch detectProjectType(dir) {
+	case "go":
+		var commands []diagnosticCommand
+		if checks == "build" || checks == "all" {
+			commands = append(commands, diagnosticCommand{source: "go build", args: []string{"go", "build", "./..."}})
+		}
+		if checks == "vet" || checks == "all" {
+			commands = append(commands, diagnosticCommand{source: "go vet", args: []string{"go", "vet", "./..."}})
+		}
+		if len(commands) == 0 {
+			return nil, fmt.Errorf("invalid checks: %s (use 'build', 'vet' or 'all')", checks)
+		}
+		return commands, nil
+	case "node":
+		if _, err := os.Stat(filepath.Join(dir, "tsconfig.json")); err == nil {
+			return []diagnosticCommand{{source: "tsc", args: []string{"npx", "--no-install", "tsc", "--noEmit", "--pretty", "false"}}}, nil
+		}
+		return nil, fmt.Errorf("no tsconfig.json found; pass a custom command to check this project")
+	case "python":
+		return []diagnosticCommand{{source: "mypy", args: []string{"python", "-m", "mypy", "--no-color-output", "--no-error-summary", "."}}}, nil
+	}
+	return nil, fmt.Errorf("could not detect project type (no go.mod, package.json or pyproject.toml); pass a custom command")
+}
+
+// runDiagnosticCommand runs a check and returns its combined output and exit code
+func runDiagnosticCommand(ctx context.Context, dir string, args, env []string, timeout time.Duration) (string, int, error) {
+	timeoutCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(timeoutCtx, args[0], args[1:]...)
+	cmd.Dir = dir
+	cmd.Env = env
+	output, err := cmd.CombinedOutput()
+
+	if timeoutCtx.Err() == context.DeadlineExceeded {
+		return "", 0, fmt.Errorf("%s timed out after %v", args[0], timeout)
+	}
+	if err != nil {
+		if exitError, ok := err.(*exec.ExitError); ok {
+			return string(output), exitError.ExitCode(), nil
+		}
+		return "", 0, fmt.Errorf("failed to run %s: %v", args[0], err)
+	}
+	return string(output), 0, nil
+}
+
+// parseDiagnostics extracts diagnostics from gcc-style and tsc-style output.
+// Indented lines following a diagnostic are treated as its continuation.
+func parseDiagnostics(output, source string) []Diagnostic {
+	var diags []Diagnostic
+
+	for _, line := range strings.Split(strings.ReplaceAll(output, "\r\n", "\n"), "\n") {
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+
+		if m := tscStyleRe.FindStringSubmatch(line); m != nil {
+			lineNum, _ := strconv.Atoi(m[2])
+			col, _ := strconv.Atoi(m[3])
+			severity := m[4]
+			if severity == "message" {
+				severity = "info"
+			}
+			diags = append(diags, Diagnostic{
+				File: m[1], Line: lineNum, Column: col,
+				Severity: severity, Code: m[5], Message: strings.TrimSpace(m[6]), Source: source,
+			})
+			continue
+		}
+
+		// go vet reports type errors as "vet: file:line:col: message"
+		typeError := strings.HasPrefix(line, "vet: ")
+		line = strings.TrimPrefix(line, "vet: ")
+
+		if m := gccStyleRe.FindStringSubmatch(line); m != nil && !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "\t") {
+			lineNum, _ := strconv.Atoi(m[2])
+			col, _ := strconv.Atoi(m[3])
+			severity := m[4]
+			switch severity {
+			case "", "fatal error":
+				severity = "error"
+			case "note":
+				severity = "info"
+			}
+			if source == "go vet" && m[4] == "" && !typeError {
+				// vet analyzers report problems, not compile errors
+				severity = "warning"
+			}
+			diags = append(diags, Diagnostic{
+				File: m[1], Line: lineNum, Column: col,
+				Severity: severity, Message: strings.TrimSpace(m[5]), Source: source,
+			})
+			continue
+		}
+
+		if len(diags) > 0 && (strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t")) {
+			last := &diags[len(diags)-1]
+			last.Message += "\n" + strings.TrimSpace(line)
+		}
+	}
+	return diags
+}
+
+// resolveDiagnosticPath makes a reported path absolute relative to the command directory
+func resolveDiagnosticPath(dir, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(dir, path)
+}
+
+// dedupeDiagnostics removes repeated entries (e.g. the same type error from
+// go build and go vet) and sorts by location
+func dedupeDiagnostics(diags []Diagnostic) []Diagnostic {
+	seen := make(map[string]bool)
+	var unique []Diagnostic
+	for _, diag := range diags {
+		key := fmt.Sprintf("%s:%d:%d:%s", diag.File, diag.Line, diag.Column, diag.Message)
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+		unique = append(unique, diag)
+	}
+
+	sort.SliceStable(unique, func(i, j int) bool {
+		if unique[i].File != unique[j].File {
+			return unique[i].File < unique[j].File
+		}
+		if unique[i].Line != unique[j].Line {
+			return unique[i].Line < unique[j].Line
+		}
+		return unique[i].Column < unique[j].Column
+	})
+	return unique
+}
+
+// filterDiagnosticsToFiles keeps diagnostics in the given files or under the given directories
+func filterDiagnosticsToFiles(diags []Diagnostic, cwd string, paths []string) []Diagnostic {
+	var kept []Diagnostic
+	for _, diag := range diags {
+		abs := filepath.Join(cwd, diag.File)
+		for _, path := range paths {
+			if abs == path || strings.HasPrefix(abs, path+string(filepath.Separator)) {
+				kept = append(kept, diag)
+				break
+			}
+		}
+	}
+	return kept
+}
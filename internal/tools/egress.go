@@ -0,0 +1,338 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// EgressRules restricts where network tools may connect
+type EgressRules struct {
+	AllowHosts        []string // Host globs that may be contacted; empty allows all
+	DenyHosts         []string // Host globs that may never be contacted
+	AllowPrivateHosts []string // Host or address globs that may be private, loopback or link-local
+	MaxResponseBytes  int64    // Largest response body accepted
+	MaxRedirects      *int     // Redirects followed per request; 0 disables redirects, nil selects the default
+}
+
+// DefaultEgressRules returns the rules used when none are configured
+func DefaultEgressRules() EgressRules {
+	maxRedirects := 10
+	return EgressRules{MaxResponseBytes: 10 << 20, MaxRedirects: &maxRedirects}
+}
+
+// withDefaults fills unset limits
+func (r EgressRules) withDefaults() EgressRules {
+	d := DefaultEgressRules()
+	if r.MaxResponseBytes <= 0 {
+		r.MaxResponseBytes = d.MaxResponseBytes
+	}
+	if r.MaxRedirects == nil || *r.MaxRedirects < 0 {
+		r.MaxRedirects = d.MaxRedirects
+	}
+	return r
+}
+
+// cgnatPrefix is the shared address space, used by some cloud metadata services
+var cgnatPrefix = netip.MustParsePrefix("100.64.0.0/10")
+
+// EgressPolicy checks the destinations of network tools. Hosts are checked
+// against the allow and deny globs before a request is sent and again on
+// every redirect; addresses are checked when the connection is dialled, so
+// a host cannot be rebound to an internal address after the check.
+type EgressPolicy struct {
+	mu    sync.RWMutex
+	rules EgressRules
+
+	proxies sync.Map // Proxy addresses in use, exempt from the address check
+}
+
+// NetworkTool is a tool whose requests go through the egress policy
+type NetworkTool interface {
+	Tool
+	SetEgressPolicy(policy *EgressPolicy)
+}
+
+// NewEgressPolicy creates a policy with the default rules
+func NewEgressPolicy() *EgressPolicy {
+	return &EgressPolicy{rules: DefaultEgressRules()}
+}
+
+// SetRules replaces the rules; zero limits select the defaults
+func (p *EgressPolicy) SetRules(rules EgressRules) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.rules = rules.withDefaults()
+}
+
+// Rules returns the current rules
+func (p *EgressPolicy) Rules() EgressRules {
+	if p == nil {
+		return DefaultEgressRules()
+	}
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.rules
+}
+
+// CheckURL reports whether a request to u is allowed by the host rules. IP
+// literals are also checked against the blocked address ranges.
+func (p *EgressPolicy) CheckURL(u *url.URL) error {
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("egress policy: only http and https URLs are allowed, not %q", u.Scheme)
+	}
+	// A trailing dot names the same host and must not slip past the globs
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "" {
+		return fmt.Errorf("egress policy: URL has no host: %s", u)
+	}
+	rules := p.Rules()
+
+	if pattern, ok := matchHost(rules.DenyHosts, host); ok {
+		return fmt.Errorf("egress policy: host %s is denied by pattern %q", host, pattern)
+	}
+	if len(rules.AllowHosts) > 0 {
+		if _, ok := matchHost(rules.AllowHosts, host); !ok {
+			return fmt.Errorf("egress policy: host %s is not in the allowed hosts (egress.allow_hosts)", host)
+		}
+	}
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return p.checkAddr(host, addr)
+	}
+	return p.checkName(host)
+}
+
+// privateNamePatterns match names that only resolve inside a network, such
+// as metadata.google.internal. They are blocked like private addresses,
+// since a proxy may resolve them even when this machine cannot.
+var privateNamePatterns = []string{"localhost", "*.localhost", "*.internal", "*.local", "*.home.arpa"}
+
+// checkName reports whether host is an internal name the policy blocks
+func (p *EgressPolicy) checkName(host string) error {
+	if _, ok := matchHost(privateNamePatterns, host); !ok {
+		return nil
+	}
+	if _, ok := matchHost(p.Rules().AllowPrivateHosts, host); ok {
+		return nil
+	}
+	return fmt.Errorf("egress policy: %s is an internal host name; add it to egress.allow_private_hosts to allow it", host)
+}
+
+// checkAddr reports whether host may connect to addr
+func (p *EgressPolicy) checkAddr(host string, addr netip.Addr) error {
+	addr = addr.Unmap()
+	kind := blockedAddrKind(addr)
+	if kind == "" {
+		return nil
+	}
+	// Private hosts may be allowed by name or by address
+	allowed := p.Rules().AllowPrivateHosts
+	if _, ok := matchHost(allowed, host); ok {
+		return nil
+	}
+	if _, ok := matchHost(allowed, addr.String()); ok {
+		return nil
+	}
+	if _, err := netip.ParseAddr(host); err == nil {
+		return fmt.Errorf("egress policy: %s is a %s address; add it to egress.allow_private_hosts to allow it", addr, kind)
+	}
+	return fmt.Errorf("egress policy: %s resolves to %s address %s; add it to egress.allow_private_hosts to allow it", host, kind, addr)
+}
+
+// blockedAddrKind names the blocked range addr belongs to, or "" when the
+// address is public
+func blockedAddrKind(addr netip.Addr) string {
+	switch {
+	case addr.IsLoopback():
+		return "loopback"
+	case addr.IsLinkLocalUnicast(), addr.IsLinkLocalMulticast():
+		return "link-local"
+	case addr.IsPrivate(), cgnatPrefix.Contains(addr):
+		return "private"
+	case addr.IsUnspecified(), addr.IsMulticast(), addr.IsInterfaceLocalMulticast():
+		return "non-routable"
+	}
+	return ""
+}
+
+// matchHost returns the first glob in patterns matching host
+func matchHost(patterns []string, host string) (string, bool) {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(strings.ToLower(pattern), host); ok {
+			return pattern, true
+		}
+	}
+	return "", false
+}
+
+// Client returns an HTTP client that enforces the policy on redirects and
+// connections. Callers check the request URL with CheckURL and wrap the
+// response body with LimitBody.
+func (p *EgressPolicy) Client(timeout time.Duration) *http.Client {
+	if p == nil {
+		p = NewEgressPolicy()
+	}
+	dialer := &net.Dialer{Timeout: 30 * time.Second, KeepAlive: 30 * time.Second}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = p.proxy
+	transport.DialContext = func(ctx context.Context, network, address string) (net.Conn, error) {
+		return p.dial(ctx, dialer, network, address)
+	}
+
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			if max := *p.Rules().MaxRedirects; len(via) > max {
+				if max == 0 {
+					return fmt.Errorf("egress policy: redirects are disabled (egress.max_redirects), refused redirect to %s", req.URL)
+				}
+				return fmt.Errorf("egress policy: stopped after %d redirects", max)
+			}
+			if err := p.CheckURL(req.URL); err != nil {
+				return fmt.Errorf("redirect to %s refused: %v", req.URL, err)
+			}
+			return nil
+		},
+	}
+}
+
+// proxy selects the proxy from the environment, remembering its address so
+// that connections to it are not mistaken for requests to internal hosts.
+// The target is never dialled when a proxy is used, so its host is resolved
+// and checked here instead.
+func (p *EgressPolicy) proxy(req *http.Request) (*url.URL, error) {
+	proxyURL, err := http.ProxyFromEnvironment(req)
+	if err != nil || proxyURL == nil {
+		return proxyURL, err
+	}
+	if err := p.checkResolved(req.Context(), req.URL.Hostname()); err != nil {
+		return nil, err
+	}
+	address := proxyURL.Host
+	if proxyURL.Port() == "" {
+		port := "80"
+		switch proxyURL.Scheme {
+		case "https":
+			port = "443"
+		case "socks5", "socks5h":
+			port = "1080"
+		}
+		address = net.JoinHostPort(proxyURL.Hostname(), port)
+	}
+	p.proxies.Store(address, true)
+	return proxyURL, nil
+}
+
+// dial resolves address and connects only to addresses the policy allows
+func (p *EgressPolicy) dial(ctx context.Context, dialer *net.Dialer, network, address string) (net.Conn, error) {
+	if _, ok := p.proxies.Load(address); ok {
+		return dialer.DialContext(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	addrs, err := p.resolve(ctx, host)
+	if err != nil {
+		return nil, err
+	}
+
+	// Connect to the first allowed address that answers
+	var lastErr error
+	for _, addr := range addrs {
+		conn, err := dialer.DialContext(ctx, network, net.JoinHostPort(addr.Unmap().String(), port))
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no addresses found for %s", host)
+	}
+	return nil, lastErr
+}
+
+// resolve looks up host and checks that every address is allowed
+func (p *EgressPolicy) resolve(ctx context.Context, host string) ([]netip.Addr, error) {
+	host = strings.TrimSuffix(strings.ToLower(host), ".")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		return []netip.Addr{addr}, p.checkAddr(host, addr)
+	}
+	if err := p.checkName(host); err != nil {
+		return nil, err
+	}
+	addrs, err := net.DefaultResolver.LookupNetIP(ctx, "ip", host)
+	if err != nil {
+		return nil, err
+	}
+	for _, addr := range addrs {
+		if err := p.checkAddr(host, addr); err != nil {
+			return nil, err
+		}
+	}
+	return addrs, nil
+}
+
+// checkResolved checks the addresses of a host reached through a proxy. A
+// host that cannot be resolved here is refused, since the proxy might
+// resolve it to an internal address.
+func (p *EgressPolicy) checkResolved(ctx context.Context, host string) error {
+	if _, err := p.resolve(ctx, host); err != nil {
+		var dnsErr *net.DNSError
+		if errors.As(err, &dnsErr) {
+			return fmt.Errorf("egress policy: %s cannot be resolved to check its address before using the proxy: %v", host, err)
+		}
+		return err
+	}
+	return nil
+}
+
+// LimitBody checks the declared size of a response and returns its body,
+// which fails once more than MaxResponseBytes have been read
+func (p *EgressPolicy) LimitBody(resp *http.Response) (io.Reader, error) {
+	max := p.Rules().MaxResponseBytes
+	if resp.ContentLength > max {
+		return nil, fmt.Errorf("egress policy: response of %d bytes exceeds the %d byte limit (egress.max_response_bytes)", resp.ContentLength, max)
+	}
+	return &limitedBody{r: resp.Body, remaining: max, max: max}, nil
+}
+
+// limitedBody reads up to max bytes and then fails
+type limitedBody struct {
+	r         io.Reader
+	remaining int64
+	max       int64
+}
+
+func (l *limitedBody) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, l.tooLarge()
+	}
+	// Read one byte past the limit to tell a body of exactly max bytes
+	// from a longer one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.r.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		n += int(l.remaining)
+		return n, l.tooLarge()
+	}
+	return n, err
+}
+
+func (l *limitedBody) tooLarge() error {
+	return fmt.Errorf("egress policy: response exceeds the %d byte limit (egress.max_response_bytes)", l.max)
+}
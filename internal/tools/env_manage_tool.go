@@ -0,0 +1,391 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// EnvManageTool manages the session environment overlay shared by the
+// command-running tools
+type EnvManageTool struct {
+	env         *EnvOverlay
+	confirmator Confirmator
+}
+
+// envNameRe matches valid environment variable names
+var envNameRe = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+func (e *EnvManageTool) Name() string {
+	return "env_manage"
+}
+
+func (e *EnvManageTool) Description() string {
+	return "Manage environment variables for this session. Variables set here apply to every later shell, code_format, run_tests, diagnostics and process_manage command (e.g. set GOFLAGS or DATABASE_URL once). Supports get, set, unset, loading a .env file, and listing (secret values are masked)."
+}
+
+func (e *EnvManageTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"operation": map[string]interface{}{
+			"type":        "string",
+			"description": "Operation to perform",
+			"enum":        []string{"get", "set", "unset", "load", "list"},
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Variable name (for get, set, unset)",
+		},
+		"value": map[string]interface{}{
+			"type":        "string",
+			"description": "Variable value (for set)",
+		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Path of the .env file relative to current working directory (for load, default: '.env')",
+		},
+		"override": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Let .env values replace variables that are already set (for load, default: true)",
+		},
+		"include_inherited": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Also list variables inherited from the RoriCode process (for list, default: false)",
+		},
+	}
+}
+
+func (e *EnvManageTool) RequiredParameters() []string {
+	return []string{"operation"}
+}
+
+func (e *EnvManageTool) SetEnvOverlay(env *EnvOverlay) {
+	e.env = env
+}
+
+func (e *EnvManageTool) SetConfirmator(confirmator Confirmator) {
+	e.confirmator = confirmator
+}
+
+// confirm asks before changing the session environment, which affects every
+// later command. It reports whether the change may go ahead.
+func (e *EnvManageTool) confirm(operation, message string, names []string) bool {
+	if e.confirmator == nil {
+		return true
+	}
+	dangerous := false
+	for _, name := range names {
+		if IsDangerousEnvName(name) {
+			dangerous = true
+		}
+	}
+	return e.confirmator.RequestConfirmation(operation, message, dangerous)
+}
+
+// envAborted is the result of a change the user declined
+func envAborted(summary string) map[string]interface{} {
+	return map[string]interface{}{
+		"summary": summary,
+		"aborted": true,
+	}
+}
+
+func (e *EnvManageTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	operation, ok := args["operation"].(string)
+	if !ok {
+		return nil, fmt.Errorf("operation parameter must be a string")
+	}
+	if e.env == nil {
+		e.env = NewEnvOverlay()
+	}
+
+	switch operation {
+	case "get":
+		name, err := envName(args)
+		if err != nil {
+			return nil, err
+		}
+		value, source := e.env.Lookup(name)
+		if source == "" {
+			return map[string]interface{}{
+				"name":    name,
+				"set":     false,
+				"summary": fmt.Sprintf("%s is not set", name),
+			}, nil
+		}
+		return map[string]interface{}{
+			"name":    name,
+			"value":   MaskEnvValue(name, value),
+			"source":  source,
+			"set":     true,
+			"summary": fmt.Sprintf("%s=%s (%s)", name, MaskEnvValue(name, value), source),
+		}, nil
+
+	case "set":
+		name, err := envName(args)
+		if err != nil {
+			return nil, err
+		}
+		value, ok := args["value"].(string)
+		if !ok {
+			return nil, fmt.Errorf("value parameter must be a string")
+		}
+		if !e.confirm("Set environment variable", fmt.Sprintf("%s=%s", name, MaskEnvValue(name, value)), []string{name}) {
+			return envAborted(fmt.Sprintf("User aborted setting %s", name)), nil
+		}
+		e.env.Set(name, value)
+		return map[string]interface{}{
+			"name":    name,
+			"value":   MaskEnvValue(name, value),
+			"summary": fmt.Sprintf("Set %s for this session", name),
+		}, nil
+
+	case "unset":
+		name, err := envName(args)
+		if err != nil {
+			return nil, err
+		}
+		if !e.confirm("Unset environment variable", name, []string{name}) {
+			return envAborted(fmt.Sprintf("User aborted unsetting %s", name)), nil
+		}
+		e.env.Unset(name)
+		return map[string]interface{}{
+			"name":    name,
+			"summary": fmt.Sprintf("Unset %s for this session", name),
+		}, nil
+
+	case "load":
+		return e.load(args)
+
+	case "list":
+		includeInherited := false
+		if val, exists := args["include_inherited"]; exists {
+			if b, ok := val.(bool); ok {
+				includeInherited = b
+			}
+		}
+		return e.list(includeInherited), nil
+
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", operation)
+	}
+}
+
+// envName reads and validates the name argument
+func envName(args map[string]interface{}) (string, error) {
+	name, ok := args["name"].(string)
+	if !ok || name == "" {
+		return "", fmt.Errorf("name parameter must be a non-empty string")
+	}
+	if !envNameRe.MatchString(name) {
+		return "", fmt.Errorf("invalid variable name: %s", name)
+	}
+	return name, nil
+}
+
+// load reads a .env file into the overlay
+func (e *EnvManageTool) load(args map[string]interface{}) (interface{}, error) {
+	path := ".env"
+	if val, exists := args["path"]; exists {
+		if p, ok := val.(string); ok && p != "" {
+			path = p
+		}
+	}
+
+	override := true
+	if val, exists := args["override"]; exists {
+		if b, ok := val.(bool); ok {
+			override = b
+		}
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(path) {
+		return nil, fmt.Errorf("path must be relative, not absolute: %s", path)
+	}
+	if strings.Contains(path, "..") {
+		return nil, fmt.Errorf("path cannot contain parent directory references (..): %s", path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+
+	file, err := os.Open(filepath.Join(cwd, path))
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("file does not exist: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %v", err)
+	}
+	defer file.Close()
+
+	vars, err := parseDotEnv(file, func(name string) string {
+		value, _ := e.env.Lookup(name)
+		return value
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %v", path, err)
+	}
+
+	var loaded, skipped []string
+	var apply [][2]string
+	for _, kv := range vars {
+		if _, source := e.env.Lookup(kv[0]); source != "" && !override {
+			skipped = append(skipped, kv[0])
+			continue
+		}
+		apply = append(apply, kv)
+		loaded = append(loaded, kv[0])
+	}
+	if len(loaded) > 0 {
+		message := fmt.Sprintf("Load %s from %s", strings.Join(loaded, ", "), path)
+		if !e.confirm("Load environment file", message, loaded) {
+			return envAborted(fmt.Sprintf("User aborted loading %s", path)), nil
+		}
+	}
+	for _, kv := range apply {
+		e.env.Set(kv[0], kv[1])
+	}
+
+	result := map[string]interface{}{
+		"path":    path,
+		"loaded":  loaded,
+		"summary": fmt.Sprintf("Loaded %d variables from %s", len(loaded), path),
+	}
+	if len(skipped) > 0 {
+		result["skipped"] = skipped
+	}
+	return result, nil
+}
+
+// list describes the session variables and optionally the inherited ones
+func (e *EnvManageTool) list(includeInherited bool) map[string]interface{} {
+	vars, unset := e.env.Session()
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	session := make([]map[string]interface{}, 0, len(names))
+	for _, name := range names {
+		session = append(session, map[string]interface{}{
+			"name":  name,
+			"value": MaskEnvValue(name, vars[name]),
+		})
+	}
+
+	result := map[string]interface{}{
+		"session": session,
+		"count":   len(session),
+		"summary": fmt.Sprintf("%d session variables", len(session)),
+	}
+	if len(unset) > 0 {
+		result["unset"] = unset
+	}
+
+	if includeInherited {
+		inherited := make(map[string]string)
+		for _, kv := range os.Environ() {
+			name, value, _ := strings.Cut(kv, "=")
+			if name == "" {
+				continue
+			}
+			if _, source := e.env.Lookup(name); source == "inherited" {
+				inherited[name] = MaskEnvValue(name, value)
+			}
+		}
+		result["inherited"] = inherited
+	}
+	return result
+}
+
+// parseDotEnv parses KEY=VALUE lines in the common .env dialect: comments,
+// optional "export", single quotes (literal), double quotes (escapes and
+// ${VAR} expansion) and unquoted values with trailing " #" comments.
+// lookup resolves variables not defined earlier in the file.
+func parseDotEnv(file *os.File, lookup func(string) string) ([][2]string, error) {
+	var vars [][2]string
+	defined := make(map[string]string)
+	expand := func(s string) string {
+		return os.Expand(s, func(name string) string {
+			if value, ok := defined[name]; ok {
+				return value
+			}
+			return lookup(name)
+		})
+	}
+
+	scanner := bufio.NewScanner(file)
+	lineNum := 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		name, value, found := strings.Cut(line, "=")
+		name = strings.TrimSpace(name)
+		if !found || !envNameRe.MatchString(name) {
+			return nil, fmt.Errorf("line %d: expected KEY=VALUE", lineNum)
+		}
+		value = strings.TrimSpace(value)
+
+		switch {
+		case strings.HasPrefix(value, "'"):
+			end := strings.Index(value[1:], "'")
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unterminated single quote", lineNum)
+			}
+			value = value[1 : end+1]
+		case strings.HasPrefix(value, `"`):
+			var b strings.Builder
+			closed := false
+			for i := 1; i < len(value); i++ {
+				c := value[i]
+				if c == '\\' && i+1 < len(value) {
+					i++
+					switch value[i] {
+					case 'n':
+						b.WriteByte('\n')
+					case 't':
+						b.WriteByte('\t')
+					default:
+						b.WriteByte(value[i])
+					}
+					continue
+				}
+				if c == '"' {
+					closed = true
+					break
+				}
+				b.WriteByte(c)
+			}
+			if !closed {
+				return nil, fmt.Errorf("line %d: unterminated double quote", lineNum)
+			}
+			value = expand(b.String())
+		default:
+			if i := strings.Index(value, " #"); i >= 0 {
+				value = strings.TrimSpace(value[:i])
+			}
+			value = expand(value)
+		}
+
+		defined[name] = value
+		vars = append(vars, [2]string{name, value})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return vars, nil
+}
@@ -0,0 +1,301 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	"github.com/Rorical/RoriCode/internal/webpage"
+)
+
+// FetchURLTool downloads web pages and returns their main content as Markdown
+type FetchURLTool struct {
+	confirmator Confirmator
+	egress      *EgressPolicy
+
+	mu    sync.Mutex
+	cache map[string]*fetchedPage // Keyed by URL without fragment
+}
+
+// fetchedPage is a converted document kept for paging
+type fetchedPage struct {
+	finalURL    string
+	title       string
+	contentType string
+	status      int
+	content     string
+	fetchedAt   time.Time
+}
+
+const (
+	fetchCacheTTL     = 15 * time.Minute
+	maxCachedPages    = 32
+	maxFetchBytes     = 5 << 20
+	defaultFetchChars = 20000
+	maxFetchChars     = 100000
+)
+
+func (f *FetchURLTool) Name() string {
+	return "fetch_url"
+}
+
+func (f *FetchURLTool) Description() string {
+	return "Fetch a web page and return its main content as Markdown (scripts, styles and navigation removed, links kept). Long documents are paged by character offset; pages are cached, so reading further pages does not download again. Use http_request for APIs."
+}
+
+func (f *FetchURLTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"url": map[string]interface{}{
+			"type":        "string",
+			"description": "URL of the page (http or https)",
+		},
+		"offset": map[string]interface{}{
+			"type":        "number",
+			"description": "Character offset to start reading from (default: 0; use next_offset from the previous call)",
+		},
+		"max_chars": map[string]interface{}{
+			"type":        "number",
+			"description": fmt.Sprintf("Maximum characters to return (default: %d, max: %d)", defaultFetchChars, maxFetchChars),
+		},
+		"refresh": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Download the page again instead of using the cached copy (default: false)",
+		},
+	}
+}
+
+func (f *FetchURLTool) RequiredParameters() []string {
+	return []string{"url"}
+}
+
+func (f *FetchURLTool) SetConfirmator(confirmator Confirmator) {
+	f.confirmator = confirmator
+}
+
+func (f *FetchURLTool) SetEgressPolicy(policy *EgressPolicy) {
+	f.egress = policy
+}
+
+func (f *FetchURLTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	rawURL, ok := args["url"].(string)
+	if !ok || rawURL == "" {
+		return nil, fmt.Errorf("url parameter must be a non-empty string")
+	}
+	target, err := url.Parse(rawURL)
+	if err != nil || (target.Scheme != "http" && target.Scheme != "https") || target.Host == "" {
+		return nil, fmt.Errorf("url must be an absolute http or https URL: %s", rawURL)
+	}
+	target.Fragment = ""
+	key := target.String()
+	if err := f.egress.CheckURL(target); err != nil {
+		return nil, err
+	}
+
+	offset := 0
+	if val, exists := args["offset"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			offset = int(num)
+		}
+	}
+	maxChars := defaultFetchChars
+	if val, exists := args["max_chars"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			maxChars = int(num)
+		}
+	}
+	if maxChars > maxFetchChars {
+		maxChars = maxFetchChars
+	}
+	refresh := false
+	if val, exists := args["refresh"]; exists {
+		if b, ok := val.(bool); ok {
+			refresh = b
+		}
+	}
+
+	// Paging through a cached page needs no new download or confirmation
+	page, cached := f.cached(key)
+	if refresh || !cached {
+		if f.confirmator != nil {
+			if !f.confirmator.RequestConfirmation("Fetch URL", fmt.Sprintf("Fetch %s", key), false) {
+				return nil, fmt.Errorf("operation cancelled by user")
+			}
+		}
+		page, err = f.fetch(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		// Error pages are returned but not cached
+		if page.status < 400 {
+			f.store(key, page)
+		}
+		cached = false
+	}
+	return f.pageResult(key, page, offset, maxChars, cached)
+}
+
+// fetch downloads a document and converts it to text
+func (f *FetchURLTool) fetch(ctx context.Context, pageURL string) (*fetchedPage, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, pageURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %v", err)
+	}
+	req.Header.Set("User-Agent", "RoriCode-FetchTool/1.0")
+	req.Header.Set("Accept", "text/html,application/xhtml+xml,text/plain;q=0.9,*/*;q=0.8")
+
+	resp, err := f.egress.Client(30 * time.Second).Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %v", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := f.egress.LimitBody(resp)
+	if err != nil {
+		return nil, err
+	}
+	body, err := io.ReadAll(io.LimitReader(respBody, maxFetchBytes+1))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+	truncated := len(body) > maxFetchBytes
+	if truncated {
+		body = body[:maxFetchBytes]
+	}
+
+	contentType := resp.Header.Get("Content-Type")
+	if contentType == "" {
+		contentType = http.DetectContentType(body)
+	}
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	page := &fetchedPage{
+		finalURL:    resp.Request.URL.String(),
+		contentType: mediaType,
+		status:      resp.StatusCode,
+		fetchedAt:   time.Now(),
+	}
+	switch {
+	case mediaType == "text/html" || mediaType == "application/xhtml+xml":
+		extracted, err := webpage.Extract(body, page.finalURL)
+		if err != nil {
+			return nil, err
+		}
+		page.title = extracted.Title
+		page.content = extracted.Markdown
+	case isTextMediaType(mediaType):
+		if !utf8.Valid(body) {
+			body = []byte(strings.ToValidUTF8(string(body), "�"))
+		}
+		page.content = string(body)
+	default:
+		return nil, fmt.Errorf("unsupported content type %s; use http_request for non-text resources", mediaType)
+	}
+	if truncated {
+		page.content += fmt.Sprintf("\n\n[Document truncated: only the first %d MiB were downloaded]", maxFetchBytes>>20)
+	}
+	return page, nil
+}
+
+// isTextMediaType reports whether a media type is returned as plain text
+func isTextMediaType(mediaType string) bool {
+	switch {
+	case strings.HasPrefix(mediaType, "text/"),
+		strings.HasSuffix(mediaType, "+json"),
+		strings.HasSuffix(mediaType, "+xml"):
+		return true
+	}
+	switch mediaType {
+	case "application/json", "application/xml", "application/javascript", "application/x-yaml", "application/yaml":
+		return true
+	}
+	return false
+}
+
+// pageResult returns one page of a document, starting at a character offset
+func (f *FetchURLTool) pageResult(key string, page *fetchedPage, offset, maxChars int, cached bool) (interface{}, error) {
+	content := []rune(page.content)
+	total := len(content)
+	if offset > total {
+		return nil, fmt.Errorf("offset %d is past the end of the document (%d characters)", offset, total)
+	}
+
+	end := offset + maxChars
+	if end >= total {
+		end = total
+	} else {
+		// Prefer to stop at a line break near the end of the page
+		for i := end - 1; i > offset+maxChars*4/5; i-- {
+			if content[i] == '\n' {
+				end = i + 1
+				break
+			}
+		}
+	}
+
+	result := map[string]interface{}{
+		"url":          key,
+		"content_type": page.contentType,
+		"status_code":  page.status,
+		"content":      string(content[offset:end]),
+		"offset":       offset,
+		"total_chars":  total,
+		"cached":       cached,
+	}
+	if page.finalURL != key {
+		result["final_url"] = page.finalURL
+	}
+	if page.title != "" {
+		result["title"] = page.title
+	}
+	if end < total {
+		result["next_offset"] = end
+	}
+
+	name := page.title
+	if name == "" {
+		name = key
+	}
+	summary := fmt.Sprintf("Fetched %s (characters %d-%d of %d)", name, offset, end, total)
+	if page.status >= 400 {
+		summary = fmt.Sprintf("Fetched %s with status %d", name, page.status)
+	}
+	result["summary"] = summary
+	return result, nil
+}
+
+// cached returns a fresh cached copy of a page
+func (f *FetchURLTool) cached(key string) (*fetchedPage, bool) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	page, ok := f.cache[key]
+	if !ok || time.Since(page.fetchedAt) > fetchCacheTTL {
+		return nil, false
+	}
+	return page, true
+}
+
+// store caches a page, evicting the oldest entry when the cache is full
+func (f *FetchURLTool) store(key string, page *fetchedPage) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.cache == nil {
+		f.cache = make(map[string]*fetchedPage)
+	}
+	if _, exists := f.cache[key]; !exists && len(f.cache) >= maxCachedPages {
+		oldest := ""
+		for k, p := range f.cache {
+			if oldest == "" || p.fetchedAt.Before(f.cache[oldest].fetchedAt) {
+				oldest = k
+			}
+		}
+		delete(f.cache, oldest)
+	}
+	f.cache[key] = page
+}
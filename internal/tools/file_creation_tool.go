@@ -11,6 +11,7 @@ import (
 // FileCreationTool creates new files with specified content
 type FileCreationTool struct {
 	confirmator Confirmator
+	changes     *ChangeTracker
 }
 
 func (f *FileCreationTool) Name() string {
@@ -46,6 +47,10 @@ func (f *FileCreationTool) SetConfirmator(confirmator Confirmator) {
 	f.confirmator = confirmator
 }
 
+func (f *FileCreationTool) SetChangeTracker(tracker *ChangeTracker) {
+	f.changes = tracker
+}
+
 func (f *FileCreationTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, ok := args["path"].(string)
 	if !ok {
@@ -113,6 +118,7 @@ func (f *FileCreationTool) Execute(ctx context.Context, args map[string]interfac
 	if err != nil {
 		return nil, fmt.Errorf("failed to write file: %v", err)
 	}
+	f.changes.Record(fullPath)
 
 	// Count lines in content
 	lines := strings.Split(content, "\n")
@@ -0,0 +1,343 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/Rorical/RoriCode/internal/textdiff"
+)
+
+// FileDiffTool compares a workspace file with another file, a string or a git
+// revision
+type FileDiffTool struct{}
+
+// maxDiffOutput caps the rendered diff returned to the model
+const maxDiffOutput = 50000
+
+func (f *FileDiffTool) Name() string {
+	return "diff"
+}
+
+func (f *FileDiffTool) Description() string {
+	return "Show the differences between a file and another file, a given string, or its git revision (HEAD by default). Produces unified or side-by-side output with hunk statistics; use it to verify your own edits. Options: context lines, ignore whitespace, word-level diff."
+}
+
+func (f *FileDiffTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Relative path of the file to compare (the new side)",
+		},
+		"other_path": map[string]interface{}{
+			"type":        "string",
+			"description": "Relative path of a file to compare against (the old side, optional)",
+		},
+		"content": map[string]interface{}{
+			"type":        "string",
+			"description": "Text to compare against (the old side, optional)",
+		},
+		"revision": map[string]interface{}{
+			"type":        "string",
+			"description": "Git revision to compare against when neither other_path nor content is given (default: 'HEAD')",
+		},
+		"format": map[string]interface{}{
+			"type":        "string",
+			"description": "Output format (default: 'unified')",
+			"enum":        []string{"unified", "side_by_side"},
+		},
+		"context": map[string]interface{}{
+			"type":        "number",
+			"description": "Unchanged lines shown around each change (default: 3, max: 100)",
+		},
+		"ignore_whitespace": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Ignore changes in the amount of whitespace (default: false)",
+		},
+		"word_diff": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Show changed lines with inline [-removed-]{+added+} word markers (unified format only, default: false)",
+		},
+		"width": map[string]interface{}{
+			"type":        "number",
+			"description": "Total line width for side_by_side format (default: 160)",
+		},
+	}
+}
+
+func (f *FileDiffTool) RequiredParameters() []string {
+	return []string{"path"}
+}
+
+func (f *FileDiffTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return nil, fmt.Errorf("path parameter must be a non-empty string")
+	}
+	if err := validateDiffPath(path); err != nil {
+		return nil, err
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current working directory: %v", err)
+	}
+
+	var otherPath, content, revision string
+	hasContent := false
+	if val, exists := args["other_path"]; exists {
+		if s, ok := val.(string); ok {
+			otherPath = s
+		}
+	}
+	if val, exists := args["content"]; exists {
+		if s, ok := val.(string); ok {
+			content = s
+			hasContent = true
+		}
+	}
+	if val, exists := args["revision"]; exists {
+		if s, ok := val.(string); ok {
+			revision = s
+		}
+	}
+	if otherPath != "" && hasContent {
+		return nil, fmt.Errorf("specify only one of other_path and content")
+	}
+
+	format := "unified"
+	if val, exists := args["format"]; exists {
+		if s, ok := val.(string); ok && s != "" {
+			format = s
+		}
+	}
+	if format != "unified" && format != "side_by_side" {
+		return nil, fmt.Errorf("unsupported format: %s", format)
+	}
+
+	opts := textdiff.Options{Context: 3}
+	if val, exists := args["context"]; exists {
+		if num, ok := val.(float64); ok && num >= 0 {
+			opts.Context = int(num)
+			if opts.Context > 100 {
+				opts.Context = 100
+			}
+		}
+	}
+	if val, exists := args["ignore_whitespace"]; exists {
+		if b, ok := val.(bool); ok {
+			opts.IgnoreWhitespace = b
+		}
+	}
+
+	wordDiff := false
+	if val, exists := args["word_diff"]; exists {
+		if b, ok := val.(bool); ok {
+			wordDiff = b
+		}
+	}
+
+	width := 160
+	if val, exists := args["width"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			width = int(num)
+		}
+	}
+
+	// Resolve both sides
+	var oldText, newText, oldName, newName, mode, note string
+	switch {
+	case otherPath != "":
+		if err := validateDiffPath(otherPath); err != nil {
+			return nil, err
+		}
+		mode = "files"
+		if oldText, err = readDiffFile(filepath.Join(cwd, otherPath), otherPath); err != nil {
+			return nil, err
+		}
+		if newText, err = readDiffFile(filepath.Join(cwd, path), path); err != nil {
+			return nil, err
+		}
+		oldName, newName = otherPath, path
+
+	case hasContent:
+		mode = "content"
+		oldText = content
+		if newText, err = readDiffFile(filepath.Join(cwd, path), path); err != nil {
+			return nil, err
+		}
+		oldName, newName = "(content)", path
+
+	default:
+		mode = "revision"
+		if revision == "" {
+			revision = "HEAD"
+		}
+		if strings.HasPrefix(revision, "-") {
+			return nil, fmt.Errorf("invalid revision: %s", revision)
+		}
+
+		existsInRevision := true
+		oldText, err = gitShowFile(ctx, cwd, revision, path)
+		if err == errNotInRevision {
+			existsInRevision = false
+		} else if err != nil {
+			return nil, err
+		}
+
+		deleted := false
+		newText, err = readDiffFile(filepath.Join(cwd, path), path)
+		if err != nil {
+			// A file deleted from the working tree diffs against nothing
+			if _, statErr := os.Stat(filepath.Join(cwd, path)); !os.IsNotExist(statErr) || !existsInRevision {
+				return nil, err
+			}
+			newText = ""
+			deleted = true
+		}
+		oldName, newName = revision+":"+filepath.ToSlash(path), path
+		if !existsInRevision {
+			note = fmt.Sprintf("%s is not tracked in %s; showing it as a new file", path, revision)
+		} else if deleted {
+			note = fmt.Sprintf("%s does not exist in the working tree; showing it as deleted", path)
+		}
+	}
+
+	result := textdiff.Lines(oldText, newText, opts)
+
+	var diff string
+	if format == "side_by_side" {
+		diff = result.SideBySide(width)
+	} else {
+		diff = result.Unified(oldName, newName, wordDiff)
+	}
+
+	truncated := false
+	if len(diff) > maxDiffOutput {
+		cut := strings.LastIndex(diff[:maxDiffOutput], "\n")
+		if cut < 0 {
+			cut = maxDiffOutput
+		}
+		diff = diff[:cut+1]
+		truncated = true
+	}
+
+	hunks := make([]map[string]interface{}, 0, len(result.Hunks))
+	for _, h := range result.Hunks {
+		hunks = append(hunks, map[string]interface{}{
+			"old_start": h.OldStart,
+			"old_lines": h.OldLines,
+			"new_start": h.NewStart,
+			"new_lines": h.NewLines,
+			"additions": h.Additions,
+			"deletions": h.Deletions,
+		})
+	}
+
+	summary := fmt.Sprintf("No differences between %s and %s", oldName, newName)
+	if len(result.Hunks) > 0 {
+		summary = fmt.Sprintf("%d hunks, +%d -%d (%s → %s)", len(result.Hunks), result.Additions, result.Deletions, oldName, newName)
+	}
+
+	output := map[string]interface{}{
+		"old":       oldName,
+		"new":       newName,
+		"mode":      mode,
+		"format":    format,
+		"identical": len(result.Hunks) == 0,
+		"hunks":     hunks,
+		"stats": map[string]interface{}{
+			"hunks":     len(result.Hunks),
+			"additions": result.Additions,
+			"deletions": result.Deletions,
+		},
+		"diff":    diff,
+		"summary": summary,
+	}
+	if truncated {
+		output["truncated"] = true
+	}
+	if note != "" {
+		output["note"] = note
+	}
+	return output, nil
+}
+
+// validateDiffPath applies the workspace path rules
+func validateDiffPath(path string) error {
+	if filepath.IsAbs(path) {
+		return fmt.Errorf("path must be relative, not absolute: %s", path)
+	}
+	if strings.Contains(path, "..") {
+		return fmt.Errorf("path cannot contain parent directory references (..): %s", path)
+	}
+	return nil
+}
+
+// readDiffFile reads a text file to diff
+func readDiffFile(fullPath, relativePath string) (string, error) {
+	info, err := os.Stat(fullPath)
+	if os.IsNotExist(err) {
+		return "", fmt.Errorf("file does not exist: %s", relativePath)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to access file: %v", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("path is a directory: %s", relativePath)
+	}
+
+	data, err := os.ReadFile(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read file: %v", err)
+	}
+	if isBinaryContent(data) {
+		return "", fmt.Errorf("cannot diff binary file: %s", relativePath)
+	}
+	return string(data), nil
+}
+
+// isBinaryContent reports whether data looks binary, using the same NUL byte
+// heuristic as git
+func isBinaryContent(data []byte) bool {
+	if len(data) > 8000 {
+		data = data[:8000]
+	}
+	return bytes.IndexByte(data, 0) >= 0
+}
+
+// errNotInRevision reports that a file does not exist in a git revision
+var errNotInRevision = fmt.Errorf("file not in revision")
+
+// gitShowFile returns a file's contents at a git revision
+func gitShowFile(ctx context.Context, cwd, revision, path string) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	// "./" makes git resolve the path relative to the working directory
+	cmd := exec.CommandContext(ctx, "git", "show", revision+":./"+filepath.ToSlash(path))
+	cmd.Dir = cwd
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		msg := strings.TrimSpace(stderr.String())
+		if strings.Contains(msg, "exists on disk, but not in") || strings.Contains(msg, "does not exist in") {
+			return "", errNotInRevision
+		}
+		if msg == "" {
+			msg = err.Error()
+		}
+		return "", fmt.Errorf("git show failed: %s", msg)
+	}
+	if isBinaryContent(stdout.Bytes()) {
+		return "", fmt.Errorf("cannot diff binary file: %s", path)
+	}
+	return stdout.String(), nil
+}
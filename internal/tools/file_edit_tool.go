@@ -11,6 +11,7 @@ import (
 // FileEditTool edits files using git diff format
 type FileEditTool struct {
 	confirmator Confirmator
+	changes     *ChangeTracker
 }
 
 // DiffHunk represents a single diff hunk
@@ -51,6 +52,10 @@ func (f *FileEditTool) SetConfirmator(confirmator Confirmator) {
 	f.confirmator = confirmator
 }
 
+func (f *FileEditTool) SetChangeTracker(tracker *ChangeTracker) {
+	f.changes = tracker
+}
+
 func (f *FileEditTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, ok := args["path"].(string)
 	if !ok {
@@ -112,6 +117,7 @@ func (f *FileEditTool) Execute(ctx context.Context, args map[string]interface{})
 	if err := os.WriteFile(fullPath, []byte(modifiedContent), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write modified file: %v", err)
 	}
+	f.changes.Record(fullPath)
 
 	return map[string]interface{}{
 		"path":           path,
@@ -11,6 +11,7 @@ import (
 // FileInsertTool inserts content at specific positions
 type FileInsertTool struct {
 	confirmator Confirmator
+	changes     *ChangeTracker
 }
 
 func (f *FileInsertTool) Name() string {
@@ -51,6 +52,10 @@ func (f *FileInsertTool) SetConfirmator(confirmator Confirmator) {
 	f.confirmator = confirmator
 }
 
+func (f *FileInsertTool) SetChangeTracker(tracker *ChangeTracker) {
+	f.changes = tracker
+}
+
 func (f *FileInsertTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, ok := args["path"].(string)
 	if !ok {
@@ -170,6 +175,7 @@ func (f *FileInsertTool) Execute(ctx context.Context, args map[string]interface{
 	if err := os.WriteFile(fullPath, []byte(newContent), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write file: %v", err)
 	}
+	f.changes.Record(fullPath)
 
 	var positionDesc string
 	switch position {
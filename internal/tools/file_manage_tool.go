@@ -12,6 +12,7 @@ import (
 // FileManageTool handles file operations (copy/move/rename)
 type FileManageTool struct {
 	confirmator Confirmator
+	changes     *ChangeTracker
 }
 
 func (f *FileManageTool) Name() string {
@@ -52,6 +53,10 @@ func (f *FileManageTool) SetConfirmator(confirmator Confirmator) {
 	f.confirmator = confirmator
 }
 
+func (f *FileManageTool) SetChangeTracker(tracker *ChangeTracker) {
+	f.changes = tracker
+}
+
 func (f *FileManageTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	operation, ok := args["operation"].(string)
 	if !ok {
@@ -154,6 +159,7 @@ func (f *FileManageTool) Execute(ctx context.Context, args map[string]interface{
 		if err != nil {
 			return nil, fmt.Errorf("failed to copy file: %v", err)
 		}
+		f.changes.Record(destPath)
 		
 		return map[string]interface{}{
 			"operation":   "copy",
@@ -168,6 +174,7 @@ func (f *FileManageTool) Execute(ctx context.Context, args map[string]interface{
 		if err != nil {
 			return nil, fmt.Errorf("failed to %s file: %v", operation, err)
 		}
+		f.changes.Record(sourcePath, destPath)
 		
 		return map[string]interface{}{
 			"operation":   operation,
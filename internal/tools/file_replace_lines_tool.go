@@ -11,6 +11,7 @@ import (
 // FileReplaceLinesTool replaces specific line ranges in files
 type FileReplaceLinesTool struct {
 	confirmator Confirmator
+	changes     *ChangeTracker
 }
 
 func (f *FileReplaceLinesTool) Name() string {
@@ -50,6 +51,10 @@ func (f *FileReplaceLinesTool) SetConfirmator(confirmator Confirmator) {
 	f.confirmator = confirmator
 }
 
+func (f *FileReplaceLinesTool) SetChangeTracker(tracker *ChangeTracker) {
+	f.changes = tracker
+}
+
 func (f *FileReplaceLinesTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, ok := args["path"].(string)
 	if !ok {
@@ -153,6 +158,7 @@ func (f *FileReplaceLinesTool) Execute(ctx context.Context, args map[string]inte
 	if err := os.WriteFile(fullPath, []byte(newContent), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write file: %v", err)
 	}
+	f.changes.Record(fullPath)
 
 	linesReplaced := int(endLine) - int(startLine) + 1
 	return fmt.Sprintf("Successfully replaced %d line(s) in %s (lines %d-%d) with %d new line(s)", 
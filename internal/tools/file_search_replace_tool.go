@@ -12,6 +12,7 @@ import (
 // FileSearchReplaceTool performs search and replace operations
 type FileSearchReplaceTool struct {
 	confirmator Confirmator
+	changes     *ChangeTracker
 }
 
 func (f *FileSearchReplaceTool) Name() string {
@@ -59,6 +60,10 @@ func (f *FileSearchReplaceTool) SetConfirmator(confirmator Confirmator) {
 	f.confirmator = confirmator
 }
 
+func (f *FileSearchReplaceTool) SetChangeTracker(tracker *ChangeTracker) {
+	f.changes = tracker
+}
+
 func (f *FileSearchReplaceTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	path, ok := args["path"].(string)
 	if !ok {
@@ -215,6 +220,7 @@ func (f *FileSearchReplaceTool) Execute(ctx context.Context, args map[string]int
 	if err := os.WriteFile(fullPath, []byte(result), 0644); err != nil {
 		return nil, fmt.Errorf("failed to write file: %v", err)
 	}
+	f.changes.Record(fullPath)
 
 	return fmt.Sprintf("Successfully replaced %d occurrence(s) of '%s' in %s", count, search, path), nil
 }
\ No newline at end of file
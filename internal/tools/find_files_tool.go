@@ -0,0 +1,382 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/bmatcuk/doublestar/v4"
+
+	"github.com/Rorical/RoriCode/internal/fsutil"
+)
+
+// FindFilesTool recursively locates files using doublestar glob patterns
+type FindFilesTool struct{}
+
+// foundFile is a single find_files match
+type foundFile struct {
+	path    string
+	isDir   bool
+	size    int64
+	modTime time.Time
+}
+
+func (f *FindFilesTool) Name() string {
+	return "find_files"
+}
+
+func (f *FindFilesTool) Description() string {
+	return "Recursively find files and directories matching a glob pattern (supports ** and {a,b}). Filters by depth, size, modification time and type, honours .gitignore, and returns results sorted by modification time (newest first)."
+}
+
+func (f *FindFilesTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"pattern": map[string]interface{}{
+			"type":        "string",
+			"description": "Glob pattern relative to path, e.g. '**/*.go', 'cmd/**/main.go', '**/*.{ts,tsx}'. Patterns without a slash match file names at any depth",
+		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Directory to search from, relative to current working directory (default: '.')",
+		},
+		"type": map[string]interface{}{
+			"type":        "string",
+			"description": "Type of entries to return: 'file', 'dir' or 'any' (default: 'file')",
+			"enum":        []string{"file", "dir", "any"},
+		},
+		"max_depth": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum directory depth to descend below path (default: unlimited)",
+		},
+		"min_size": map[string]interface{}{
+			"type":        "number",
+			"description": "Minimum file size in bytes (optional)",
+		},
+		"max_size": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum file size in bytes (optional)",
+		},
+		"modified_after": map[string]interface{}{
+			"type":        "string",
+			"description": "Only entries modified after this time. Accepts RFC3339, 'YYYY-MM-DD' or a relative age like '30m', '24h', '7d' (optional)",
+		},
+		"modified_before": map[string]interface{}{
+			"type":        "string",
+			"description": "Only entries modified before this time. Same formats as modified_after (optional)",
+		},
+		"exclude": map[string]interface{}{
+			"type":        "array",
+			"description": "Additional gitignore-style patterns to exclude (optional)",
+			"items":       map[string]interface{}{"type": "string"},
+		},
+		"respect_ignore": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Skip paths matched by .gitignore, .ignore and .roricodeignore files (default: true)",
+		},
+		"show_hidden": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Include hidden files and directories (default: false)",
+		},
+		"max_results": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum number of results to return (default: 200, max: 2000)",
+		},
+	}
+}
+
+func (f *FindFilesTool) RequiredParameters() []string {
+	return []string{"pattern"}
+}
+
+func (f *FindFilesTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	pattern, ok := args["pattern"].(string)
+	if !ok || pattern == "" {
+		return nil, fmt.Errorf("pattern parameter must be a non-empty string")
+	}
+	pattern = filepath.ToSlash(strings.TrimPrefix(pattern, "./"))
+	if !strings.Contains(pattern, "/") {
+		pattern = "**/" + pattern
+	}
+	if !doublestar.ValidatePattern(pattern) {
+		return nil, fmt.Errorf("invalid glob pattern: %s", pattern)
+	}
+
+	path := "."
+	if val, exists := args["path"]; exists {
+		if p, ok := val.(string); ok && p != "" {
+			path = p
+		}
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(path) {
+		return nil, fmt.Errorf("path must be relative, not absolute: %s", path)
+	}
+	if strings.Contains(path, "..") {
+		return nil, fmt.Errorf("path cannot contain parent directory references (..): %s", path)
+	}
+
+	entryType := "file"
+	if val, exists := args["type"]; exists {
+		if t, ok := val.(string); ok && t != "" {
+			entryType = t
+		}
+	}
+	if entryType != "file" && entryType != "dir" && entryType != "any" {
+		return nil, fmt.Errorf("type must be one of: file, dir, any")
+	}
+
+	maxDepth := 0
+	if val, exists := args["max_depth"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			maxDepth = int(num)
+		}
+	}
+
+	var minSize, maxSize int64 = -1, -1
+	if val, exists := args["min_size"]; exists {
+		if num, ok := val.(float64); ok {
+			minSize = int64(num)
+		}
+	}
+	if val, exists := args["max_size"]; exists {
+		if num, ok := val.(float64); ok {
+			maxSize = int64(num)
+		}
+	}
+
+	var modifiedAfter, modifiedBefore time.Time
+	if val, exists := args["modified_after"]; exists {
+		if s, ok := val.(string); ok && s != "" {
+			t, err := parseTimeFilter(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid modified_after: %v", err)
+			}
+			modifiedAfter = t
+		}
+	}
+	if val, exists := args["modified_before"]; exists {
+		if s, ok := val.(string); ok && s != "" {
+			t, err := parseTimeFilter(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid modified_before: %v", err)
+			}
+			modifiedBefore = t
+		}
+	}
+
+	respectIgnore := true
+	if val, exists := args["respect_ignore"]; exists {
+		if b, ok := val.(bool); ok {
+			respectIgnore = b
+		}
+	}
+
+	showHidden := false
+	if val, exists := args["show_hidden"]; exists {
+		if b, ok := val.(bool); ok {
+			showHidden = b
+		}
+	}
+
+	maxResults := 200
+	if val, exists := args["max_results"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			if num > 2000 {
+				maxResults = 2000
+			} else {
+				maxResults = int(num)
+			}
+		}
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+
+	root := filepath.Join(cwd, path)
+	info, err := os.Stat(root)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("directory does not exist: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path is not a directory: %s", path)
+	}
+
+	// Ignore rules are resolved relative to the search root
+	var ignoreFiles []string
+	if respectIgnore {
+		ignoreFiles = fsutil.DefaultIgnoreFiles
+	}
+	ignore := fsutil.NewIgnoreMatcher(root, ignoreFiles)
+	if val, exists := args["exclude"]; exists {
+		if list, ok := val.([]interface{}); ok {
+			for _, item := range list {
+				if s, ok := item.(string); ok {
+					ignore.AddPatterns(s)
+				}
+			}
+		}
+	}
+
+	var matches []foundFile
+	err = filepath.WalkDir(root, func(fullPath string, d fs.DirEntry, walkErr error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if walkErr != nil {
+			// Skip unreadable entries instead of aborting the whole search
+			if d != nil && d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if fullPath == root {
+			return nil
+		}
+
+		rel, err := filepath.Rel(root, fullPath)
+		if err != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		depth := strings.Count(rel, "/") + 1
+
+		if d.IsDir() && d.Name() == ".git" {
+			return filepath.SkipDir
+		}
+		if !showHidden && fsutil.IsHidden(d.Name()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ignore.Match(rel, d.IsDir()) {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			ignore.LoadDir(rel)
+		}
+
+		if maxDepth > 0 && depth > maxDepth {
+			if d.IsDir() {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+
+		if (entryType == "file" && d.IsDir()) || (entryType == "dir" && !d.IsDir()) {
+			return nil
+		}
+		if matched, _ := doublestar.Match(pattern, rel); !matched {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if !d.IsDir() {
+			if minSize >= 0 && info.Size() < minSize {
+				return nil
+			}
+			if maxSize >= 0 && info.Size() > maxSize {
+				return nil
+			}
+		}
+		if !modifiedAfter.IsZero() && !info.ModTime().After(modifiedAfter) {
+			return nil
+		}
+		if !modifiedBefore.IsZero() && !info.ModTime().Before(modifiedBefore) {
+			return nil
+		}
+
+		matches = append(matches, foundFile{
+			path:    filepath.ToSlash(filepath.Join(path, rel)),
+			isDir:   d.IsDir(),
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %v", err)
+	}
+
+	// Newest first, so recently edited files surface at the top
+	sort.Slice(matches, func(i, j int) bool {
+		if matches[i].modTime.Equal(matches[j].modTime) {
+			return matches[i].path < matches[j].path
+		}
+		return matches[i].modTime.After(matches[j].modTime)
+	})
+
+	total := len(matches)
+	truncated := false
+	if total > maxResults {
+		matches = matches[:maxResults]
+		truncated = true
+	}
+
+	results := make([]map[string]interface{}, 0, len(matches))
+	for _, m := range matches {
+		item := map[string]interface{}{
+			"path":     m.path,
+			"type":     "file",
+			"modified": m.modTime.Format("2006-01-02 15:04:05"),
+		}
+		if m.isDir {
+			item["type"] = "directory"
+		} else {
+			item["size"] = m.size
+		}
+		results = append(results, item)
+	}
+
+	return map[string]interface{}{
+		"path":          path,
+		"pattern":       pattern,
+		"count":         len(results),
+		"total_matches": total,
+		"truncated":     truncated,
+		"results":       results,
+	}, nil
+}
+
+// parseTimeFilter parses an absolute timestamp or a relative age such as '24h' or '7d'
+func parseTimeFilter(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t, nil
+	}
+
+	// Relative ages: Go durations plus a 'd' suffix for days
+	if strings.HasSuffix(value, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(value, "d"), 64)
+		if err == nil {
+			return time.Now().Add(-time.Duration(days * float64(24*time.Hour))), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d), nil
+	}
+
+	return time.Time{}, fmt.Errorf("unrecognized time '%s' (use RFC3339, YYYY-MM-DD or an age like 24h/7d)", value)
+}
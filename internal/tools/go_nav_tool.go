@@ -0,0 +1,816 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// GoNavTool provides Go-aware code navigation built on go/parser, go/ast and go/types
+type GoNavTool struct{}
+
+// goPackage is a parsed Go package in a single directory
+type goPackage struct {
+	dir        string
+	name       string
+	importPath string
+	files      []*ast.File
+}
+
+// goDecl is a declaration found by name
+type goDecl struct {
+	pkg  *goPackage
+	file *ast.File
+	kind string
+	node ast.Node // Node spanning the declaration source
+	doc  *ast.CommentGroup
+	name string
+	recv string
+}
+
+func (g *GoNavTool) Name() string {
+	return "go_nav"
+}
+
+func (g *GoNavTool) Description() string {
+	return "Navigate Go code: list the symbols in a package, find the definition of an identifier, find references to it (type-checked when the package builds), or print the source of a function, method or type by name."
+}
+
+func (g *GoNavTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"operation": map[string]interface{}{
+			"type":        "string",
+			"description": "Operation to perform: 'symbols', 'definition', 'references' or 'source'",
+			"enum":        []string{"symbols", "definition", "references", "source"},
+		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Package directory for 'symbols'; search root (searched recursively) for the other operations. Relative to current working directory (default: '.')",
+		},
+		"name": map[string]interface{}{
+			"type":        "string",
+			"description": "Identifier to look up. Use 'Type.Method' or 'Type.Field' for members and 'pkg.Name' to qualify by package name. Required for definition, references and source",
+		},
+		"include_tests": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Include _test.go files (default: false)",
+		},
+		"exported_only": map[string]interface{}{
+			"type":        "boolean",
+			"description": "For symbols: only list exported identifiers (default: false)",
+		},
+		"max_results": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum number of results to return (default: 100, max: 1000)",
+		},
+	}
+}
+
+func (g *GoNavTool) RequiredParameters() []string {
+	return []string{"operation"}
+}
+
+func (g *GoNavTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	operation, ok := args["operation"].(string)
+	if !ok {
+		return nil, fmt.Errorf("operation parameter must be a string")
+	}
+
+	path := "."
+	if val, exists := args["path"]; exists {
+		if p, ok := val.(string); ok && p != "" {
+			path = p
+		}
+	}
+
+	var name string
+	if val, exists := args["name"]; exists {
+		if n, ok := val.(string); ok {
+			name = strings.TrimSpace(n)
+		}
+	}
+
+	includeTests := false
+	if val, exists := args["include_tests"]; exists {
+		if b, ok := val.(bool); ok {
+			includeTests = b
+		}
+	}
+
+	exportedOnly := false
+	if val, exists := args["exported_only"]; exists {
+		if b, ok := val.(bool); ok {
+			exportedOnly = b
+		}
+	}
+
+	maxResults := 100
+	if val, exists := args["max_results"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			if num > 1000 {
+				maxResults = 1000
+			} else {
+				maxResults = int(num)
+			}
+		}
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(path) {
+		return nil, fmt.Errorf("path must be relative, not absolute: %s", path)
+	}
+	if strings.Contains(path, "..") {
+		return nil, fmt.Errorf("path cannot contain parent directory references (..): %s", path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+
+	fullPath := filepath.Join(cwd, path)
+	info, err := os.Stat(fullPath)
+	if os.IsNotExist(err) {
+		return nil, fmt.Errorf("path does not exist: %s", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to access path: %v", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("path must be a package directory: %s", path)
+	}
+
+	if operation != "symbols" && name == "" {
+		return nil, fmt.Errorf("name parameter is required for %s", operation)
+	}
+
+	fset := token.NewFileSet()
+	switch operation {
+	case "symbols":
+		pkgs, err := parseGoDir(fset, fullPath, includeTests)
+		if err != nil {
+			return nil, err
+		}
+		return g.listSymbols(fset, cwd, path, pkgs, exportedOnly, maxResults)
+	case "definition", "source":
+		pkgs, err := parseGoTree(ctx, fset, fullPath, includeTests)
+		if err != nil {
+			return nil, err
+		}
+		decls := findGoDecls(pkgs, name)
+		if operation == "definition" {
+			return g.definitions(fset, cwd, name, decls, maxResults), nil
+		}
+		return g.sources(fset, cwd, name, decls, maxResults)
+	case "references":
+		pkgs, err := parseGoTree(ctx, fset, fullPath, includeTests)
+		if err != nil {
+			return nil, err
+		}
+		return g.references(ctx, fset, cwd, path, name, pkgs, includeTests, maxResults), nil
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", operation)
+	}
+}
+
+// listSymbols lists top-level declarations of the package(s) in one directory
+func (g *GoNavTool) listSymbols(fset *token.FileSet, cwd, path string, pkgs []*goPackage, exportedOnly bool, maxResults int) (interface{}, error) {
+	if len(pkgs) == 0 {
+		return nil, fmt.Errorf("no Go files found in %s", path)
+	}
+
+	var symbols []map[string]interface{}
+	total := 0
+	for _, pkg := range pkgs {
+		for _, file := range pkg.files {
+			for _, decl := range topLevelDecls(pkg, file) {
+				if exportedOnly && !ast.IsExported(decl.name) {
+					continue
+				}
+				total++
+				if len(symbols) >= maxResults {
+					continue
+				}
+
+				pos := fset.Position(decl.node.Pos())
+				symbol := map[string]interface{}{
+					"name":      decl.name,
+					"kind":      decl.kind,
+					"package":   pkg.name,
+					"file":      relativeTo(cwd, pos.Filename),
+					"line":      pos.Line,
+					"signature": declSignature(fset, decl),
+				}
+				if decl.recv != "" {
+					symbol["receiver"] = decl.recv
+				}
+				symbols = append(symbols, symbol)
+			}
+		}
+	}
+
+	return map[string]interface{}{
+		"operation": "symbols",
+		"path":      path,
+		"count":     len(symbols),
+		"total":     total,
+		"truncated": total > len(symbols),
+		"symbols":   symbols,
+	}, nil
+}
+
+// definitions reports where the named identifier is declared
+func (g *GoNavTool) definitions(fset *token.FileSet, cwd, name string, decls []goDecl, maxResults int) interface{} {
+	var results []map[string]interface{}
+	for _, decl := range decls {
+		if len(results) >= maxResults {
+			break
+		}
+		pos := fset.Position(decl.node.Pos())
+		result := map[string]interface{}{
+			"name":      decl.name,
+			"kind":      decl.kind,
+			"package":   decl.pkg.name,
+			"file":      relativeTo(cwd, pos.Filename),
+			"line":      pos.Line,
+			"column":    pos.Column,
+			"signature": declSignature(fset, decl),
+		}
+		if decl.recv != "" {
+			result["receiver"] = decl.recv
+		}
+		if decl.doc != nil {
+			result["doc"] = strings.TrimSpace(decl.doc.Text())
+		}
+		results = append(results, result)
+	}
+
+	return map[string]interface{}{
+		"operation":   "definition",
+		"name":        name,
+		"found":       len(results) > 0,
+		"count":       len(results),
+		"definitions": results,
+	}
+}
+
+// sources prints the full source of each matching declaration
+func (g *GoNavTool) sources(fset *token.FileSet, cwd, name string, decls []goDecl, maxResults int) (interface{}, error) {
+	if len(decls) == 0 {
+		return nil, fmt.Errorf("no declaration named '%s' found", name)
+	}
+
+	// Whole declarations can be long, so cap the number returned more tightly
+	if maxResults > 5 {
+		maxResults = 5
+	}
+
+	var results []map[string]interface{}
+	for _, decl := range decls {
+		if len(results) >= maxResults {
+			break
+		}
+
+		start := decl.node.Pos()
+		if decl.doc != nil {
+			start = decl.doc.Pos()
+		}
+		startPos := fset.Position(start)
+		endPos := fset.Position(decl.node.End())
+
+		content, err := os.ReadFile(startPos.Filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %v", startPos.Filename, err)
+		}
+		if endPos.Offset > len(content) || startPos.Offset > endPos.Offset {
+			continue
+		}
+
+		results = append(results, map[string]interface{}{
+			"name":       decl.name,
+			"kind":       decl.kind,
+			"package":    decl.pkg.name,
+			"file":       relativeTo(cwd, startPos.Filename),
+			"start_line": startPos.Line,
+			"end_line":   endPos.Line,
+			"source":     string(content[startPos.Offset:endPos.Offset]),
+		})
+	}
+
+	return map[string]interface{}{
+		"operation": "source",
+		"name":      name,
+		"count":     len(results),
+		"total":     len(decls),
+		"sources":   results,
+	}, nil
+}
+
+// references finds uses of the named identifier. Packages that type-check are
+// resolved precisely through go/types; the rest fall back to matching identifiers
+// by name, and their results are marked approximate.
+func (g *GoNavTool) references(ctx context.Context, fset *token.FileSet, cwd, path, name string, pkgs []*goPackage, includeTests bool, maxResults int) interface{} {
+	qualifier, simple := splitQualifiedName(name)
+	exports := loadGoExportData(ctx, cwd, path, includeTests)
+
+	var refs []map[string]interface{}
+	total := 0
+	typedPkgs, syntaxPkgs := 0, 0
+	lineCache := make(map[string][]string)
+
+	addRef := func(pos token.Position, resolution string) {
+		total++
+		if len(refs) >= maxResults {
+			return
+		}
+		refs = append(refs, map[string]interface{}{
+			"file":       relativeTo(cwd, pos.Filename),
+			"line":       pos.Line,
+			"column":     pos.Column,
+			"text":       sourceLine(lineCache, pos.Filename, pos.Line),
+			"resolution": resolution,
+		})
+	}
+
+	for _, pkg := range pkgs {
+		if ctx.Err() != nil {
+			break
+		}
+
+		info, err := typeCheckGoPackage(fset, pkg, exports)
+		if err == nil {
+			typedPkgs++
+			var idents []*ast.Ident
+			for ident := range info.Uses {
+				idents = append(idents, ident)
+			}
+			sort.Slice(idents, func(i, j int) bool { return idents[i].Pos() < idents[j].Pos() })
+			for _, ident := range idents {
+				if matchesTypesObject(info.Uses[ident], qualifier, simple) {
+					addRef(fset.Position(ident.Pos()), "types")
+				}
+			}
+			continue
+		}
+
+		// Syntax-only fallback
+		syntaxPkgs++
+		for _, file := range pkg.files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if ident, ok := n.(*ast.Ident); ok && ident.Name == simple {
+					if ident.Obj == nil || ident.Obj.Pos() != ident.Pos() {
+						addRef(fset.Position(ident.Pos()), "syntax")
+					}
+				}
+				return true
+			})
+		}
+	}
+
+	return map[string]interface{}{
+		"operation":         "references",
+		"name":              name,
+		"count":             len(refs),
+		"total":             total,
+		"truncated":         total > len(refs),
+		"typed_packages":    typedPkgs,
+		"approximate":       syntaxPkgs > 0,
+		"syntax_packages":   syntaxPkgs,
+		"references":        refs,
+		"packages_searched": len(pkgs),
+	}
+}
+
+// parseGoDir parses the Go files of a single directory, grouped by package name
+func parseGoDir(fset *token.FileSet, dir string, includeTests bool) ([]*goPackage, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read directory: %v", err)
+	}
+
+	byName := make(map[string]*goPackage)
+	var order []string
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasSuffix(name, ".go") {
+			continue
+		}
+		if !includeTests && strings.HasSuffix(name, "_test.go") {
+			continue
+		}
+
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil && file == nil {
+			continue
+		}
+
+		pkgName := file.Name.Name
+		pkg, exists := byName[pkgName]
+		if !exists {
+			pkg = &goPackage{dir: dir, name: pkgName, importPath: goImportPath(dir)}
+			if strings.HasSuffix(pkgName, "_test") {
+				pkg.importPath += "_test"
+			}
+			byName[pkgName] = pkg
+			order = append(order, pkgName)
+		}
+		pkg.files = append(pkg.files, file)
+	}
+
+	pkgs := make([]*goPackage, 0, len(order))
+	for _, name := range order {
+		pkgs = append(pkgs, byName[name])
+	}
+	return pkgs, nil
+}
+
+// parseGoTree parses every Go package below root
+func parseGoTree(ctx context.Context, fset *token.FileSet, root string, includeTests bool) ([]*goPackage, error) {
+	var pkgs []*goPackage
+	err := filepath.WalkDir(root, func(dir string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil || !d.IsDir() {
+			return nil
+		}
+		if dir != root {
+			name := d.Name()
+			if strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") || name == "vendor" || name == "testdata" || name == "node_modules" {
+				return filepath.SkipDir
+			}
+		}
+
+		dirPkgs, err := parseGoDir(fset, dir, includeTests)
+		if err == nil {
+			pkgs = append(pkgs, dirPkgs...)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan packages: %v", err)
+	}
+	return pkgs, nil
+}
+
+// topLevelDecls lists the named top-level declarations of a file
+func topLevelDecls(pkg *goPackage, file *ast.File) []goDecl {
+	var decls []goDecl
+	for _, d := range file.Decls {
+		switch decl := d.(type) {
+		case *ast.FuncDecl:
+			item := goDecl{pkg: pkg, file: file, kind: "func", node: decl, doc: decl.Doc, name: decl.Name.Name}
+			if decl.Recv != nil && len(decl.Recv.List) > 0 {
+				item.kind = "method"
+				item.recv = receiverTypeName(decl.Recv.List[0].Type)
+			}
+			decls = append(decls, item)
+		case *ast.GenDecl:
+			for _, spec := range decl.Specs {
+				// Single-spec declarations keep the 'type'/'var' keyword and doc comment
+				var node ast.Node = spec
+				doc := decl.Doc
+				if len(decl.Specs) == 1 {
+					node = decl
+				}
+
+				switch s := spec.(type) {
+				case *ast.TypeSpec:
+					if s.Doc != nil {
+						doc = s.Doc
+					}
+					kind := "type"
+					switch s.Type.(type) {
+					case *ast.StructType:
+						kind = "struct"
+					case *ast.InterfaceType:
+						kind = "interface"
+					}
+					decls = append(decls, goDecl{pkg: pkg, file: file, kind: kind, node: node, doc: doc, name: s.Name.Name})
+				case *ast.ValueSpec:
+					if s.Doc != nil {
+						doc = s.Doc
+					}
+					kind := "var"
+					if decl.Tok == token.CONST {
+						kind = "const"
+					}
+					for _, ident := range s.Names {
+						if ident.Name == "_" {
+							continue
+						}
+						decls = append(decls, goDecl{pkg: pkg, file: file, kind: kind, node: node, doc: doc, name: ident.Name})
+					}
+				}
+			}
+		}
+	}
+	return decls
+}
+
+// findGoDecls finds declarations matching a plain, 'Type.Member' or 'pkg.Name' identifier
+func findGoDecls(pkgs []*goPackage, name string) []goDecl {
+	qualifier, simple := splitQualifiedName(name)
+
+	var decls []goDecl
+	for _, pkg := range pkgs {
+		for _, file := range pkg.files {
+			for _, decl := range topLevelDecls(pkg, file) {
+				if decl.name != simple {
+					continue
+				}
+				if qualifier == "" || decl.recv == qualifier || (decl.recv == "" && pkg.name == qualifier) {
+					decls = append(decls, decl)
+				}
+			}
+
+			if qualifier != "" {
+				decls = append(decls, findMemberDecls(pkg, file, qualifier, simple)...)
+			}
+		}
+	}
+	return decls
+}
+
+// findMemberDecls finds struct fields and interface methods named member on typeName
+func findMemberDecls(pkg *goPackage, file *ast.File, typeName, member string) []goDecl {
+	var decls []goDecl
+	ast.Inspect(file, func(n ast.Node) bool {
+		spec, ok := n.(*ast.TypeSpec)
+		if !ok || spec.Name.Name != typeName {
+			return true
+		}
+
+		var fields *ast.FieldList
+		kind := "field"
+		switch t := spec.Type.(type) {
+		case *ast.StructType:
+			fields = t.Fields
+		case *ast.InterfaceType:
+			fields = t.Methods
+			kind = "interface method"
+		}
+		if fields == nil {
+			return false
+		}
+
+		for _, field := range fields.List {
+			for _, ident := range field.Names {
+				if ident.Name == member {
+					decls = append(decls, goDecl{pkg: pkg, file: file, kind: kind, node: field, doc: field.Doc, name: member, recv: typeName})
+				}
+			}
+		}
+		return false
+	})
+	return decls
+}
+
+// declSignature renders a one-line summary of a declaration
+func declSignature(fset *token.FileSet, decl goDecl) string {
+	var node interface{} = decl.node
+	switch n := decl.node.(type) {
+	case *ast.FuncDecl:
+		// Drop the body and doc comment so only the signature is printed
+		stripped := *n
+		stripped.Body = nil
+		stripped.Doc = nil
+		node = &stripped
+	case *ast.GenDecl:
+		stripped := *n
+		stripped.Doc = nil
+		node = &stripped
+	}
+
+	var buf bytes.Buffer
+	if err := printer.Fprint(&buf, fset, node); err != nil {
+		return ""
+	}
+
+	signature := buf.String()
+	switch decl.kind {
+	case "struct", "interface":
+		// Members are available through 'source'; keep the header only
+		if idx := strings.Index(signature, "{"); idx >= 0 {
+			signature = strings.TrimSpace(signature[:idx])
+		}
+	}
+	if idx := strings.Index(signature, "\n"); idx >= 0 {
+		signature = signature[:idx] + " ..."
+	}
+	return signature
+}
+
+// receiverTypeName extracts the base type name of a method receiver
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
+// splitQualifiedName splits 'Qualifier.Name' into its parts
+func splitQualifiedName(name string) (string, string) {
+	if idx := strings.LastIndex(name, "."); idx >= 0 {
+		return name[:idx], name[idx+1:]
+	}
+	return "", name
+}
+
+// matchesTypesObject reports whether a resolved object is the one being searched for
+func matchesTypesObject(obj types.Object, qualifier, simple string) bool {
+	if obj == nil || obj.Name() != simple {
+		return false
+	}
+	if qualifier == "" {
+		return true
+	}
+
+	// Qualified by package name
+	if obj.Pkg() != nil && obj.Pkg().Name() == qualifier && obj.Pkg().Scope().Lookup(simple) == obj {
+		return true
+	}
+
+	// Qualified by receiver type
+	if fn, ok := obj.(*types.Func); ok {
+		if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+			return namedTypeName(sig.Recv().Type()) == qualifier
+		}
+	}
+	if v, ok := obj.(*types.Var); ok && v.IsField() {
+		return fieldOwnedBy(v, qualifier)
+	}
+	return false
+}
+
+// fieldOwnedBy reports whether field belongs to the named struct type
+// qualifier, directly or through an embedded field. Fields do not record
+// their owner, so the type is looked up in the field's package.
+func fieldOwnedBy(field *types.Var, qualifier string) bool {
+	if field.Pkg() == nil {
+		return false
+	}
+	owner, ok := field.Pkg().Scope().Lookup(qualifier).(*types.TypeName)
+	if !ok {
+		return false
+	}
+	found, _, _ := types.LookupFieldOrMethod(owner.Type(), true, field.Pkg(), field.Name())
+	v, ok := found.(*types.Var)
+	// Fields of an instantiated generic type are copies of the declared ones
+	return ok && v.Origin() == field.Origin()
+}
+
+// namedTypeName returns the name of a (possibly pointer) named type
+func namedTypeName(t types.Type) string {
+	if ptr, ok := t.(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	switch named := t.(type) {
+	case *types.Named:
+		return named.Obj().Name()
+	case *types.Alias:
+		return named.Obj().Name()
+	}
+	return ""
+}
+
+// loadGoExportData asks the go command for compiled export data of every
+// package (and dependency) under path, keyed by import path. Returns nil when
+// the go command is unavailable or the tree is not a module.
+func loadGoExportData(ctx context.Context, cwd, path string, includeTests bool) map[string]string {
+	pattern := "./" + filepath.ToSlash(filepath.Clean(path)) + "/..."
+	if filepath.Clean(path) == "." {
+		pattern = "./..."
+	}
+
+	args := []string{"list", "-e", "-export", "-deps", "-f", "{{.ImportPath}}\t{{.Export}}"}
+	if includeTests {
+		args = append(args, "-test")
+	}
+	args = append(args, pattern)
+
+	cmd := exec.CommandContext(ctx, "go", args...)
+	cmd.Dir = cwd
+	output, err := cmd.Output()
+	if err != nil && len(output) == 0 {
+		return nil
+	}
+
+	exports := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(output))
+	for scanner.Scan() {
+		parts := strings.SplitN(scanner.Text(), "\t", 2)
+		// Skip test variants such as "pkg [pkg.test]"
+		if len(parts) != 2 || parts[1] == "" || strings.Contains(parts[0], " ") {
+			continue
+		}
+		exports[parts[0]] = parts[1]
+	}
+	return exports
+}
+
+// typeCheckGoPackage type-checks a parsed package against compiled export data
+func typeCheckGoPackage(fset *token.FileSet, pkg *goPackage, exports map[string]string) (*types.Info, error) {
+	if exports == nil {
+		return nil, fmt.Errorf("no export data available")
+	}
+
+	lookup := func(path string) (io.ReadCloser, error) {
+		file, ok := exports[path]
+		if !ok {
+			return nil, fmt.Errorf("no export data for %s", path)
+		}
+		return os.Open(file)
+	}
+
+	var firstErr error
+	conf := types.Config{
+		Importer: importer.ForCompiler(fset, "gc", lookup),
+		Error: func(err error) {
+			if firstErr == nil {
+				firstErr = err
+			}
+		},
+	}
+	info := &types.Info{
+		Defs: make(map[*ast.Ident]types.Object),
+		Uses: make(map[*ast.Ident]types.Object),
+	}
+
+	conf.Check(pkg.importPath, fset, pkg.files, info)
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return info, nil
+}
+
+// goImportPath derives a directory's import path from the nearest go.mod
+func goImportPath(dir string) string {
+	for current := dir; ; {
+		data, err := os.ReadFile(filepath.Join(current, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				line = strings.TrimSpace(line)
+				if strings.HasPrefix(line, "module ") {
+					modulePath := strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), "\"")
+					rel, err := filepath.Rel(current, dir)
+					if err != nil || rel == "." {
+						return modulePath
+					}
+					return modulePath + "/" + filepath.ToSlash(rel)
+				}
+			}
+		}
+
+		parent := filepath.Dir(current)
+		if parent == current {
+			return filepath.Base(dir)
+		}
+		current = parent
+	}
+}
+
+// sourceLine returns a trimmed line of a file, caching file contents
+func sourceLine(cache map[string][]string, filename string, line int) string {
+	lines, ok := cache[filename]
+	if !ok {
+		data, err := os.ReadFile(filename)
+		if err == nil {
+			lines = strings.Split(string(data), "\n")
+		}
+		cache[filename] = lines
+	}
+	if line < 1 || line > len(lines) {
+		return ""
+	}
+	return strings.TrimSpace(lines[line-1])
+}
+
+// relativeTo makes a path relative to base when possible
+func relativeTo(base, path string) string {
+	if rel, err := filepath.Rel(base, path); err == nil {
+		return filepath.ToSlash(rel)
+	}
+	return path
+}
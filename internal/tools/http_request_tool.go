@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	neturl "net/url"
 	"strings"
 	"time"
 )
@@ -14,6 +15,9 @@ import (
 // HttpRequestTool makes HTTP requests
 type HttpRequestTool struct {
 	confirmator Confirmator
+	outputs     *OutputStore
+	egress      *EgressPolicy
+	credentials *CredentialStore
 }
 
 func (h *HttpRequestTool) Name() string {
@@ -21,7 +25,11 @@ func (h *HttpRequestTool) Name() string {
 }
 
 func (h *HttpRequestTool) Description() string {
-	return "Make HTTP requests to APIs and web services"
+	description := "Make HTTP requests to APIs and web services"
+	if creds := h.credentials.Describe(); creds != "" {
+		description += ". Configured credentials are added automatically to requests for their hosts and redacted from the result; do not set authentication headers for these hosts yourself: " + creds
+	}
+	return description
 }
 
 func (h *HttpRequestTool) Parameters() map[string]interface{} {
@@ -62,6 +70,18 @@ func (h *HttpRequestTool) SetConfirmator(confirmator Confirmator) {
 	h.confirmator = confirmator
 }
 
+func (h *HttpRequestTool) SetOutputStore(store *OutputStore) {
+	h.outputs = store
+}
+
+func (h *HttpRequestTool) SetEgressPolicy(policy *EgressPolicy) {
+	h.egress = policy
+}
+
+func (h *HttpRequestTool) SetCredentials(store *CredentialStore) {
+	h.credentials = store
+}
+
 func (h *HttpRequestTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	url, ok := args["url"].(string)
 	if !ok {
@@ -91,6 +111,15 @@ func (h *HttpRequestTool) Execute(ctx context.Context, args map[string]interface
 		return nil, fmt.Errorf("unsupported HTTP method: %s", method)
 	}
 
+	// Refuse destinations outside the egress policy before asking the user
+	target, err := neturl.Parse(url)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	if err := h.egress.CheckURL(target); err != nil {
+		return nil, err
+	}
+
 	// Ask for confirmation for potentially dangerous requests
 	if h.confirmator != nil {
 		dangerous := method != "GET" && method != "HEAD" && method != "OPTIONS"
@@ -140,10 +169,12 @@ func (h *HttpRequestTool) Execute(ctx context.Context, args map[string]interface
 	// Set User-Agent
 	req.Header.Set("User-Agent", "RoriCode-HttpTool/1.0")
 
-	// Create client with timeout
-	client := &http.Client{
-		Timeout: time.Duration(timeout) * time.Second,
-	}
+	// Create client with timeout; redirects and connections are checked
+	// against the egress policy
+	client := h.egress.Client(time.Duration(timeout) * time.Second)
+
+	// Credentials are added as the request is sent, so the model never sees them
+	secrets := h.credentials.withCredentials(client)
 
 	// Make request
 	resp, err := client.Do(req)
@@ -152,11 +183,22 @@ func (h *HttpRequestTool) Execute(ctx context.Context, args map[string]interface
 	}
 	defer resp.Body.Close()
 
-	// Read response body
-	respBody, err := io.ReadAll(resp.Body)
+	// Read response body; large bodies spill to a file instead of memory
+	respBody, err := h.egress.LimitBody(resp)
 	if err != nil {
+		return nil, err
+	}
+	capture := h.outputs.Writer()
+	redacted := &redactingWriter{w: capture, secrets: secrets}
+	if _, err := io.Copy(redacted, respBody); err != nil {
+		capture.Finish()
 		return nil, fmt.Errorf("failed to read response: %v", err)
 	}
+	if err := redacted.Flush(); err != nil {
+		capture.Finish()
+		return nil, fmt.Errorf("failed to read response: %v", err)
+	}
+	body := capture.Finish()
 
 	// Parse response headers
 	responseHeaders := make(map[string]string)
@@ -169,8 +211,8 @@ func (h *HttpRequestTool) Execute(ctx context.Context, args map[string]interface
 	// Try to parse JSON response
 	var jsonResponse interface{}
 	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "application/json") {
-		json.Unmarshal(respBody, &jsonResponse)
+	if strings.Contains(contentType, "application/json") && !body.Truncated {
+		json.Unmarshal([]byte(body.Text), &jsonResponse)
 	}
 
 	result := map[string]interface{}{
@@ -179,13 +221,22 @@ func (h *HttpRequestTool) Execute(ctx context.Context, args map[string]interface
 		"status_code":    resp.StatusCode,
 		"status":         resp.Status,
 		"headers":        responseHeaders,
-		"body":           string(respBody),
-		"content_length": len(respBody),
+		"body":           body.Text,
+		"content_length": body.Bytes,
+	}
+	if body.Truncated {
+		result["body_truncated"] = true
+		if body.Handle != "" {
+			result["body_handle"] = body.Handle
+		}
 	}
 
 	if jsonResponse != nil {
 		result["json"] = jsonResponse
 	}
+	if names := secrets.names(); len(names) > 0 {
+		result["credentials"] = names
+	}
 
-	return result, nil
+	return secrets.redact(result), nil
 }
\ No newline at end of file
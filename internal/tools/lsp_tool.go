@@ -0,0 +1,433 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/Rorical/RoriCode/internal/lsp"
+)
+
+// lspTool holds what the LSP tools share: the server manager and argument handling
+type lspTool struct {
+	manager *lsp.Manager
+}
+
+// maxLSPLocations bounds the number of locations returned
+const maxLSPLocations = 200
+
+// lspPositionParameters are the parameters of tools that act on a position in a file
+func lspPositionParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "File path relative to current working directory",
+		},
+		"line": map[string]interface{}{
+			"type":        "number",
+			"description": "Line number (1-based)",
+		},
+		"symbol": map[string]interface{}{
+			"type":        "string",
+			"description": "Identifier on that line to query; its first occurrence is used (alternative to column)",
+		},
+		"column": map[string]interface{}{
+			"type":        "number",
+			"description": "Column (1-based, in characters) of the identifier on that line",
+		},
+	}
+}
+
+// client validates the path argument and returns the running server for it
+func (l *lspTool) client(ctx context.Context, args map[string]interface{}) (*lsp.Client, string, string, error) {
+	path, ok := args["path"].(string)
+	if !ok || path == "" {
+		return nil, "", "", fmt.Errorf("path parameter must be a non-empty string")
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(path) {
+		return nil, "", "", fmt.Errorf("path must be relative, not absolute: %s", path)
+	}
+	if strings.Contains(path, "..") {
+		return nil, "", "", fmt.Errorf("path cannot contain parent directory references (..): %s", path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to get current directory: %v", err)
+	}
+	fullPath := filepath.Join(cwd, path)
+
+	info, err := os.Stat(fullPath)
+	if os.IsNotExist(err) {
+		return nil, "", "", fmt.Errorf("file does not exist: %s", path)
+	}
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to access file: %v", err)
+	}
+	if info.IsDir() {
+		return nil, "", "", fmt.Errorf("path is a directory, not a file: %s", path)
+	}
+
+	if l.manager == nil {
+		return nil, "", "", fmt.Errorf("language servers are not available")
+	}
+	client, err := l.manager.ClientFor(ctx, cwd, fullPath)
+	if err != nil {
+		return nil, "", "", err
+	}
+	return client, fullPath, cwd, nil
+}
+
+// position resolves the line and symbol/column arguments to an LSP position
+func (l *lspTool) position(fullPath string, args map[string]interface{}) (lsp.Position, error) {
+	lineNum, ok := args["line"].(float64)
+	if !ok || lineNum < 1 {
+		return lsp.Position{}, fmt.Errorf("line parameter must be a positive number")
+	}
+
+	lines, err := readLines(fullPath)
+	if err != nil {
+		return lsp.Position{}, err
+	}
+	if int(lineNum) > len(lines) {
+		return lsp.Position{}, fmt.Errorf("line %d is beyond end of file (%d lines)", int(lineNum), len(lines))
+	}
+	line := lines[int(lineNum)-1]
+
+	var col int // Zero-based rune offset
+	if symbol, ok := args["symbol"].(string); ok && symbol != "" {
+		col = findIdentifier(line, symbol)
+		if col < 0 {
+			return lsp.Position{}, fmt.Errorf("symbol %q not found on line %d", symbol, int(lineNum))
+		}
+	} else if column, ok := args["column"].(float64); ok && column >= 1 {
+		col = int(column) - 1
+	} else {
+		return lsp.Position{}, fmt.Errorf("either symbol or column is required")
+	}
+
+	return lsp.Position{Line: int(lineNum) - 1, Character: lsp.UTF16Offset(line, col)}, nil
+}
+
+// findIdentifier returns the rune offset of symbol in line, preferring a whole-word match
+func findIdentifier(line, symbol string) int {
+	runes := []rune(line)
+	target := []rune(symbol)
+	first := -1
+
+	isIdent := func(r rune) bool { return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) }
+	for i := 0; i+len(target) <= len(runes); i++ {
+		if string(runes[i:i+len(target)]) != symbol {
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		before := i == 0 || !isIdent(runes[i-1])
+		after := i+len(target) == len(runes) || !isIdent(runes[i+len(target)])
+		if before && after {
+			return i
+		}
+	}
+	return first
+}
+
+// readLines reads a file split into lines
+func readLines(path string) ([]string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %v", err)
+	}
+	return strings.Split(strings.ReplaceAll(string(content), "\r\n", "\n"), "\n"), nil
+}
+
+// formatLocations converts LSP locations into 1-based, cwd-relative entries
+// with the source line of each
+func formatLocations(cwd string, locations []lsp.Location) []map[string]interface{} {
+	cache := make(map[string][]string)
+	var results []map[string]interface{}
+
+	for _, loc := range locations {
+		if len(results) >= maxLSPLocations {
+			break
+		}
+		path := lsp.URIToPath(loc.URI)
+
+		lines, ok := cache[path]
+		if !ok {
+			lines, _ = readLines(path)
+			cache[path] = lines
+		}
+
+		entry := map[string]interface{}{
+			"path":   relativeTo(cwd, path),
+			"line":   loc.Range.Start.Line + 1,
+			"column": loc.Range.Start.Character + 1,
+		}
+		if loc.Range.Start.Line < len(lines) {
+			text := lines[loc.Range.Start.Line]
+			entry["column"] = lsp.RuneOffset(text, loc.Range.Start.Character) + 1
+			entry["text"] = strings.TrimSpace(text)
+		}
+		results = append(results, entry)
+	}
+	return results
+}
+
+// LspDiagnosticsTool reports language server diagnostics for a file
+type LspDiagnosticsTool struct {
+	lspTool
+}
+
+func (l *LspDiagnosticsTool) Name() string {
+	return "lsp_diagnostics"
+}
+
+func (l *LspDiagnosticsTool) Description() string {
+	return "Get errors and warnings for a file from its language server (gopls, pyright, typescript-language-server). The server is started on first use and kept running; edits made with the file tools are synced automatically."
+}
+
+func (l *LspDiagnosticsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "File path relative to current working directory",
+		},
+		"wait": map[string]interface{}{
+			"type":        "number",
+			"description": "Seconds to wait for the server to analyse the file (default: 5, max: 60)",
+		},
+	}
+}
+
+func (l *LspDiagnosticsTool) RequiredParameters() []string {
+	return []string{"path"}
+}
+
+func (l *LspDiagnosticsTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	wait := 5.0
+	if val, exists := args["wait"]; exists {
+		if w, ok := val.(float64); ok && w > 0 {
+			if w > 60 {
+				wait = 60
+			} else {
+				wait = w
+			}
+		}
+	}
+
+	client, fullPath, cwd, err := l.client(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+
+	diags, complete, err := client.Diagnostics(ctx, fullPath, time.Duration(wait*float64(time.Second)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get diagnostics: %v", err)
+	}
+
+	lines, _ := readLines(fullPath)
+	column := func(pos lsp.Position) int {
+		if pos.Line < len(lines) {
+			return lsp.RuneOffset(lines[pos.Line], pos.Character) + 1
+		}
+		return pos.Character + 1
+	}
+
+	entries := make([]map[string]interface{}, 0, len(diags))
+	errors, warnings := 0, 0
+	for _, d := range diags {
+		severity := d.SeverityName()
+		switch severity {
+		case "error":
+			errors++
+		case "warning":
+			warnings++
+		}
+		entry := map[string]interface{}{
+			"line":       d.Range.Start.Line + 1,
+			"column":     column(d.Range.Start),
+			"end_line":   d.Range.End.Line + 1,
+			"end_column": column(d.Range.End),
+			"severity":   severity,
+			"message":    d.Message,
+		}
+		if d.Source != "" {
+			entry["source"] = d.Source
+		}
+		if code := d.CodeString(); code != "" {
+			entry["code"] = code
+		}
+		entries = append(entries, entry)
+	}
+
+	result := map[string]interface{}{
+		"path":        relativeTo(cwd, fullPath),
+		"count":       len(entries),
+		"errors":      errors,
+		"warnings":    warnings,
+		"diagnostics": entries,
+		"complete":    complete,
+		"summary":     fmt.Sprintf("%d problems (%d errors, %d warnings)", len(entries), errors, warnings),
+	}
+	if !complete {
+		result["note"] = "The server had not finished analysing the file; results may be stale. Retry with a longer wait."
+	}
+	return result, nil
+}
+
+// LspDefinitionTool finds where a symbol is defined using the language server
+type LspDefinitionTool struct {
+	lspTool
+}
+
+func (l *LspDefinitionTool) Name() string {
+	return "lsp_definition"
+}
+
+func (l *LspDefinitionTool) Description() string {
+	return "Go to the definition of the symbol at a position using the file's language server. Give the line and either the symbol name on that line or its column."
+}
+
+func (l *LspDefinitionTool) Parameters() map[string]interface{} {
+	return lspPositionParameters()
+}
+
+func (l *LspDefinitionTool) RequiredParameters() []string {
+	return []string{"path", "line"}
+}
+
+func (l *LspDefinitionTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	client, fullPath, cwd, err := l.client(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := l.position(fullPath, args)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := client.Definition(ctx, fullPath, pos)
+	if err != nil {
+		return nil, fmt.Errorf("definition request failed: %v", err)
+	}
+
+	return map[string]interface{}{
+		"count":       len(locations),
+		"definitions": formatLocations(cwd, locations),
+		"summary":     fmt.Sprintf("Found %d definitions", len(locations)),
+	}, nil
+}
+
+// LspReferencesTool finds references to a symbol using the language server
+type LspReferencesTool struct {
+	lspTool
+}
+
+func (l *LspReferencesTool) Name() string {
+	return "lsp_references"
+}
+
+func (l *LspReferencesTool) Description() string {
+	return "Find all references to the symbol at a position using the file's language server. Give the line and either the symbol name on that line or its column."
+}
+
+func (l *LspReferencesTool) Parameters() map[string]interface{} {
+	params := lspPositionParameters()
+	params["include_declaration"] = map[string]interface{}{
+		"type":        "boolean",
+		"description": "Include the declaration itself in the results (default: false)",
+	}
+	return params
+}
+
+func (l *LspReferencesTool) RequiredParameters() []string {
+	return []string{"path", "line"}
+}
+
+func (l *LspReferencesTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	includeDeclaration := false
+	if val, exists := args["include_declaration"]; exists {
+		if b, ok := val.(bool); ok {
+			includeDeclaration = b
+		}
+	}
+
+	client, fullPath, cwd, err := l.client(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := l.position(fullPath, args)
+	if err != nil {
+		return nil, err
+	}
+
+	locations, err := client.References(ctx, fullPath, pos, includeDeclaration)
+	if err != nil {
+		return nil, fmt.Errorf("references request failed: %v", err)
+	}
+
+	result := map[string]interface{}{
+		"count":      len(locations),
+		"references": formatLocations(cwd, locations),
+		"summary":    fmt.Sprintf("Found %d references", len(locations)),
+	}
+	if len(locations) > maxLSPLocations {
+		result["truncated"] = true
+	}
+	return result, nil
+}
+
+// LspHoverTool shows type information and documentation for a symbol
+type LspHoverTool struct {
+	lspTool
+}
+
+func (l *LspHoverTool) Name() string {
+	return "lsp_hover"
+}
+
+func (l *LspHoverTool) Description() string {
+	return "Show the type signature and documentation of the symbol at a position using the file's language server. Give the line and either the symbol name on that line or its column."
+}
+
+func (l *LspHoverTool) Parameters() map[string]interface{} {
+	return lspPositionParameters()
+}
+
+func (l *LspHoverTool) RequiredParameters() []string {
+	return []string{"path", "line"}
+}
+
+func (l *LspHoverTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	client, fullPath, _, err := l.client(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	pos, err := l.position(fullPath, args)
+	if err != nil {
+		return nil, err
+	}
+
+	text, err := client.Hover(ctx, fullPath, pos)
+	if err != nil {
+		return nil, fmt.Errorf("hover request failed: %v", err)
+	}
+	if text == "" {
+		return map[string]interface{}{
+			"found":   false,
+			"summary": "No hover information at this position",
+		}, nil
+	}
+	return map[string]interface{}{
+		"found":   true,
+		"hover":   text,
+		"summary": "Hover information found",
+	}, nil
+}
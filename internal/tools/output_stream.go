@@ -0,0 +1,146 @@
+package tools
+
+import (
+	"context"
+	"io"
+	"strings"
+	"sync"
+	"time"
+)
+
+// OutputHandler receives lines of live output from a running tool call
+type OutputHandler func(callID, toolName string, lines []string)
+
+// outputSinkKey is the context key for a tool call's output sink
+type outputSinkKey struct{}
+
+// outputFlushInterval batches streamed lines so that fast commands do not
+// flood the UI with events
+const outputFlushInterval = 100 * time.Millisecond
+
+// maxPendingLines bounds a batch; when output outpaces the flush interval
+// only the most recent lines are delivered
+const maxPendingLines = 200
+
+// maxStreamLineLength truncates very long lines before they are streamed
+const maxStreamLineLength = 1000
+
+// withOutputSink returns a context carrying sink for the tool call
+func withOutputSink(ctx context.Context, sink io.Writer) context.Context {
+	return context.WithValue(ctx, outputSinkKey{}, sink)
+}
+
+// outputSink returns the live output writer of the current tool call, or
+// io.Discard when output is not being streamed
+func outputSink(ctx context.Context) io.Writer {
+	if sink, ok := ctx.Value(outputSinkKey{}).(io.Writer); ok {
+		return sink
+	}
+	return io.Discard
+}
+
+// lineSink splits written output into lines and hands them to an
+// OutputHandler in batches
+type lineSink struct {
+	mu      sync.Mutex
+	deliver sync.Mutex // Keeps batches in order
+	handler func(lines []string)
+	partial strings.Builder // Unterminated last line
+	pending []string
+	timer   *time.Timer
+	closed  bool
+}
+
+func newLineSink(handler func(lines []string)) *lineSink {
+	return &lineSink{handler: handler}
+}
+
+func (s *lineSink) Write(p []byte) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return len(p), nil
+	}
+
+	text := strings.ReplaceAll(string(p), "\r\n", "\n")
+	for {
+		i := strings.IndexByte(text, '\n')
+		if i < 0 {
+			break
+		}
+		s.appendPartial(text[:i])
+		s.pending = append(s.pending, streamLine(s.partial.String()))
+		s.partial.Reset()
+		text = text[i+1:]
+	}
+	if over := len(s.pending) - maxPendingLines; over > 0 {
+		s.pending = append(s.pending[:0], s.pending[over:]...)
+	}
+	s.appendPartial(text)
+
+	if len(s.pending) > 0 && s.timer == nil {
+		s.timer = time.AfterFunc(outputFlushInterval, s.flush)
+	}
+	return len(p), nil
+}
+
+// appendPartial adds text to the current line, keeping just enough of an
+// overlong line for streamLine to mark it as shortened
+func (s *lineSink) appendPartial(text string) {
+	if room := maxStreamLineLength + 1 - s.partial.Len(); room > 0 {
+		if len(text) > room {
+			text = text[:room]
+		}
+		s.partial.WriteString(text)
+	}
+}
+
+// flush delivers the pending lines
+func (s *lineSink) flush() {
+	s.deliver.Lock()
+	defer s.deliver.Unlock()
+
+	s.mu.Lock()
+	lines := s.pending
+	s.pending = nil
+	s.timer = nil
+	s.mu.Unlock()
+
+	if len(lines) > 0 {
+		s.handler(lines)
+	}
+}
+
+// Close delivers the remaining output, including an unterminated last line
+func (s *lineSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	if s.timer != nil {
+		s.timer.Stop()
+		s.timer = nil
+	}
+	if s.partial.Len() > 0 {
+		s.pending = append(s.pending, streamLine(s.partial.String()))
+		s.partial.Reset()
+	}
+	s.mu.Unlock()
+
+	s.flush()
+	return nil
+}
+
+// streamLine prepares a line for display: carriage-return progress updates
+// keep only their final state and long lines are shortened
+func streamLine(line string) string {
+	if i := strings.LastIndexByte(line, '\r'); i >= 0 {
+		line = line[i+1:]
+	}
+	if len(line) > maxStreamLineLength {
+		line = line[:maxStreamLineLength] + "…"
+	}
+	return line
+}
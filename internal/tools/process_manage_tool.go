@@ -0,0 +1,629 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// processBufferSize is the amount of output retained per process
+	processBufferSize = 1024 * 1024
+	// defaultOutputRead is the default amount of output returned per read
+	defaultOutputRead = 16 * 1024
+	// killGracePeriod is how long kill waits after SIGTERM before SIGKILL
+	killGracePeriod = 3 * time.Second
+)
+
+// ProcessManageTool starts and supervises long-running background processes
+// such as dev servers and watchers
+type ProcessManageTool struct {
+	confirmator Confirmator
+	env         *EnvOverlay
+	mu          sync.Mutex
+	processes   map[string]*managedProcess
+	nextID      int
+	closed      bool
+}
+
+// managedProcess is a background process started by the tool
+type managedProcess struct {
+	id         string
+	command    string
+	workingDir string
+	cmd        *exec.Cmd
+	started    time.Time
+	output     *outputBuffer
+	done       chan struct{} // Closed when the process has exited
+
+	mu         sync.Mutex
+	exitCode   int
+	exitErr    string
+	exited     time.Time
+	readOffset int64 // Where the last incremental read ended
+}
+
+// outputBuffer is a ring buffer of combined stdout/stderr addressed by
+// absolute byte offsets, so readers can resume where they left off
+type outputBuffer struct {
+	mu      sync.Mutex
+	data    []byte
+	start   int64 // Offset of data[0] in the full stream
+	max     int
+	changed chan struct{} // Closed and replaced on every write
+}
+
+func newOutputBuffer(max int) *outputBuffer {
+	return &outputBuffer{max: max, changed: make(chan struct{})}
+}
+
+func (b *outputBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.data = append(b.data, p...)
+	if over := len(b.data) - b.max; over > 0 {
+		b.data = append(b.data[:0], b.data[over:]...)
+		b.start += int64(over)
+	}
+	close(b.changed)
+	b.changed = make(chan struct{})
+	return len(p), nil
+}
+
+// read returns up to limit bytes from offset, the offset to continue from and
+// how many requested bytes were already dropped from the buffer
+func (b *outputBuffer) read(offset int64, limit int) (string, int64, int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	end := b.start + int64(len(b.data))
+	var dropped int64
+	if offset < b.start {
+		dropped = b.start - offset
+		offset = b.start
+	}
+	if offset > end {
+		offset = end
+	}
+
+	from := int(offset - b.start)
+	to := len(b.data)
+	if limit > 0 && to-from > limit {
+		to = from + limit
+	}
+	return string(b.data[from:to]), b.start + int64(to), dropped
+}
+
+// end returns the offset just past the last byte written
+func (b *outputBuffer) end() int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.start + int64(len(b.data))
+}
+
+// waitChan returns a channel closed on the next write
+func (b *outputBuffer) waitChan() <-chan struct{} {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.changed
+}
+
+func (t *ProcessManageTool) Name() string {
+	return "process_manage"
+}
+
+func (t *ProcessManageTool) Description() string {
+	return "Manage long-running background processes (dev servers, watchers, databases). Start a command and get an ID, read its output incrementally, list processes, send signals, wait until a log line appears or a port accepts connections, and kill one or all processes. Processes keep running across tool calls and are killed when RoriCode exits."
+}
+
+func (t *ProcessManageTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"operation": map[string]interface{}{
+			"type":        "string",
+			"description": "Operation to perform",
+			"enum":        []string{"start", "output", "list", "signal", "wait_for", "kill", "kill_all"},
+		},
+		"command": map[string]interface{}{
+			"type":        "string",
+			"description": "Shell command to start (for start)",
+		},
+		"working_dir": map[string]interface{}{
+			"type":        "string",
+			"description": "Working directory relative to current working directory (for start, default: '.')",
+		},
+		"id": map[string]interface{}{
+			"type":        "string",
+			"description": "Process ID returned by start (for output, signal, wait_for, kill)",
+		},
+		"offset": map[string]interface{}{
+			"type":        "number",
+			"description": "Output byte offset to read from (for output; default: continue after the previous read, use 0 for the beginning)",
+		},
+		"max_bytes": map[string]interface{}{
+			"type":        "number",
+			"description": "Maximum bytes of output to return (for output, default: 16384)",
+		},
+		"signal": map[string]interface{}{
+			"type":        "string",
+			"description": "Signal name such as SIGINT, SIGTERM, SIGHUP, SIGKILL (for signal)",
+		},
+		"pattern": map[string]interface{}{
+			"type":        "string",
+			"description": "Regular expression to wait for in the output (for wait_for)",
+		},
+		"port": map[string]interface{}{
+			"type":        "number",
+			"description": "TCP port on localhost to wait for (for wait_for)",
+		},
+		"timeout": map[string]interface{}{
+			"type":        "number",
+			"description": "Seconds to wait (for wait_for, default: 30, max: 300)",
+		},
+	}
+}
+
+func (t *ProcessManageTool) RequiredParameters() []string {
+	return []string{"operation"}
+}
+
+func (t *ProcessManageTool) SetConfirmator(confirmator Confirmator) {
+	t.confirmator = confirmator
+}
+
+func (t *ProcessManageTool) SetEnvOverlay(env *EnvOverlay) {
+	t.env = env
+}
+
+func (t *ProcessManageTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	operation, ok := args["operation"].(string)
+	if !ok {
+		return nil, fmt.Errorf("operation parameter must be a string")
+	}
+
+	switch operation {
+	case "start":
+		return t.start(args)
+	case "list":
+		return t.list(), nil
+	case "kill_all":
+		killed := t.killAll()
+		return map[string]interface{}{
+			"killed":  killed,
+			"summary": fmt.Sprintf("Killed %d processes", len(killed)),
+		}, nil
+	}
+
+	id, ok := args["id"].(string)
+	if !ok || id == "" {
+		return nil, fmt.Errorf("id parameter is required for %s", operation)
+	}
+	p, err := t.process(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch operation {
+	case "output":
+		return t.readOutput(p, args), nil
+	case "signal":
+		name, ok := args["signal"].(string)
+		if !ok || name == "" {
+			return nil, fmt.Errorf("signal parameter is required")
+		}
+		if !p.running() {
+			return nil, fmt.Errorf("process %s has already exited", id)
+		}
+		if err := signalProcess(p.cmd.Process, name); err != nil {
+			return nil, fmt.Errorf("failed to send %s to %s: %v", name, id, err)
+		}
+		return map[string]interface{}{
+			"id":      id,
+			"signal":  strings.ToUpper(name),
+			"summary": fmt.Sprintf("Sent %s to %s", strings.ToUpper(name), id),
+		}, nil
+	case "wait_for":
+		return t.waitFor(ctx, p, args)
+	case "kill":
+		p.kill()
+		return map[string]interface{}{
+			"id":      id,
+			"status":  p.info(),
+			"summary": fmt.Sprintf("Killed %s", id),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported operation: %s", operation)
+	}
+}
+
+// start launches a command in the background
+func (t *ProcessManageTool) start(args map[string]interface{}) (interface{}, error) {
+	command, ok := args["command"].(string)
+	if !ok || strings.TrimSpace(command) == "" {
+		return nil, fmt.Errorf("command parameter is required for start")
+	}
+
+	workingDir := "."
+	if val, exists := args["working_dir"]; exists {
+		if s, ok := val.(string); ok && s != "" {
+			workingDir = s
+		}
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(workingDir) {
+		return nil, fmt.Errorf("working_dir must be relative, not absolute: %s", workingDir)
+	}
+	if strings.Contains(workingDir, "..") {
+		return nil, fmt.Errorf("working_dir cannot contain parent directory references (..): %s", workingDir)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+
+	if t.confirmator != nil {
+		if !t.confirmator.RequestConfirmation("Start background process", t.env.confirmationText(command), isDangerousCommand(command) || t.env.HasDangerous()) {
+			return map[string]interface{}{
+				"output":  "User aborted process start",
+				"aborted": true,
+			}, nil
+		}
+	}
+
+	t.mu.Lock()
+	if t.closed {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("process manager is shut down")
+	}
+	t.nextID++
+	id := "proc-" + strconv.Itoa(t.nextID)
+	t.mu.Unlock()
+
+	// Not bound to the tool call context: the process outlives this call
+	cmd := shellCommand(context.Background(), command)
+	cmd.Dir = filepath.Join(cwd, workingDir)
+	cmd.Env = t.env.Environ()
+	output := newOutputBuffer(processBufferSize)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// Don't let grandchildren holding the pipe open block Wait forever
+	cmd.WaitDelay = 2 * time.Second
+	setProcessGroup(cmd)
+
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start process: %v", err)
+	}
+
+	p := &managedProcess{
+		id:         id,
+		command:    command,
+		workingDir: workingDir,
+		cmd:        cmd,
+		started:    time.Now(),
+		output:     output,
+		done:       make(chan struct{}),
+	}
+	go p.wait()
+
+	t.mu.Lock()
+	if t.processes == nil {
+		t.processes = make(map[string]*managedProcess)
+	}
+	t.processes[id] = p
+	t.mu.Unlock()
+
+	// Give the process a moment so immediate failures are reported
+	select {
+	case <-p.done:
+	case <-time.After(500 * time.Millisecond):
+	}
+
+	result := p.info()
+	text, next, _ := output.read(0, defaultOutputRead)
+	p.mu.Lock()
+	p.readOffset = next
+	p.mu.Unlock()
+	result["output"] = text
+	result["next_offset"] = next
+	if p.running() {
+		result["summary"] = fmt.Sprintf("Started %s (pid %d)", id, cmd.Process.Pid)
+	} else {
+		result["summary"] = fmt.Sprintf("%s exited immediately", id)
+	}
+	return result, nil
+}
+
+// process looks up a managed process by ID
+func (t *ProcessManageTool) process(id string) (*managedProcess, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	p, ok := t.processes[id]
+	if !ok {
+		return nil, fmt.Errorf("no process with id %s", id)
+	}
+	return p, nil
+}
+
+// list describes all managed processes
+func (t *ProcessManageTool) list() map[string]interface{} {
+	t.mu.Lock()
+	processes := make([]*managedProcess, 0, len(t.processes))
+	for _, p := range t.processes {
+		processes = append(processes, p)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(processes, func(i, j int) bool {
+		return processes[i].started.Before(processes[j].started)
+	})
+
+	running := 0
+	entries := make([]map[string]interface{}, 0, len(processes))
+	for _, p := range processes {
+		if p.running() {
+			running++
+		}
+		entries = append(entries, p.info())
+	}
+
+	return map[string]interface{}{
+		"processes": entries,
+		"count":     len(entries),
+		"running":   running,
+		"summary":   fmt.Sprintf("%d processes (%d running)", len(entries), running),
+	}
+}
+
+// readOutput returns output from an offset, continuing after the previous read by default
+func (t *ProcessManageTool) readOutput(p *managedProcess, args map[string]interface{}) map[string]interface{} {
+	p.mu.Lock()
+	offset := p.readOffset
+	p.mu.Unlock()
+	if val, exists := args["offset"]; exists {
+		if o, ok := val.(float64); ok && o >= 0 {
+			offset = int64(o)
+		}
+	}
+
+	limit := defaultOutputRead
+	if val, exists := args["max_bytes"]; exists {
+		if m, ok := val.(float64); ok && m > 0 {
+			if m > processBufferSize {
+				limit = processBufferSize
+			} else {
+				limit = int(m)
+			}
+		}
+	}
+
+	text, next, dropped := p.output.read(offset, limit)
+	p.mu.Lock()
+	p.readOffset = next
+	p.mu.Unlock()
+
+	result := p.info()
+	result["output"] = text
+	result["offset"] = offset
+	result["next_offset"] = next
+	result["more"] = next < p.output.end()
+	if dropped > 0 {
+		result["dropped_bytes"] = dropped
+	}
+	return result
+}
+
+// waitFor blocks until the output matches a pattern or a port accepts connections
+func (t *ProcessManageTool) waitFor(ctx context.Context, p *managedProcess, args map[string]interface{}) (interface{}, error) {
+	var re *regexp.Regexp
+	if pattern, ok := args["pattern"].(string); ok && pattern != "" {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern: %v", err)
+		}
+	}
+
+	port := 0
+	if val, exists := args["port"]; exists {
+		if num, ok := val.(float64); ok && num > 0 && num < 65536 {
+			port = int(num)
+		}
+	}
+
+	if re == nil && port == 0 {
+		return nil, fmt.Errorf("wait_for requires a pattern or a port")
+	}
+
+	timeout := 30.0
+	if val, exists := args["timeout"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			if num > 300 {
+				timeout = 300
+			} else {
+				timeout = num
+			}
+		}
+	}
+
+	start := time.Now()
+	deadline := time.NewTimer(time.Duration(timeout * float64(time.Second)))
+	defer deadline.Stop()
+	ticker := time.NewTicker(250 * time.Millisecond)
+	defer ticker.Stop()
+
+	result := map[string]interface{}{"id": p.id}
+	exited := false
+
+	for {
+		changed := p.output.waitChan()
+
+		if re != nil {
+			text, _, _ := p.output.read(0, 0)
+			if loc := re.FindStringIndex(text); loc != nil {
+				lineStart := strings.LastIndex(text[:loc[0]], "\n") + 1
+				lineEnd := len(text)
+				if i := strings.Index(text[loc[1]:], "\n"); i >= 0 {
+					lineEnd = loc[1] + i
+				}
+				result["matched"] = true
+				result["line"] = strings.TrimRight(text[lineStart:lineEnd], "\r")
+				result["elapsed_seconds"] = time.Since(start).Round(time.Millisecond).Seconds()
+				result["summary"] = fmt.Sprintf("Output of %s matched %q", p.id, re.String())
+				return result, nil
+			}
+		}
+		if port != 0 {
+			conn, err := net.DialTimeout("tcp", net.JoinHostPort("127.0.0.1", strconv.Itoa(port)), 200*time.Millisecond)
+			if err == nil {
+				conn.Close()
+				result["matched"] = true
+				result["port"] = port
+				result["elapsed_seconds"] = time.Since(start).Round(time.Millisecond).Seconds()
+				result["summary"] = fmt.Sprintf("Port %d is accepting connections", port)
+				return result, nil
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-ticker.C:
+		case <-p.done:
+			// Check once more for output written just before the exit
+			if !exited {
+				exited = true
+				continue
+			}
+			return t.waitFailed(p, result, "process exited before the condition was met"), nil
+		case <-deadline.C:
+			return t.waitFailed(p, result, fmt.Sprintf("timed out after %.0f seconds", timeout)), nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// waitFailed reports an unmet wait_for with the process state and recent output
+func (t *ProcessManageTool) waitFailed(p *managedProcess, result map[string]interface{}, reason string) map[string]interface{} {
+	end := p.output.end()
+	tail, _, _ := p.output.read(end-4096, 0)
+	result["matched"] = false
+	result["reason"] = reason
+	result["status"] = p.info()
+	result["output_tail"] = tail
+	result["summary"] = fmt.Sprintf("Wait on %s failed: %s", p.id, reason)
+	return result
+}
+
+// killAll kills every running process and returns their IDs
+func (t *ProcessManageTool) killAll() []string {
+	t.mu.Lock()
+	var processes []*managedProcess
+	for _, p := range t.processes {
+		if p.running() {
+			processes = append(processes, p)
+		}
+	}
+	t.mu.Unlock()
+
+	var wg sync.WaitGroup
+	killed := make([]string, 0, len(processes))
+	for _, p := range processes {
+		killed = append(killed, p.id)
+		wg.Add(1)
+		go func(p *managedProcess) {
+			defer wg.Done()
+			p.kill()
+		}(p)
+	}
+	wg.Wait()
+	sort.Strings(killed)
+	return killed
+}
+
+// Close kills all managed processes; called when the chat service stops
+func (t *ProcessManageTool) Close() error {
+	t.mu.Lock()
+	t.closed = true
+	t.mu.Unlock()
+	t.killAll()
+	return nil
+}
+
+// wait records the exit status once the process ends
+func (p *managedProcess) wait() {
+	err := p.cmd.Wait()
+
+	p.mu.Lock()
+	p.exited = time.Now()
+	p.exitCode = p.cmd.ProcessState.ExitCode()
+	if err != nil {
+		p.exitErr = err.Error()
+	}
+	p.mu.Unlock()
+	close(p.done)
+}
+
+// running reports whether the process is still alive
+func (p *managedProcess) running() bool {
+	select {
+	case <-p.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// kill terminates the process gracefully, then forcibly after a grace period
+func (p *managedProcess) kill() {
+	if !p.running() {
+		return
+	}
+	if err := signalProcess(p.cmd.Process, "SIGTERM"); err == nil {
+		select {
+		case <-p.done:
+			return
+		case <-time.After(killGracePeriod):
+		}
+	}
+	killProcessTree(p.cmd.Process)
+	<-p.done
+}
+
+// info describes the process
+func (p *managedProcess) info() map[string]interface{} {
+	info := map[string]interface{}{
+		"id":          p.id,
+		"command":     p.command,
+		"working_dir": p.workingDir,
+		"pid":         p.cmd.Process.Pid,
+		"started":     p.started.Format(time.RFC3339),
+		"output_size": p.output.end(),
+	}
+
+	if p.running() {
+		info["status"] = "running"
+		info["uptime_seconds"] = int(time.Since(p.started).Seconds())
+		return info
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	info["status"] = "exited"
+	info["exit_code"] = p.exitCode
+	info["runtime_seconds"] = int(p.exited.Sub(p.started).Seconds())
+	if p.exitErr != "" && p.exitCode != 0 {
+		info["error"] = p.exitErr
+	}
+	return info
+}
@@ -0,0 +1,53 @@
+//go:build !windows
+
+package tools
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"syscall"
+)
+
+// processSignals lists the signals that can be sent to managed processes
+var processSignals = map[string]syscall.Signal{
+	"SIGINT":  syscall.SIGINT,
+	"SIGTERM": syscall.SIGTERM,
+	"SIGKILL": syscall.SIGKILL,
+	"SIGHUP":  syscall.SIGHUP,
+	"SIGQUIT": syscall.SIGQUIT,
+	"SIGUSR1": syscall.SIGUSR1,
+	"SIGUSR2": syscall.SIGUSR2,
+}
+
+// setProcessGroup starts the command in its own process group so signals
+// reach the children of the shell as well
+func setProcessGroup(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+}
+
+// signalProcess sends a named signal to the process group of p
+func signalProcess(p *os.Process, name string) error {
+	name = strings.ToUpper(name)
+	if !strings.HasPrefix(name, "SIG") {
+		name = "SIG" + name
+	}
+	sig, ok := processSignals[name]
+	if !ok {
+		return fmt.Errorf("unsupported signal: %s", name)
+	}
+	if err := syscall.Kill(-p.Pid, sig); err != nil {
+		// Not a group leader (e.g. already reaped); fall back to the process itself
+		return p.Signal(sig)
+	}
+	return nil
+}
+
+// killProcessTree forcibly kills p and its process group
+func killProcessTree(p *os.Process) error {
+	if err := syscall.Kill(-p.Pid, syscall.SIGKILL); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
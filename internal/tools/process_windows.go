@@ -0,0 +1,31 @@
+//go:build windows
+
+package tools
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// setProcessGroup is a no-op on Windows; trees are killed with taskkill
+func setProcessGroup(cmd *exec.Cmd) {}
+
+// signalProcess supports only termination on Windows
+func signalProcess(p *os.Process, name string) error {
+	switch strings.TrimPrefix(strings.ToUpper(name), "SIG") {
+	case "KILL", "TERM", "INT":
+		return killProcessTree(p)
+	}
+	return fmt.Errorf("unsupported signal on Windows: %s (use SIGTERM or SIGKILL)", name)
+}
+
+// killProcessTree kills p and all of its children
+func killProcessTree(p *os.Process) error {
+	if err := exec.Command("taskkill", "/T", "/F", "/PID", strconv.Itoa(p.Pid)).Run(); err != nil {
+		return p.Kill()
+	}
+	return nil
+}
@@ -0,0 +1,164 @@
+package tools
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+)
+
+// ReadOutputTool pages through tool output that was too large to return whole
+type ReadOutputTool struct {
+	outputs *OutputStore
+}
+
+// maxOutputLineLength shortens very long lines when paging
+const maxOutputLineLength = 2000
+
+func (r *ReadOutputTool) Name() string {
+	return "read_output"
+}
+
+func (r *ReadOutputTool) Description() string {
+	return "Page through the full text of a truncated tool output (e.g. shell output or an HTTP response body) using the handle given in the result (output_handle, body_handle). Read by line range or search with a regex."
+}
+
+func (r *ReadOutputTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"handle": map[string]interface{}{
+			"type":        "string",
+			"description": "Output handle from a truncated tool result (e.g. 'out-1a2b3c4d5e6f')",
+		},
+		"lines_from": map[string]interface{}{
+			"type":        "number",
+			"description": "First line to return (1-based, default: 1)",
+		},
+		"lines_to": map[string]interface{}{
+			"type":        "number",
+			"description": "Last line to return (1-based, default: lines_from + 199, at most 1000 lines per call)",
+		},
+		"regex": map[string]interface{}{
+			"type":        "string",
+			"description": "Return only lines matching this regular expression, within the line range (optional)",
+		},
+	}
+}
+
+func (r *ReadOutputTool) RequiredParameters() []string {
+	return []string{"handle"}
+}
+
+func (r *ReadOutputTool) SetOutputStore(store *OutputStore) {
+	r.outputs = store
+}
+
+func (r *ReadOutputTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	handle, ok := args["handle"].(string)
+	if !ok || handle == "" {
+		return nil, fmt.Errorf("handle parameter must be a non-empty string")
+	}
+	if r.outputs == nil {
+		return nil, fmt.Errorf("unknown output handle: %s", handle)
+	}
+	path, err := r.outputs.Path(handle)
+	if err != nil {
+		return nil, err
+	}
+
+	linesFrom, linesTo := 1, 0
+	if val, exists := args["lines_from"]; exists {
+		if num, ok := val.(float64); ok && num >= 1 {
+			linesFrom = int(num)
+		}
+	}
+	if val, exists := args["lines_to"]; exists {
+		if num, ok := val.(float64); ok && num >= 1 {
+			linesTo = int(num)
+		}
+	}
+	var regex *regexp.Regexp
+	if val, exists := args["regex"]; exists {
+		if pattern, ok := val.(string); ok && pattern != "" {
+			regex, err = regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex pattern: %v", err)
+			}
+		}
+	}
+
+	// Pages hold up to 200 lines by default and 1000 at most; a search
+	// covers the whole output unless a range is given
+	maxLines := 200
+	switch {
+	case linesTo == 0 && regex != nil:
+		linesTo = int(^uint(0) >> 1)
+	case linesTo == 0:
+		linesTo = linesFrom + maxLines - 1
+	case linesTo < linesFrom:
+		return nil, fmt.Errorf("lines_to (%d) is before lines_from (%d)", linesTo, linesFrom)
+	case regex == nil && linesTo-linesFrom+1 > 1000:
+		linesTo = linesFrom + 999
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open output: %v", err)
+	}
+	defer file.Close()
+
+	// Pages are also bounded by the configured output size
+	maxBytes := r.outputs.Limits().MaxBytes
+
+	var content strings.Builder
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	lineNum, returned, lastLine := 0, 0, 0
+	truncated := false
+	for scanner.Scan() {
+		lineNum++
+		if lineNum < linesFrom || lineNum > linesTo {
+			continue
+		}
+		line := scanner.Text()
+		if regex != nil && !regex.MatchString(line) {
+			continue
+		}
+		if len(line) > maxOutputLineLength {
+			line = line[:maxOutputLineLength] + "…"
+		}
+		entry := fmt.Sprintf("%6d\t%s\n", lineNum, line)
+		if truncated || content.Len()+len(entry) > maxBytes || (regex != nil && returned >= maxLines) {
+			truncated = true
+			continue // Keep counting lines for total_lines
+		}
+		content.WriteString(entry)
+		returned++
+		lastLine = lineNum
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read output: %v", err)
+	}
+
+	result := map[string]interface{}{
+		"handle":      handle,
+		"lines_from":  linesFrom,
+		"lines_to":    lastLine,
+		"count":       returned,
+		"total_lines": lineNum,
+		"content":     content.String(),
+	}
+	if truncated {
+		result["truncated"] = true
+	}
+	if regex == nil && lastLine < lineNum && lastLine > 0 {
+		result["next_lines_from"] = lastLine + 1
+	}
+	summary := fmt.Sprintf("Read lines %d-%d of %d from %s", linesFrom, lastLine, lineNum, handle)
+	if regex != nil {
+		summary = fmt.Sprintf("Found %d matching lines in %s", returned, handle)
+	}
+	result["summary"] = summary
+	return result, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"io"
 	"sync"
 )
 
@@ -45,14 +46,26 @@ type ToolResult struct {
 
 // Registry manages available tools
 type Registry struct {
-	tools map[string]Tool
-	mu    sync.RWMutex
+	tools   map[string]Tool
+	changes *ChangeTracker
+	env     *EnvOverlay
+	outputs *OutputStore
+	egress  *EgressPolicy
+	creds   *CredentialStore
+	output  OutputHandler
+	closers []io.Closer
+	mu      sync.RWMutex
 }
 
 // NewRegistry creates a new tool registry
 func NewRegistry() *Registry {
 	return &Registry{
-		tools: make(map[string]Tool),
+		tools:   make(map[string]Tool),
+		changes: NewChangeTracker(),
+		env:     NewEnvOverlay(),
+		outputs: NewOutputStore(),
+		egress:  NewEgressPolicy(),
+		creds:   NewCredentialStore(),
 	}
 }
 
@@ -61,6 +74,60 @@ func (r *Registry) Register(tool Tool) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 	r.tools[tool.Name()] = tool
+	if trackingTool, ok := tool.(ChangeTrackingTool); ok {
+		trackingTool.SetChangeTracker(r.changes)
+	}
+	if envTool, ok := tool.(EnvAwareTool); ok {
+		envTool.SetEnvOverlay(r.env)
+	}
+	if storingTool, ok := tool.(OutputStoringTool); ok {
+		storingTool.SetOutputStore(r.outputs)
+	}
+	if networkTool, ok := tool.(NetworkTool); ok {
+		networkTool.SetEgressPolicy(r.egress)
+	}
+	if credentialTool, ok := tool.(CredentialTool); ok {
+		credentialTool.SetCredentials(r.creds)
+	}
+}
+
+// AddCloser registers a resource (e.g. background processes) to release on Close
+func (r *Registry) AddCloser(closer io.Closer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.closers = append(r.closers, closer)
+}
+
+// Close releases the resources held by tools, in reverse registration order
+func (r *Registry) Close() {
+	r.mu.Lock()
+	closers := r.closers
+	r.closers = nil
+	r.mu.Unlock()
+
+	for i := len(closers) - 1; i >= 0; i-- {
+		closers[i].Close()
+	}
+}
+
+// ChangeTracker returns the tracker shared by all file-modifying tools
+func (r *Registry) ChangeTracker() *ChangeTracker {
+	return r.changes
+}
+
+// OutputStore returns the store that caps tool output and keeps spillover files
+func (r *Registry) OutputStore() *OutputStore {
+	return r.outputs
+}
+
+// EgressPolicy returns the policy that restricts where network tools connect
+func (r *Registry) EgressPolicy() *EgressPolicy {
+	return r.egress
+}
+
+// Credentials returns the credentials that network tools add to requests
+func (r *Registry) Credentials() *CredentialStore {
+	return r.creds
 }
 
 // SetConfirmator sets the confirmator for all confirming tools
@@ -75,6 +142,43 @@ func (r *Registry) SetConfirmator(confirmator Confirmator) {
 	}
 }
 
+// SetOutputHandler sets the receiver of live output streamed by running tools
+func (r *Registry) SetOutputHandler(handler OutputHandler) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.output = handler
+}
+
+// Restrict keeps only the enabled tools (all when enabled is empty) minus
+// the disabled ones. It returns the names that match no tool.
+func (r *Registry) Restrict(enabled, disabled []string) []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	var unknown []string
+	if len(enabled) > 0 {
+		keep := make(map[string]bool)
+		for _, name := range enabled {
+			if _, exists := r.tools[name]; !exists {
+				unknown = append(unknown, name)
+			}
+			keep[name] = true
+		}
+		for name := range r.tools {
+			if !keep[name] {
+				delete(r.tools, name)
+			}
+		}
+	}
+	for _, name := range disabled {
+		if _, exists := r.tools[name]; !exists && len(enabled) == 0 {
+			unknown = append(unknown, name)
+		}
+		delete(r.tools, name)
+	}
+	return unknown
+}
+
 // GetTool retrieves a tool by name
 func (r *Registry) GetTool(name string) (Tool, bool) {
 	r.mu.RLock()
@@ -133,7 +237,23 @@ func (r *Registry) ExecuteAsync(ctx context.Context, call ToolCall, resultChan c
 			return
 		}
 		
+		// Give the tool somewhere to stream output while it runs
+		r.mu.RLock()
+		handler := r.output
+		r.mu.RUnlock()
+		var sink *lineSink
+		if handler != nil {
+			sink = newLineSink(func(lines []string) {
+				handler(call.ID, call.Name, lines)
+			})
+			ctx = withOutputSink(ctx, sink)
+		}
+
 		result, err := tool.Execute(ctx, call.Args)
+		if sink != nil {
+			sink.Close()
+		}
+		result = r.outputs.limitResult(result)
 		toolResult := ToolResult{
 			CallID: call.ID,
 			Name:   call.Name,
@@ -0,0 +1,86 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/Rorical/RoriCode/internal/repomap"
+)
+
+// RepoMapTool serves the cached repository map
+type RepoMapTool struct{}
+
+func (r *RepoMapTool) Name() string {
+	return "repo_map"
+}
+
+func (r *RepoMapTool) Description() string {
+	return "Get a compact map of the repository: the directory tree with the top-level symbols (types, functions, classes) of each source file. Cached and refreshed automatically when files change. Use it before exploring with read_file."
+}
+
+func (r *RepoMapTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Directory to map, relative to current working directory (default: '.')",
+		},
+		"token_budget": map[string]interface{}{
+			"type":        "number",
+			"description": "Approximate maximum size of the map in tokens; detail is reduced to fit (default: 2000, max: 16000)",
+		},
+	}
+}
+
+func (r *RepoMapTool) RequiredParameters() []string {
+	return []string{}
+}
+
+func (r *RepoMapTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	path := "."
+	if val, exists := args["path"]; exists {
+		if p, ok := val.(string); ok && p != "" {
+			path = p
+		}
+	}
+
+	tokenBudget := repomap.DefaultTokenBudget
+	if val, exists := args["token_budget"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			if num > 16000 {
+				tokenBudget = 16000
+			} else {
+				tokenBudget = int(num)
+			}
+		}
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(path) {
+		return nil, fmt.Errorf("path must be relative, not absolute: %s", path)
+	}
+	if strings.Contains(path, "..") {
+		return nil, fmt.Errorf("path cannot contain parent directory references (..): %s", path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+
+	m, text, err := repomap.ForRoot(filepath.Join(cwd, path)).Render(ctx, tokenBudget)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"path":      path,
+		"files":     len(m.Files),
+		"tokens":    repomap.EstimateTokens(text),
+		"truncated": m.Truncated,
+		"map":       text,
+		"summary":   fmt.Sprintf("Mapped %d files in %s", len(m.Files), path),
+	}, nil
+}
@@ -0,0 +1,598 @@
+package tools
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RunTestsTool runs a project's test suite and returns structured results
+type RunTestsTool struct {
+	confirmator Confirmator
+	env         *EnvOverlay
+	mu          sync.Mutex
+	lastFailed  map[string][]string // Go: failed top-level tests by package, for rerun_failed; none reruns the whole package
+	lastType    string              // Project type of the previous run
+}
+
+// testCase is the outcome of a single test
+type testCase struct {
+	Package   string   `json:"package,omitempty"`
+	Name      string   `json:"name"`
+	Status    string   `json:"status"`
+	Elapsed   float64  `json:"elapsed_seconds"`
+	Output    string   `json:"output,omitempty"`
+	Locations []string `json:"locations,omitempty"`
+}
+
+// goTestEvent is one line of `go test -json` output (see `go doc test2json`)
+type goTestEvent struct {
+	Action      string
+	Package     string
+	Test        string
+	Elapsed     float64
+	Output      string
+	ImportPath  string
+	FailedBuild string
+}
+
+// goFileLineRe matches file:line locations in test output, e.g. "    foo_test.go:42: ..."
+var goFileLineRe = regexp.MustCompile(`([\w./\\-]+\.go):(\d+)`)
+
+// pytestResultRe matches pytest -rA summary lines, e.g. "FAILED tests/test_x.py::test_y - AssertionError"
+var pytestResultRe = regexp.MustCompile(`^(PASSED|FAILED|ERROR|SKIPPED|XFAIL|XPASS)\s+(\S+)(?:\s+-\s+(.*))?$`)
+
+// pytestLocationRe matches pytest traceback locations, e.g. "tests/test_x.py:12: AssertionError"
+var pytestLocationRe = regexp.MustCompile(`^([\w./\\-]+\.py):(\d+):`)
+
+const (
+	// maxTestOutput bounds the captured output per failed test
+	maxTestOutput = 4000
+	// maxListedTests bounds the per-test list in the result
+	maxListedTests = 200
+)
+
+func (r *RunTestsTool) Name() string {
+	return "run_tests"
+}
+
+func (r *RunTestsTool) Description() string {
+	return "Run the project's tests and get structured results. Detects Go (go.mod), Node (package.json) and Python (pyproject.toml) projects. For Go, reports pass/fail/skip per test with failure output, file:line locations and durations. Supports filtering by package and test name and rerunning only the previous failures."
+}
+
+func (r *RunTestsTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"package": map[string]interface{}{
+			"type":        "string",
+			"description": "Package pattern or test path to run, e.g. './internal/core/...' for Go or 'tests/test_api.py' for Python (default: all)",
+		},
+		"run": map[string]interface{}{
+			"type":        "string",
+			"description": "Only run tests matching this name pattern (Go -run regex, pytest -k expression, or passed to the npm test script)",
+		},
+		"rerun_failed": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Rerun only the tests that failed in the previous run (default: false)",
+		},
+		"project_type": map[string]interface{}{
+			"type":        "string",
+			"description": "Override project detection: 'go', 'node' or 'python'",
+			"enum":        []string{"go", "node", "python"},
+		},
+		"working_dir": map[string]interface{}{
+			"type":        "string",
+			"description": "Project directory relative to current working directory (default: '.')",
+		},
+		"timeout": map[string]interface{}{
+			"type":        "number",
+			"description": "Timeout in seconds (default: 300, max: 1800)",
+		},
+	}
+}
+
+func (r *RunTestsTool) RequiredParameters() []string {
+	return []string{}
+}
+
+func (r *RunTestsTool) SetConfirmator(confirmator Confirmator) {
+	r.confirmator = confirmator
+}
+
+func (r *RunTestsTool) SetEnvOverlay(env *EnvOverlay) {
+	r.env = env
+}
+
+func (r *RunTestsTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	var pkg, run, projectType string
+	if val, exists := args["package"]; exists {
+		if s, ok := val.(string); ok {
+			pkg = strings.TrimSpace(s)
+		}
+	}
+	if val, exists := args["run"]; exists {
+		if s, ok := val.(string); ok {
+			run = strings.TrimSpace(s)
+		}
+	}
+	if val, exists := args["project_type"]; exists {
+		if s, ok := val.(string); ok {
+			projectType = s
+		}
+	}
+
+	rerunFailed := false
+	if val, exists := args["rerun_failed"]; exists {
+		if b, ok := val.(bool); ok {
+			rerunFailed = b
+		}
+	}
+
+	workingDir := "."
+	if val, exists := args["working_dir"]; exists {
+		if s, ok := val.(string); ok && s != "" {
+			workingDir = s
+		}
+	}
+
+	timeout := 300.0
+	if val, exists := args["timeout"]; exists {
+		if t, ok := val.(float64); ok && t > 0 {
+			if t > 1800 {
+				timeout = 1800
+			} else {
+				timeout = t
+			}
+		}
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(workingDir) {
+		return nil, fmt.Errorf("working_dir must be relative, not absolute: %s", workingDir)
+	}
+	if strings.Contains(workingDir, "..") {
+		return nil, fmt.Errorf("working_dir cannot contain parent directory references (..): %s", workingDir)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+	dir := filepath.Join(cwd, workingDir)
+
+	if projectType == "" {
+		projectType = detectProjectType(dir)
+		if projectType == "" {
+			return nil, fmt.Errorf("could not detect project type in %s (no go.mod, package.json or pyproject.toml); set project_type", workingDir)
+		}
+	}
+
+	var cmdArgs []string
+	switch projectType {
+	case "go":
+		cmdArgs, err = r.goTestArgs(pkg, run, rerunFailed)
+	case "python":
+		cmdArgs, err = r.pytestArgs(pkg, run, rerunFailed)
+	case "node":
+		if rerunFailed {
+			return nil, fmt.Errorf("rerun_failed is not supported for node projects")
+		}
+		cmdArgs = []string{"npm", "test", "--silent"}
+		if run != "" {
+			cmdArgs = append(cmdArgs, "--", run)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported project type: %s", projectType)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	commandLine := strings.Join(cmdArgs, " ")
+
+	// Tests run arbitrary project code, so ask first
+	if r.confirmator != nil {
+		if !r.confirmator.RequestConfirmation("Run tests", r.env.confirmationText(commandLine), r.env.HasDangerous()) {
+			return map[string]interface{}{
+				"output":  "User aborted test run",
+				"aborted": true,
+			}, nil
+		}
+	}
+
+	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
+	defer cancel()
+
+	cmd := exec.CommandContext(timeoutCtx, cmdArgs[0], cmdArgs[1:]...)
+	cmd.Dir = dir
+	cmd.Env = append(r.env.Environ(), "CI=true")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	start := time.Now()
+	runErr := cmd.Run()
+	duration := time.Since(start)
+
+	if runErr != nil {
+		if _, isExit := runErr.(*exec.ExitError); !isExit && timeoutCtx.Err() == nil {
+			return nil, fmt.Errorf("failed to run %s: %v", cmdArgs[0], runErr)
+		}
+	}
+
+	exitCode := 0
+	if exitError, ok := runErr.(*exec.ExitError); ok {
+		exitCode = exitError.ExitCode()
+	}
+
+	result := map[string]interface{}{
+		"project_type":     projectType,
+		"command":          commandLine,
+		"working_dir":      workingDir,
+		"exit_code":        exitCode,
+		"success":          runErr == nil,
+		"duration_seconds": duration.Round(time.Millisecond).Seconds(),
+	}
+	if timeoutCtx.Err() == context.DeadlineExceeded {
+		result["timed_out"] = true
+	}
+
+	switch projectType {
+	case "go":
+		r.summarizeGo(result, stdout.Bytes(), stderr.String())
+	case "python":
+		r.summarizePytest(result, stdout.String()+stderr.String())
+	default:
+		result["output"] = tailText(stdout.String()+stderr.String(), maxTestOutput)
+	}
+
+	r.mu.Lock()
+	r.lastType = projectType
+	r.mu.Unlock()
+
+	return result, nil
+}
+
+// detectProjectType identifies the test runner from project files
+func detectProjectType(dir string) string {
+	markers := []struct {
+		file, projectType string
+	}{
+		{"go.mod", "go"},
+		{"package.json", "node"},
+		{"pyproject.toml", "python"},
+		{"setup.py", "python"},
+		{"pytest.ini", "python"},
+	}
+	for _, m := range markers {
+		if _, err := os.Stat(filepath.Join(dir, m.file)); err == nil {
+			return m.projectType
+		}
+	}
+	return ""
+}
+
+// goTestArgs builds the go test command line
+func (r *RunTestsTool) goTestArgs(pkg, run string, rerunFailed bool) ([]string, error) {
+	args := []string{"go", "test", "-json"}
+
+	if rerunFailed {
+		r.mu.Lock()
+		failed := r.lastFailed
+		lastType := r.lastType
+		r.mu.Unlock()
+
+		if lastType != "go" || len(failed) == 0 {
+			return nil, fmt.Errorf("no failed Go tests recorded from a previous run")
+		}
+
+		var names, pkgs []string
+		seen := make(map[string]bool)
+		wholePackage := false
+		for p, tests := range failed {
+			pkgs = append(pkgs, p)
+			if len(tests) == 0 {
+				wholePackage = true
+			}
+			for _, t := range tests {
+				if !seen[t] {
+					seen[t] = true
+					names = append(names, regexp.QuoteMeta(t))
+				}
+			}
+		}
+		sort.Strings(pkgs)
+		sort.Strings(names)
+
+		args = append(args, "-count=1")
+		// -run applies to every package, so a package that failed to build
+		// or failed outside a test reruns the listed packages in full
+		if len(names) > 0 && !wholePackage {
+			args = append(args, "-run", "^("+strings.Join(names, "|")+")$")
+		}
+		return append(args, pkgs...), nil
+	}
+
+	if run != "" {
+		if _, err := regexp.Compile(run); err != nil {
+			return nil, fmt.Errorf("invalid run pattern: %v", err)
+		}
+		args = append(args, "-run", run)
+	}
+	if pkg == "" {
+		pkg = "./..."
+	}
+	return append(args, strings.Fields(pkg)...), nil
+}
+
+// pytestArgs builds the pytest command line
+func (r *RunTestsTool) pytestArgs(pkg, run string, rerunFailed bool) ([]string, error) {
+	args := []string{"python", "-m", "pytest", "-q", "-rA", "--tb=short", "--durations=0"}
+	if rerunFailed {
+		// pytest remembers failures in its own cache
+		args = append(args, "--lf")
+	}
+	if run != "" {
+		args = append(args, "-k", run)
+	}
+	if pkg != "" {
+		args = append(args, strings.Fields(pkg)...)
+	}
+	return args, nil
+}
+
+// summarizeGo parses `go test -json` output into per-test results
+func (r *RunTestsTool) summarizeGo(result map[string]interface{}, stdout []byte, stderr string) {
+	type key struct{ pkg, test string }
+	cases := make(map[key]*testCase)
+	var order []key
+	outputs := make(map[key]*strings.Builder)
+	var buildErrors []string
+	var failedPackages []string
+	var nonJSON strings.Builder
+
+	scanner := bufio.NewScanner(bytes.NewReader(stdout))
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		var event goTestEvent
+		if len(line) == 0 || line[0] != '{' || json.Unmarshal(line, &event) != nil {
+			nonJSON.Write(line)
+			nonJSON.WriteByte('\n')
+			continue
+		}
+
+		switch event.Action {
+		case "build-output":
+			buildErrors = append(buildErrors, strings.TrimRight(event.Output, "\n"))
+			continue
+		case "build-fail":
+			// ImportPath is "pkg" or "pkg [pkg.test]"
+			if fields := strings.Fields(event.ImportPath); len(fields) > 0 && !containsString(failedPackages, fields[0]) {
+				failedPackages = append(failedPackages, fields[0])
+			}
+			continue
+		}
+
+		k := key{event.Package, event.Test}
+		if event.Test == "" {
+			// Package-level events
+			if event.Action == "fail" && !containsString(failedPackages, event.Package) {
+				failedPackages = append(failedPackages, event.Package)
+			}
+			if event.Action == "output" && event.FailedBuild == "" {
+				if b, ok := outputs[k]; ok || strings.Contains(event.Output, "FAIL") || strings.Contains(event.Output, "panic") {
+					if !ok {
+						b = &strings.Builder{}
+						outputs[k] = b
+					}
+					b.WriteString(event.Output)
+				}
+			}
+			continue
+		}
+
+		tc, exists := cases[k]
+		if !exists {
+			tc = &testCase{Package: event.Package, Name: event.Test, Status: "running"}
+			cases[k] = tc
+			order = append(order, k)
+		}
+
+		switch event.Action {
+		case "output":
+			b, ok := outputs[k]
+			if !ok {
+				b = &strings.Builder{}
+				outputs[k] = b
+			}
+			if b.Len() < maxTestOutput*2 {
+				b.WriteString(event.Output)
+			}
+		case "pass", "fail", "skip":
+			tc.Status = event.Action
+			tc.Elapsed = event.Elapsed
+		}
+	}
+
+	counts := map[string]int{"pass": 0, "fail": 0, "skip": 0}
+	var failures, tests []testCase
+	failedByPkg := make(map[string][]string)
+
+	for _, k := range order {
+		tc := cases[k]
+		if tc.Status == "running" {
+			// Interrupted by a panic or timeout
+			tc.Status = "fail"
+		}
+		counts[tc.Status]++
+
+		if tc.Status == "fail" {
+			failure := *tc
+			if b, ok := outputs[k]; ok {
+				failure.Output = cleanGoTestOutput(b.String())
+				failure.Locations = goTestLocations(failure.Output)
+			}
+			failures = append(failures, failure)
+
+			top := strings.SplitN(tc.Name, "/", 2)[0]
+			if !containsString(failedByPkg[tc.Package], top) {
+				failedByPkg[tc.Package] = append(failedByPkg[tc.Package], top)
+			}
+		}
+		if len(tests) < maxListedTests {
+			tests = append(tests, testCase{Package: tc.Package, Name: tc.Name, Status: tc.Status, Elapsed: tc.Elapsed})
+		}
+	}
+
+	// Packages that failed without a failing test (build errors, panics in init, TestMain)
+	var packageErrors []map[string]interface{}
+	for _, pkg := range failedPackages {
+		if len(failedByPkg[pkg]) > 0 {
+			continue
+		}
+		entry := map[string]interface{}{"package": pkg}
+		if b, ok := outputs[key{pkg, ""}]; ok {
+			entry["output"] = tailText(b.String(), maxTestOutput)
+		}
+		packageErrors = append(packageErrors, entry)
+		failedByPkg[pkg] = nil
+	}
+
+	// Remember failures for rerun_failed. A rerun runs every recorded
+	// package, so its failures replace the record as a whole.
+	r.mu.Lock()
+	r.lastFailed = failedByPkg
+	r.mu.Unlock()
+
+	result["summary"] = fmt.Sprintf("%d passed, %d failed, %d skipped", counts["pass"], counts["fail"], counts["skip"])
+	result["passed"] = counts["pass"]
+	result["failed"] = counts["fail"]
+	result["skipped"] = counts["skip"]
+	result["failures"] = failures
+	result["tests"] = tests
+	if len(order) > len(tests) {
+		result["tests_truncated"] = true
+	}
+	if len(packageErrors) > 0 {
+		result["package_errors"] = packageErrors
+	}
+	if len(buildErrors) > 0 {
+		result["build_errors"] = strings.Join(buildErrors, "\n")
+	}
+	if extra := strings.TrimSpace(nonJSON.String() + stderr); extra != "" {
+		result["stderr"] = tailText(extra, maxTestOutput)
+	}
+}
+
+// summarizePytest parses pytest -rA summary lines into per-test results
+func (r *RunTestsTool) summarizePytest(result map[string]interface{}, output string) {
+	counts := map[string]int{"pass": 0, "fail": 0, "skip": 0}
+	var failures, tests []testCase
+
+	for _, line := range strings.Split(output, "\n") {
+		m := pytestResultRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil {
+			continue
+		}
+
+		status := "pass"
+		switch m[1] {
+		case "FAILED", "ERROR":
+			status = "fail"
+		case "SKIPPED", "XFAIL":
+			status = "skip"
+		}
+		counts[status]++
+
+		tc := testCase{Name: m[2], Status: status}
+		if status == "fail" {
+			failure := tc
+			failure.Output = m[3]
+			failure.Locations = pytestLocations(output, m[2])
+			failures = append(failures, failure)
+		}
+		if len(tests) < maxListedTests {
+			tests = append(tests, tc)
+		}
+	}
+
+	result["summary"] = fmt.Sprintf("%d passed, %d failed, %d skipped", counts["pass"], counts["fail"], counts["skip"])
+	result["passed"] = counts["pass"]
+	result["failed"] = counts["fail"]
+	result["skipped"] = counts["skip"]
+	result["failures"] = failures
+	result["tests"] = tests
+	if counts["fail"] > 0 || len(tests) == 0 {
+		result["output"] = tailText(output, maxTestOutput)
+	}
+}
+
+// cleanGoTestOutput drops go test framing lines and bounds the size
+func cleanGoTestOutput(output string) string {
+	var kept []string
+	for _, line := range strings.Split(output, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "=== RUN") || strings.HasPrefix(trimmed, "=== PAUSE") || strings.HasPrefix(trimmed, "=== CONT") {
+			continue
+		}
+		kept = append(kept, line)
+	}
+	return tailText(strings.TrimSpace(strings.Join(kept, "\n")), maxTestOutput)
+}
+
+// goTestLocations extracts unique file:line references from test output
+func goTestLocations(output string) []string {
+	var locations []string
+	for _, m := range goFileLineRe.FindAllStringSubmatch(output, -1) {
+		location := m[1] + ":" + m[2]
+		if !containsString(locations, location) {
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
+
+// pytestLocations finds traceback locations in the section for a failed test
+func pytestLocations(output, nodeID string) []string {
+	file := strings.SplitN(nodeID, "::", 2)[0]
+	var locations []string
+	for _, line := range strings.Split(output, "\n") {
+		m := pytestLocationRe.FindStringSubmatch(strings.TrimSpace(line))
+		if m == nil || m[1] != file {
+			continue
+		}
+		location := m[1] + ":" + m[2]
+		if !containsString(locations, location) {
+			locations = append(locations, location)
+		}
+	}
+	return locations
+}
+
+// tailText keeps the last max bytes of text, where failures usually are
+func tailText(text string, max int) string {
+	if len(text) <= max {
+		return text
+	}
+	return "... (truncated)\n" + text[len(text)-max:]
+}
+
+// containsString reports whether list contains value
+func containsString(list []string, value string) bool {
+	for _, item := range list {
+		if item == value {
+			return true
+		}
+	}
+	return false
+}
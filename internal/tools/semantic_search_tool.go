@@ -0,0 +1,153 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/Rorical/RoriCode/internal/semindex"
+)
+
+// SemanticSearchTool answers conceptual code questions from a local embedding index
+type SemanticSearchTool struct {
+	embedder semindex.Embedder
+	mu       sync.Mutex
+	indexes  map[string]*semindex.Index // Open indexes by workspace root
+}
+
+func (s *SemanticSearchTool) Name() string {
+	return "semantic_search"
+}
+
+func (s *SemanticSearchTool) Description() string {
+	return "Search the codebase by meaning rather than keywords (e.g. 'where do we retry uploads'). Uses an embedding index stored under .roricode/index/ that is updated incrementally for changed files before each search. Returns ranked code snippets with file paths and line ranges."
+}
+
+func (s *SemanticSearchTool) Parameters() map[string]interface{} {
+	return map[string]interface{}{
+		"query": map[string]interface{}{
+			"type":        "string",
+			"description": "Natural language description of the code you are looking for",
+		},
+		"top_k": map[string]interface{}{
+			"type":        "number",
+			"description": "Number of snippets to return (default: 8, max: 30)",
+		},
+		"path": map[string]interface{}{
+			"type":        "string",
+			"description": "Only return results under this directory or file, relative to current working directory (optional)",
+		},
+		"refresh": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Update the index for changed files before searching (default: true)",
+		},
+	}
+}
+
+func (s *SemanticSearchTool) RequiredParameters() []string {
+	return []string{"query"}
+}
+
+func (s *SemanticSearchTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+	query, ok := args["query"].(string)
+	if !ok || strings.TrimSpace(query) == "" {
+		return nil, fmt.Errorf("query parameter must be a non-empty string")
+	}
+
+	if s.embedder == nil {
+		return nil, fmt.Errorf("semantic search is not available: configure a profile with an API key, or set embedding_model to \"%s\" for the offline embedder", semindex.LocalEmbeddingModel)
+	}
+
+	topK := 8
+	if val, exists := args["top_k"]; exists {
+		if num, ok := val.(float64); ok && num > 0 {
+			if num > 30 {
+				topK = 30
+			} else {
+				topK = int(num)
+			}
+		}
+	}
+
+	var path string
+	if val, exists := args["path"]; exists {
+		if p, ok := val.(string); ok {
+			path = p
+		}
+	}
+
+	refresh := true
+	if val, exists := args["refresh"]; exists {
+		if b, ok := val.(bool); ok {
+			refresh = b
+		}
+	}
+
+	// Validate path safety
+	if filepath.IsAbs(path) {
+		return nil, fmt.Errorf("path must be relative, not absolute: %s", path)
+	}
+	if strings.Contains(path, "..") {
+		return nil, fmt.Errorf("path cannot contain parent directory references (..): %s", path)
+	}
+
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get current directory: %v", err)
+	}
+
+	index := s.indexFor(cwd)
+
+	result := map[string]interface{}{
+		"query": query,
+	}
+
+	if refresh {
+		stats, err := index.Update(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update index: %v", err)
+		}
+		result["index"] = stats
+	}
+
+	hits, err := index.Search(ctx, query, topK, path)
+	if err != nil {
+		return nil, fmt.Errorf("search failed: %v", err)
+	}
+
+	// Long chunks are cut down; read_file can fetch the full range
+	for i := range hits {
+		if snippet := hits[i].Snippet; len(snippet) > 1500 {
+			cut := 1500
+			for cut > 0 && !utf8.RuneStart(snippet[cut]) {
+				cut--
+			}
+			hits[i].Snippet = snippet[:cut] + "\n..."
+		}
+	}
+
+	result["count"] = len(hits)
+	result["results"] = hits
+	result["summary"] = fmt.Sprintf("Found %d relevant snippets", len(hits))
+	return result, nil
+}
+
+// indexFor returns the index for a workspace root, opening it on first use
+func (s *SemanticSearchTool) indexFor(root string) *semindex.Index {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.indexes == nil {
+		s.indexes = make(map[string]*semindex.Index)
+	}
+	index, exists := s.indexes[root]
+	if !exists {
+		index = semindex.Open(root, s.embedder)
+		s.indexes[root] = index
+	}
+	return index
+}
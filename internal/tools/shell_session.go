@@ -0,0 +1,254 @@
+package tools
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os/exec"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxSessionOutput caps the unread output buffered from the session shell
+const maxSessionOutput = 1 << 20
+
+// shellSession is a long-lived shell that runs commands one at a time, so
+// the working directory, variables and functions persist between calls.
+// Each command is followed by a sentinel line carrying its exit code and
+// the shell's working directory.
+type shellSession struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	output *outputBuffer
+	done   chan struct{} // Closed when the shell has exited
+	shell  string
+
+	// Overlay variables already exported into the shell
+	appliedVars  map[string]string
+	appliedUnset map[string]bool
+}
+
+// sessionResult is the outcome of one command in a session
+type sessionResult struct {
+	exitCode int
+	cwd      string
+	lost     bool // Output was dropped from the buffer before it was read
+	exited   bool // The shell itself exited (e.g. the command ran "exit")
+}
+
+// startShellSession starts bash (or sh when bash is unavailable) in dir with
+// the session environment
+func startShellSession(dir string, env *EnvOverlay) (*shellSession, error) {
+	shell, args := "sh", []string{}
+	if path, err := exec.LookPath("bash"); err == nil {
+		shell, args = path, []string{"--noprofile", "--norc"}
+	}
+
+	cmd := exec.Command(shell, args...)
+	cmd.Dir = dir
+	cmd.Env = env.Environ()
+	output := newOutputBuffer(maxSessionOutput)
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = 2 * time.Second
+	setProcessGroup(cmd)
+
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, fmt.Errorf("failed to create shell input: %v", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("failed to start shell: %v", err)
+	}
+
+	// The shell inherits the overlay as it is now; later changes are
+	// exported before each command
+	vars, unset := env.Session()
+	s := &shellSession{
+		cmd:          cmd,
+		stdin:        stdin,
+		output:       output,
+		done:         make(chan struct{}),
+		shell:        shell,
+		appliedVars:  vars,
+		appliedUnset: make(map[string]bool),
+	}
+	for _, name := range unset {
+		s.appliedUnset[name] = true
+	}
+
+	go func() {
+		cmd.Wait()
+		close(s.done)
+	}()
+	return s, nil
+}
+
+// alive reports whether the shell is still running
+func (s *shellSession) alive() bool {
+	select {
+	case <-s.done:
+		return false
+	default:
+		return true
+	}
+}
+
+// close kills the shell and everything it started
+func (s *shellSession) close() {
+	if !s.alive() {
+		return
+	}
+	s.stdin.Close()
+	killProcessTree(s.cmd.Process)
+	<-s.done
+}
+
+// envScript returns the export and unset commands that bring the shell in line
+// with the overlay changes made since the last command
+func (s *shellSession) envScript(env *EnvOverlay) string {
+	if env == nil {
+		return ""
+	}
+	vars, unset := env.Session()
+
+	names := make([]string, 0, len(vars))
+	for name := range vars {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	for _, name := range names {
+		if applied, ok := s.appliedVars[name]; ok && applied == vars[name] {
+			continue
+		}
+		fmt.Fprintf(&b, "export %s=%s\n", name, shellQuote(vars[name]))
+		s.appliedVars[name] = vars[name]
+		delete(s.appliedUnset, name)
+	}
+	for _, name := range unset {
+		if s.appliedUnset[name] {
+			continue
+		}
+		fmt.Fprintf(&b, "unset %s\n", name)
+		s.appliedUnset[name] = true
+		delete(s.appliedVars, name)
+	}
+	return b.String()
+}
+
+// run executes command in the session, copying its output to capture and the
+// context's output sink, and waits for its sentinel line. The
+// command runs through eval so that syntax errors fail the command instead
+// of leaving the shell waiting for more input; its stdin is /dev/null so it
+// cannot consume the session's own input.
+func (s *shellSession) run(ctx context.Context, command, workingDir string, env *EnvOverlay, capture io.Writer) (*sessionResult, error) {
+	marker := newSessionMarker()
+
+	var script strings.Builder
+	script.WriteString(s.envScript(env))
+	if workingDir != "" {
+		// A failed cd skips the command and reports cd's exit code
+		fmt.Fprintf(&script, "cd -- %s && ", shellQuote(workingDir))
+	}
+	fmt.Fprintf(&script, "eval %s < /dev/null\n", shellQuote(command))
+	fmt.Fprintf(&script, "printf '%%s %%s %%s\\n' %s \"$?\" \"$PWD\"\n", marker)
+
+	start := s.output.end()
+	if _, err := io.WriteString(s.stdin, script.String()); err != nil {
+		return nil, fmt.Errorf("failed to write to shell: %v", err)
+	}
+
+	// Everything before the sentinel is the command's output: it is captured
+	// and streamed as it arrives. Only the new output is scanned, carrying
+	// over enough of the previous chunk to match a sentinel split across
+	// writes.
+	w := io.MultiWriter(capture, outputSink(ctx))
+	needle := marker + " "
+	scanned, streamed := start, start
+	lost := false
+	var carry string
+	for {
+		changed := s.output.waitChan()
+		chunk, next, dropped := s.output.read(scanned, 0)
+		if dropped > 0 {
+			lost = true
+		}
+		window := carry + chunk
+		windowStart := next - int64(len(window))
+		scanned = next
+
+		if i := strings.Index(window, needle); i >= 0 {
+			streamed = s.copyOutput(w, streamed, windowStart+int64(i))
+			status := window[i+len(needle):]
+			if nl := strings.IndexByte(status, '\n'); nl >= 0 {
+				code, cwd, _ := strings.Cut(status[:nl], " ")
+				exitCode, err := strconv.Atoi(code)
+				if err != nil {
+					return nil, fmt.Errorf("malformed session status: %q", status[:nl])
+				}
+				return &sessionResult{exitCode: exitCode, cwd: cwd, lost: lost}, nil
+			}
+			carry = window[i:]
+		} else {
+			// Hold back only what could be the start of the sentinel
+			hold := 0
+			for k := len(needle) - 1; k > 0; k-- {
+				if strings.HasSuffix(window, needle[:k]) {
+					hold = k
+					break
+				}
+			}
+			streamed = s.copyOutput(w, streamed, next-int64(hold))
+			if len(window) > len(needle) {
+				carry = window[len(window)-len(needle):]
+			} else {
+				carry = window
+			}
+		}
+
+		select {
+		case <-changed:
+		case <-s.done:
+			if s.output.end() > scanned {
+				continue // Output written just before the exit
+			}
+			s.copyOutput(w, streamed, scanned)
+			return &sessionResult{
+				exitCode: s.cmd.ProcessState.ExitCode(),
+				lost:     lost,
+				exited:   true,
+			}, nil
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
+
+// copyOutput writes the session output from offset from up to offset to into
+// w and returns the offset reached
+func (s *shellSession) copyOutput(w io.Writer, from, to int64) int64 {
+	if to <= from {
+		return from
+	}
+	text, next, _ := s.output.read(from, int(to-from))
+	io.WriteString(w, text)
+	return next
+}
+
+// newSessionMarker returns a random sentinel that cannot appear in normal output
+func newSessionMarker() string {
+	buf := make([]byte, 8)
+	rand.Read(buf)
+	return "__RORICODE_" + hex.EncodeToString(buf) + "__"
+}
+
+// shellQuote quotes s as a single POSIX shell word
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -3,14 +3,23 @@ package tools
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
 	"os/exec"
+	"runtime"
 	"strings"
+	"sync"
 	"time"
 )
 
 // ShellTool executes shell commands (use with caution)
 type ShellTool struct {
 	confirmator Confirmator
+	env         *EnvOverlay
+	outputs     *OutputStore
+
+	mu      sync.Mutex    // Serialises session commands
+	session *shellSession // Persistent shell, started on first use
 }
 
 func (s *ShellTool) Name() string {
@@ -18,7 +27,7 @@ func (s *ShellTool) Name() string {
 }
 
 func (s *ShellTool) Description() string {
-	return "Execute shell commands with safety features and timeout control"
+	return "Execute shell commands with safety features and timeout control. Set session=true to run in a persistent shell that keeps the working directory, exported variables and activated virtualenvs between calls; reset_session=true restarts it."
 }
 
 func (s *ShellTool) Parameters() map[string]interface{} {
@@ -33,7 +42,15 @@ func (s *ShellTool) Parameters() map[string]interface{} {
 		},
 		"working_dir": map[string]interface{}{
 			"type":        "string",
-			"description": "Working directory for the command (optional)",
+			"description": "Working directory for the command (optional; in a session the directory change persists)",
+		},
+		"session": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Run in the persistent shell session instead of a fresh process (default: false, not supported on Windows)",
+		},
+		"reset_session": map[string]interface{}{
+			"type":        "boolean",
+			"description": "Restart the persistent session before running the command, discarding its directory and variables; the command may be empty to only reset (default: false)",
 		},
 	}
 }
@@ -46,12 +63,42 @@ func (s *ShellTool) SetConfirmator(confirmator Confirmator) {
 	s.confirmator = confirmator
 }
 
+func (s *ShellTool) SetEnvOverlay(env *EnvOverlay) {
+	s.env = env
+}
+
+func (s *ShellTool) SetOutputStore(store *OutputStore) {
+	s.outputs = store
+}
+
 func (s *ShellTool) Execute(ctx context.Context, args map[string]interface{}) (interface{}, error) {
 	command, ok := args["command"].(string)
 	if !ok {
 		return nil, fmt.Errorf("command parameter must be a string")
 	}
 
+	useSession, resetSession := false, false
+	if val, exists := args["session"]; exists {
+		if b, ok := val.(bool); ok {
+			useSession = b
+		}
+	}
+	if val, exists := args["reset_session"]; exists {
+		if b, ok := val.(bool); ok {
+			resetSession = b
+		}
+	}
+	if (useSession || resetSession) && runtime.GOOS == "windows" {
+		return nil, fmt.Errorf("shell sessions are not supported on Windows")
+	}
+	if resetSession && strings.TrimSpace(command) == "" {
+		s.resetSession()
+		return map[string]interface{}{
+			"success": true,
+			"summary": "Shell session reset",
+		}, nil
+	}
+
 	// Handle timeout
 	timeout := 30.0
 	if val, exists := args["timeout"]; exists {
@@ -74,8 +121,8 @@ func (s *ShellTool) Execute(ctx context.Context, args map[string]interface{}) (i
 
 	// Check if confirmation is needed
 	if s.confirmator != nil {
-		dangerous := s.isDangerousCommand(command)
-		if !s.confirmator.RequestConfirmation("Execute command", command, dangerous) {
+		dangerous := isDangerousCommand(command) || s.env.HasDangerous()
+		if !s.confirmator.RequestConfirmation("Execute command", s.env.confirmationText(command), dangerous) {
 			return map[string]interface{}{
 				"output":  "User aborted command execution",
 				"aborted": true,
@@ -87,20 +134,38 @@ func (s *ShellTool) Execute(ctx context.Context, args map[string]interface{}) (i
 	timeoutCtx, cancel := context.WithTimeout(ctx, time.Duration(timeout)*time.Second)
 	defer cancel()
 
-	// Execute command
-	cmd := exec.CommandContext(timeoutCtx, "cmd", "/c", command)
+	if useSession || resetSession {
+		if resetSession {
+			s.resetSession()
+		}
+		return s.executeInSession(timeoutCtx, command, workingDir, timeout)
+	}
+
+	// Execute command, streaming its output while capturing it within the
+	// output limits
+	cmd := shellCommand(timeoutCtx, command)
+	cmd.Env = s.env.Environ()
 	if workingDir != "" {
 		cmd.Dir = workingDir
 	}
+	capture := s.outputs.Writer()
+	output := io.MultiWriter(capture, outputSink(ctx))
+	cmd.Stdout = output
+	cmd.Stderr = output
+	// On timeout, kill the whole tree, and don't let grandchildren holding
+	// the pipe open block Wait forever
+	setProcessGroup(cmd)
+	cmd.Cancel = func() error { return killProcessTree(cmd.Process) }
+	cmd.WaitDelay = 2 * time.Second
 
-	output, err := cmd.CombinedOutput()
+	err := cmd.Run()
 
 	result := map[string]interface{}{
 		"command":     command,
-		"output":      string(output),
 		"working_dir": workingDir,
 		"timeout":     timeout,
 	}
+	addCommandOutput(result, capture.Finish())
 
 	if err != nil {
 		result["error"] = err.Error()
@@ -123,8 +188,114 @@ func (s *ShellTool) Execute(ctx context.Context, args map[string]interface{}) (i
 	return result, nil
 }
 
+// executeInSession runs a command in the persistent shell, starting it when
+// needed. A timed-out command cannot be interrupted without losing the shell,
+// so the session is reset.
+func (s *ShellTool) executeInSession(ctx context.Context, command, workingDir string, timeout float64) (interface{}, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	started := false
+	if s.session == nil || !s.session.alive() {
+		cwd, err := os.Getwd()
+		if err != nil {
+			return nil, fmt.Errorf("failed to get current working directory: %v", err)
+		}
+		session, err := startShellSession(cwd, s.env)
+		if err != nil {
+			return nil, err
+		}
+		s.session = session
+		started = true
+	}
+
+	result := map[string]interface{}{
+		"command":     command,
+		"working_dir": workingDir,
+		"timeout":     timeout,
+		"session":     true,
+	}
+	if started {
+		result["session_started"] = true
+	}
+
+	capture := s.outputs.Writer()
+	res, err := s.session.run(ctx, command, workingDir, s.env, capture)
+	addCommandOutput(result, capture.Finish())
+	if err != nil {
+		s.session.close()
+		s.session = nil
+		if ctx.Err() == context.DeadlineExceeded {
+			result["success"] = false
+			result["timed_out"] = true
+			result["session_reset"] = true
+			result["error"] = "command timed out; the shell session was reset, so its working directory and variables were lost"
+			return result, nil
+		}
+		return nil, err
+	}
+
+	result["exit_code"] = res.exitCode
+	result["success"] = res.exitCode == 0
+	if res.cwd != "" {
+		result["cwd"] = res.cwd
+	}
+	if res.lost {
+		result["output_truncated"] = true
+	}
+	if res.exited {
+		s.session = nil
+		result["session_ended"] = true
+		if res.exitCode != 0 {
+			result["error"] = fmt.Sprintf("shell session exited with code %d", res.exitCode)
+		}
+	} else if res.exitCode != 0 {
+		result["error"] = fmt.Sprintf("exit status %d", res.exitCode)
+	}
+	return result, nil
+}
+
+// addCommandOutput stores captured command output in a result, with the
+// spillover handle and full size when it was truncated
+func addCommandOutput(result map[string]interface{}, out SpillOutput) {
+	result["output"] = out.Text
+	if out.Truncated {
+		result["output_truncated"] = true
+		result["output_bytes"] = out.Bytes
+		result["output_lines"] = out.Lines
+		if out.Handle != "" {
+			result["output_handle"] = out.Handle
+		}
+	}
+}
+
+// resetSession discards the persistent shell; the next session command
+// starts a fresh one
+func (s *ShellTool) resetSession() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.session != nil {
+		s.session.close()
+		s.session = nil
+	}
+}
+
+// Close stops the persistent shell session
+func (s *ShellTool) Close() error {
+	s.resetSession()
+	return nil
+}
+
+// shellCommand runs a command line through the platform shell
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.CommandContext(ctx, "cmd", "/c", command)
+	}
+	return exec.CommandContext(ctx, "sh", "-c", command)
+}
+
 // isDangerousCommand returns true if the command might be dangerous
-func (s *ShellTool) isDangerousCommand(command string) bool {
+func isDangerousCommand(command string) bool {
 	commandLower := strings.ToLower(command)
 	
 	// Commands that are generally safe and don't need confirmation
@@ -0,0 +1,330 @@
+package tools
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// OutputLimits controls how much tool output is returned to the model
+type OutputLimits struct {
+	MaxBytes  int // Output up to this size is returned whole
+	HeadBytes int // Kept from the start of larger output
+	TailBytes int // Kept from the end of larger output
+}
+
+// DefaultOutputLimits returns the limits used when none are configured
+func DefaultOutputLimits() OutputLimits {
+	return OutputLimits{MaxBytes: 32 * 1024, HeadBytes: 8 * 1024, TailBytes: 24 * 1024}
+}
+
+// withDefaults fills unset limits and keeps head and tail within MaxBytes
+func (l OutputLimits) withDefaults() OutputLimits {
+	d := DefaultOutputLimits()
+	if l.MaxBytes <= 0 {
+		l.MaxBytes = d.MaxBytes
+	}
+	if l.HeadBytes <= 0 && l.TailBytes <= 0 {
+		l.HeadBytes = l.MaxBytes / 4
+		l.TailBytes = l.MaxBytes - l.HeadBytes
+	}
+	if l.HeadBytes < 0 {
+		l.HeadBytes = 0
+	}
+	if l.TailBytes < 0 {
+		l.TailBytes = 0
+	}
+	if l.HeadBytes+l.TailBytes > l.MaxBytes {
+		l.TailBytes = l.MaxBytes - l.HeadBytes
+		if l.TailBytes < 0 {
+			l.HeadBytes, l.TailBytes = l.MaxBytes, 0
+		}
+	}
+	return l
+}
+
+// spillDir is where oversized output is kept, relative to the working directory
+var spillDir = filepath.Join(".roricode", "tmp")
+
+// maxSpillBytes bounds a single spillover file
+const maxSpillBytes = 100 << 20
+
+// spillHandleRe matches the handles given out by OutputStore
+var spillHandleRe = regexp.MustCompile(`^out-[0-9a-f]{12}$`)
+
+// OutputStore caps tool output and keeps the full text of oversized output in
+// spillover files under .roricode/tmp, addressed by handle. The files live
+// for the session and are removed on Close.
+type OutputStore struct {
+	mu     sync.Mutex
+	limits OutputLimits
+	files  map[string]string // Handle to absolute path
+}
+
+// OutputStoringTool is a tool that caps its own output using the store
+type OutputStoringTool interface {
+	Tool
+	SetOutputStore(store *OutputStore)
+}
+
+// NewOutputStore creates a store with the default limits
+func NewOutputStore() *OutputStore {
+	return &OutputStore{
+		limits: DefaultOutputLimits(),
+		files:  make(map[string]string),
+	}
+}
+
+// SetLimits changes the limits; zero values select the defaults
+func (s *OutputStore) SetLimits(limits OutputLimits) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.limits = limits.withDefaults()
+}
+
+// Limits returns the current limits
+func (s *OutputStore) Limits() OutputLimits {
+	if s == nil {
+		return DefaultOutputLimits()
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.limits
+}
+
+// create opens a new spillover file and registers its handle
+func (s *OutputStore) create() (*os.File, string, error) {
+	cwd, err := os.Getwd()
+	if err != nil {
+		return nil, "", err
+	}
+	dir := filepath.Join(cwd, spillDir)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, "", err
+	}
+	// Keep spillover files out of version control
+	ignore := filepath.Join(dir, ".gitignore")
+	if _, err := os.Stat(ignore); os.IsNotExist(err) {
+		os.WriteFile(ignore, []byte("*\n"), 0644)
+	}
+
+	buf := make([]byte, 6)
+	rand.Read(buf)
+	handle := "out-" + hex.EncodeToString(buf)
+	path := filepath.Join(dir, handle+".txt")
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return nil, "", err
+	}
+
+	s.mu.Lock()
+	s.files[handle] = path
+	s.mu.Unlock()
+	return file, handle, nil
+}
+
+// Path returns the spillover file of a handle
+func (s *OutputStore) Path(handle string) (string, error) {
+	if !spillHandleRe.MatchString(handle) {
+		return "", fmt.Errorf("invalid output handle: %s", handle)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	path, ok := s.files[handle]
+	if !ok {
+		return "", fmt.Errorf("unknown output handle: %s (output is kept only for the current session)", handle)
+	}
+	return path, nil
+}
+
+// Close removes the spillover files of the session
+func (s *OutputStore) Close() error {
+	s.mu.Lock()
+	files := s.files
+	s.files = make(map[string]string)
+	s.mu.Unlock()
+
+	for _, path := range files {
+		os.Remove(path)
+	}
+	return nil
+}
+
+// Writer returns a writer that captures output within the limits, spilling
+// to a file once it grows past MaxBytes. It is safe to call on a nil store.
+func (s *OutputStore) Writer() *SpillWriter {
+	return &SpillWriter{store: s, limits: s.Limits()}
+}
+
+// Limit caps text, spilling it when oversized. It returns the text to show
+// and, when spilled, the handle of the full text.
+func (s *OutputStore) Limit(text string) (string, string) {
+	w := s.Writer()
+	w.Write([]byte(text))
+	out := w.Finish()
+	return out.Text, out.Handle
+}
+
+// limitResult caps oversized top-level string fields of a tool result, for
+// tools that do not limit their own output. A spilled field gets companion
+// "<field>_truncated" and "<field>_handle" entries.
+func (s *OutputStore) limitResult(result interface{}) interface{} {
+	fields, ok := result.(map[string]interface{})
+	if !ok {
+		return result
+	}
+	maxBytes := s.Limits().MaxBytes
+
+	var oversized []string
+	for key, value := range fields {
+		if _, limited := fields[key+"_truncated"]; limited {
+			continue // Already capped by the tool
+		}
+		if text, ok := value.(string); ok && len(text) > maxBytes {
+			oversized = append(oversized, key)
+		}
+	}
+	for _, key := range oversized {
+		text, handle := s.Limit(fields[key].(string))
+		fields[key] = text
+		fields[key+"_truncated"] = true
+		if handle != "" {
+			fields[key+"_handle"] = handle
+		}
+	}
+	return result
+}
+
+// SpillWriter captures a stream of output for a tool result
+type SpillWriter struct {
+	mu     sync.Mutex
+	store  *OutputStore
+	limits OutputLimits
+	buf    []byte   // Whole output while it fits in MaxBytes, then the head
+	tail   []byte   // Last TailBytes once spilled
+	file   *os.File // Spillover file, once the output is too large
+	handle string
+	total  int64
+	lines  int64
+	last   byte // Last byte written, to count an unterminated last line
+	failed bool // No file could be created; only head and tail are kept
+}
+
+// SpillOutput describes captured output
+type SpillOutput struct {
+	Text      string // Whole output, or head and tail with a notice
+	Handle    string // Spillover handle, empty unless spilled to a file
+	Truncated bool   // Text is not the whole output
+	Bytes     int64  // Size of the whole output
+	Lines     int64  // Lines in the whole output
+}
+
+func (w *SpillWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if len(p) == 0 {
+		return 0, nil
+	}
+	w.total += int64(len(p))
+	w.lines += int64(bytes.Count(p, []byte{'\n'}))
+	w.last = p[len(p)-1]
+
+	if w.file == nil && !w.failed {
+		if len(w.buf)+len(p) <= w.limits.MaxBytes {
+			w.buf = append(w.buf, p...)
+			return len(p), nil
+		}
+		w.spill()
+	}
+
+	if w.file != nil && w.total <= maxSpillBytes {
+		w.file.Write(p)
+	}
+	if len(w.buf) < w.limits.HeadBytes {
+		n := w.limits.HeadBytes - len(w.buf)
+		if n > len(p) {
+			n = len(p)
+		}
+		w.buf = append(w.buf, p[:n]...)
+	}
+	w.tail = append(w.tail, p...)
+	if over := len(w.tail) - w.limits.TailBytes; over > 0 {
+		w.tail = append(w.tail[:0], w.tail[over:]...)
+	}
+	return len(p), nil
+}
+
+// spill moves the buffered output to a spillover file, keeping only the head
+// and tail in memory
+func (w *SpillWriter) spill() {
+	if w.store != nil {
+		if file, handle, err := w.store.create(); err == nil {
+			file.Write(w.buf)
+			w.file, w.handle = file, handle
+		}
+	}
+	if w.file == nil {
+		w.failed = true
+	}
+
+	w.tail = append(w.tail, w.buf...)
+	if over := len(w.tail) - w.limits.TailBytes; over > 0 {
+		w.tail = append(w.tail[:0], w.tail[over:]...)
+	}
+	if len(w.buf) > w.limits.HeadBytes {
+		w.buf = w.buf[:w.limits.HeadBytes]
+	}
+}
+
+// Finish closes the spillover file and returns the captured output
+func (w *SpillWriter) Finish() SpillOutput {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	lines := w.lines
+	if w.total > 0 && w.last != '\n' {
+		lines++ // Unterminated last line
+	}
+	out := SpillOutput{Bytes: w.total, Lines: lines}
+
+	if w.file == nil && !w.failed {
+		out.Text = string(w.buf)
+		return out
+	}
+	if w.file != nil {
+		w.file.Close()
+	}
+
+	// Cut head and tail at line boundaries when that loses little
+	head := string(w.buf)
+	if i := strings.LastIndexByte(head, '\n'); i >= len(head)/2 {
+		head = head[:i+1]
+	}
+	tail := string(w.tail)
+	if i := strings.IndexByte(tail, '\n'); i >= 0 && i < len(tail)/2 {
+		tail = tail[i+1:]
+	}
+	omitted := w.total - int64(len(head)) - int64(len(tail))
+
+	var notice string
+	switch {
+	case w.handle != "" && w.total > maxSpillBytes:
+		notice = fmt.Sprintf("... [%d bytes omitted; the first %d bytes of the output are saved as %s, use read_output to page through them] ...", omitted, maxSpillBytes, w.handle)
+	case w.handle != "":
+		notice = fmt.Sprintf("... [%d bytes omitted; full output (%d bytes, %d lines) saved as %s, use read_output to page through it] ...", omitted, w.total, lines, w.handle)
+	default:
+		notice = fmt.Sprintf("... [%d bytes omitted] ...", omitted)
+	}
+
+	out.Text = head + "\n" + notice + "\n\n" + tail
+	out.Handle = w.handle
+	out.Truncated = true
+	return out
+}
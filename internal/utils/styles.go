@@ -48,6 +48,13 @@ func ToolResultStyle() lipgloss.Style {
 		MarginLeft(2)
 }
 
+func LiveOutputStyle() lipgloss.Style {
+	return lipgloss.NewStyle().
+		Foreground(lipgloss.Color("244")).
+		Faint(true).
+		MarginLeft(4)
+}
+
 func DangerStyle() lipgloss.Style {
 	return lipgloss.NewStyle().
 		Foreground(lipgloss.Color("196")).  // Bright red
@@ -0,0 +1,125 @@
+package webpage
+
+import (
+	"bytes"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+
+	md "github.com/JohannesKaufmann/html-to-markdown"
+	"github.com/JohannesKaufmann/html-to-markdown/plugin"
+	"github.com/PuerkitoBio/goquery"
+)
+
+// Page is the readable content of an HTML document
+type Page struct {
+	Title    string
+	Markdown string
+}
+
+// noiseSelector matches elements that never carry page content
+const noiseSelector = "script, style, noscript, template, svg, canvas, iframe, object, embed, button, select, input, textarea, dialog, " +
+	"nav, aside, [role=navigation], [role=search], [role=complementary], [aria-hidden=true], [hidden]"
+
+// chromeSelector matches site headers, footers and banners; they are only
+// removed when no main content element was found
+const chromeSelector = "header, footer, [role=banner], [role=contentinfo]"
+
+// blankLinesRe collapses runs of blank lines left by removed elements
+var blankLinesRe = regexp.MustCompile(`\n{3,}`)
+
+// Extract converts an HTML document to Markdown, keeping the main content:
+// scripts, styles, navigation and other page furniture are dropped, and
+// links and images are made absolute against pageURL.
+func Extract(html []byte, pageURL string) (*Page, error) {
+	doc, err := goquery.NewDocumentFromReader(bytes.NewReader(html))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse HTML: %v", err)
+	}
+
+	page := &Page{Title: strings.Join(strings.Fields(doc.Find("title").First().Text()), " ")}
+
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid page URL: %v", err)
+	}
+	if href, ok := doc.Find("base[href]").First().Attr("href"); ok {
+		if ref, err := url.Parse(href); err == nil {
+			base = base.ResolveReference(ref)
+		}
+	}
+
+	content := mainContent(doc)
+	content.Find(noiseSelector).Remove()
+	unwrapForms(content)
+	if content.Is("body") {
+		content.Find(chromeSelector).Remove()
+	}
+	absolutize(content, base)
+
+	converter := md.NewConverter("", true, &md.Options{CodeBlockStyle: "fenced"})
+	converter.Use(plugin.GitHubFlavored())
+	markdown := converter.Convert(content)
+
+	markdown = blankLinesRe.ReplaceAllString(strings.TrimSpace(markdown), "\n\n")
+	page.Markdown = markdown
+	return page, nil
+}
+
+// mainContent picks the element holding the page's main content: an explicit
+// main element, the longest article, or the whole body
+func mainContent(doc *goquery.Document) *goquery.Selection {
+	if main := doc.Find("main, [role=main]").First(); main.Length() > 0 {
+		return main
+	}
+
+	var best *goquery.Selection
+	bestLen := 0
+	doc.Find("article").Each(func(_ int, article *goquery.Selection) {
+		if n := len(strings.TrimSpace(article.Text())); n > bestLen {
+			best, bestLen = article, n
+		}
+	})
+	if best != nil {
+		return best
+	}
+
+	if body := doc.Find("body").First(); body.Length() > 0 {
+		return body
+	}
+	return doc.Selection
+}
+
+// unwrapForms replaces forms by their contents once the controls are gone.
+// Some sites (ASP.NET WebForms, many CMS themes) wrap the whole page in a
+// form, so forms cannot simply be dropped; those left empty are removed.
+func unwrapForms(content *goquery.Selection) {
+	content.Find("form").Each(func(_ int, form *goquery.Selection) {
+		if strings.TrimSpace(form.Text()) == "" && form.Find("img").Length() == 0 {
+			form.Remove()
+			return
+		}
+		form.ReplaceWithSelection(form.Contents())
+	})
+}
+
+// absolutize resolves link and image URLs against base and unwraps links
+// that cannot be followed
+func absolutize(content *goquery.Selection, base *url.URL) {
+	content.Find("a[href]").Each(func(_ int, link *goquery.Selection) {
+		href, _ := link.Attr("href")
+		ref, err := url.Parse(strings.TrimSpace(href))
+		if err != nil || ref.Scheme == "javascript" {
+			link.RemoveAttr("href")
+			return
+		}
+		link.SetAttr("href", base.ResolveReference(ref).String())
+	})
+	content.Find("img[src]").Each(func(_ int, img *goquery.Selection) {
+		src, _ := img.Attr("src")
+		if ref, err := url.Parse(strings.TrimSpace(src)); err == nil {
+			img.SetAttr("src", base.ResolveReference(ref).String())
+		}
+	})
+}